@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
+
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/blockchain"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/config"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/external"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/realtime"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/service"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
@@ -43,7 +46,7 @@ func main() {
 	logger.Info().Msg("Cache configuration loaded")
 
 	logger.Info().Msg("Running database migrations")
-	db.AutoMigrate(model.Events{}, model.Burns{}, model.Deposit{}, model.Events{}, model.Mints{}, model.Prices{}, model.Redeem{}, model.Liquidations{})
+	db.AutoMigrate(model.Events{}, model.Burns{}, model.Deposit{}, model.Events{}, model.Mints{}, model.Prices{}, model.Redeem{}, model.Liquidations{}, model.AlertSubscription{}, model.AlertDelivery{}, model.DailyProtocolStats{}, model.DailyCollateralStats{})
 	logger.Info().Msg("Database migrations completed successfully")
 
 	logger.Info().Msg("Initializing blockchain client")
@@ -66,22 +69,57 @@ func main() {
 	healthFactorCalcService := service.NewHealthFactorCalculationService(cacheStore, priceFeed)
 	logger.Info().Msg("Health factor calculation service ready")
 
+	logger.Info().Msg("Initializing health factor history service")
+	healthFactorHistoryService := service.NewHealthFactorHistoryService(cacheStore)
+	logger.Info().Msg("Health factor history service ready")
+
+	logger.Info().Msg("Initializing liquidation transitions service")
+	liquidationTransitionsService := service.NewLiquidationTransitionsService(cacheStore)
+	logger.Info().Msg("Liquidation transitions service ready")
+
+	logger.Info().Msg("Initializing liquidation opportunities service")
+	liquidationOpportunitiesService := service.NewLiquidationOpportunitiesService(cacheStore, priceFeed)
+	logger.Info().Msg("Liquidation opportunities service ready")
+
+	logger.Info().Msg("Initializing risk service")
+	riskService := service.NewRiskService(cacheStore, priceFeed)
+	logger.Info().Msg("Risk service ready")
+
 	logger.Info().Msg("Initializing dashboard metrics service")
 	dashboardMetricsService := service.NewDashboardMetricsService(cacheStore, priceFeed)
 	logger.Info().Msg("Dashboard metrics service ready")
 
 	logger.Info().Msg("Initializing storage layers")
 	eventStore := storage.NewEventsStore(db)
-	coinStore := storage.NewCoinStore(db)
-	collateralStore := storage.NewCollateralStore(db)
+	// The event processors and metrics updater read these through storage.GetCoinStore and
+	// storage.GetCollateralStore, so they are only registered here.
+	storage.NewCoinStore(db)
+	storage.NewCollateralStore(db)
 	liquidationStore := storage.NewLiquidationStore(db)
 	priceStore := storage.NewPriceStore(db)
+	alertStore := storage.NewAlertStore(db)
+	historyStore := storage.NewHistoryStore(db)
+	dailyStatsStore := storage.NewDailyStatsStore(db)
 	logger.Info().Msg("All storage layers initialized")
 
+	logger.Info().Msg("Initializing alerts service")
+	alertsService := service.NewAlertsService(alertStore)
+	logger.Info().Msg("Alerts service ready")
+
 	logger.Info().Msg("Initializing history service")
-	historyService := service.NewHistoryService(collateralStore, coinStore, liquidationStore, eventStore)
+	historyService := service.NewHistoryService(historyStore, priceStore)
 	logger.Info().Msg("History service ready")
 
+	logger.Info().Msg("Initializing liquidators service")
+	liquidatorsService := service.NewLiquidatorsService(liquidationStore)
+	logger.Info().Msg("Liquidators service ready")
+
+	logger.Info().Msg("Initializing daily stats service")
+	dailyStatsService := service.NewDailyStatsService(dailyStatsStore, priceStore)
+	logger.Info().Msg("Daily stats service ready")
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+
 	logger.Info().Msg("Starting log worker for blockchain events")
 	worker.RunLogWorker(bChainClient, bChainConfig, eventStore)
 	logger.Info().Msg("Log worker started")
@@ -91,11 +129,26 @@ func main() {
 	logger.Info().Msg("Metrics worker started")
 
 	logger.Info().Msg("Starting liquidations worker")
-	worker.RunLiquidationsWorker(cacheStore, priceFeed)
+	worker.RunLiquidationsWorker(workersCtx, cacheStore, priceFeed)
 	logger.Info().Msg("Liquidations worker started")
 
+	logger.Info().Msg("Starting alerts worker")
+	worker.RunAlertsWorker(workersCtx, cacheStore, alertsService)
+	logger.Info().Msg("Alerts worker started")
+
+	logger.Info().Msg("Starting daily stats worker")
+	worker.RunDailyStatsWorker(cacheStore, dailyStatsService)
+	logger.Info().Msg("Daily stats worker started")
+
+	logger.Info().Msg("Starting realtime hub")
+	realtimeHub := realtime.NewHub(cacheStore, dashboardMetricsService)
+	realtimeHub.Run()
+	logger.Info().Msg("Realtime hub started")
+
 	logger.Info().Msg("Flushing cache store")
-	cacheStore.FlushAll()
+	if err := cacheStore.FlushAllExcept(service.DurableCacheKeys...); err != nil {
+		logger.Fatal().Err(err).Msg("Failed to flush cache store")
+	}
 	logger.Info().Msg("Cache store flushed successfully")
 
 	logger.Info().Msg("Updating initial metrics")
@@ -103,9 +156,9 @@ func main() {
 	logger.Info().Msg("Initial metrics updated")
 
 	logger.Info().Msg("Registering HTTP routes")
-	http.RegisterRoutes(userDataService, healthFactorCalcService, dashboardMetricsService, historyService)
+	http.RegisterRoutes(userDataService, healthFactorCalcService, dashboardMetricsService, historyService, healthFactorHistoryService, liquidationTransitionsService, liquidationOpportunitiesService, liquidatorsService, dailyStatsService, riskService, alertsService, realtimeHub, cacheStore)
 	logger.Info().Msg("HTTP routes registered")
 
 	logger.Info().Str("address", ":3000").Msg("Starting HTTP server")
-	http.Run(":3000")
+	http.Run(":3000", realtimeHub.Close, stopWorkers)
 }
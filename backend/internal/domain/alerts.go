@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// nonPublicNetworks are the ranges IsPublicWebhookIP rejects on top of the loopback, private,
+// link-local and multicast ranges net.IP already classifies.
+var nonPublicNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier-grade NAT, also used by some cloud metadata services
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"240.0.0.0/4",   // reserved, including the broadcast address
+	"64:ff9b::/96",  // NAT64, which can map to any IPv4 address
+	"2001:db8::/32", // documentation
+)
+
+var alertThresholdPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]{1,18})?$`)
+
+// ParseAlertThreshold parses a decimal health factor such as "1.3" into its 18 decimal value. Signs,
+// exponents, NaN and infinities are rejected along with zero.
+func ParseAlertThreshold(value string) (*big.Int, error) {
+	if !alertThresholdPattern.MatchString(value) {
+		return nil, fmt.Errorf("%w: 'threshold' must be a positive decimal health factor such as 1.3, got %q", model.ErrInvalidAmount, value)
+	}
+
+	threshold, ok := ParseDecimalToScaledInt(value, constants.PRECISION)
+	if !ok || threshold.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: 'threshold' must be a positive decimal health factor such as 1.3, got %q", model.ErrInvalidAmount, value)
+	}
+	return threshold, nil
+}
+
+// AlertState is the part of a subscription that decides whether a new health factor fires an alert.
+type AlertState struct {
+	Threshold       *big.Int
+	Triggered       bool
+	LastTriggeredAt *time.Time
+}
+
+// ShouldTriggerAlert fires once per crossing below the threshold. A subscription that already fired
+// stays silent until the position recovers, and never fires twice within the cooldown.
+func ShouldTriggerAlert(state AlertState, healthFactor *big.Int, now time.Time, cooldown time.Duration) bool {
+	if state.Threshold == nil || healthFactor == nil || state.Triggered {
+		return false
+	}
+	if healthFactor.Cmp(state.Threshold) >= 0 {
+		return false
+	}
+	if state.LastTriggeredAt != nil && now.Sub(*state.LastTriggeredAt) < cooldown {
+		return false
+	}
+	return true
+}
+
+// ShouldRearmAlert reports whether a fired subscription can fire again because the position recovered.
+func ShouldRearmAlert(state AlertState, healthFactor *big.Int) bool {
+	if state.Threshold == nil || healthFactor == nil || !state.Triggered {
+		return false
+	}
+	return healthFactor.Cmp(state.Threshold) >= 0
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>" keyed by the subscription secret.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AlertOwnerMessage is the message an address signs with personal_sign to prove it owns its alert
+// subscriptions. The timestamp, in unix seconds, bounds how long the signature can be replayed.
+func AlertOwnerMessage(address string, timestamp int64) string {
+	return fmt.Sprintf("AnchorUSD alerts\nAddress: %s\nTimestamp: %d", address, timestamp)
+}
+
+// RecoverMessageSigner returns the checksummed address that signed message with personal_sign
+// (EIP-191). Both the 27/28 and 0/1 recovery ids wallets produce are accepted.
+func RecoverMessageSigner(message string, signature []byte) (string, error) {
+	if len(signature) != crypto.SignatureLength {
+		return "", errors.New("signature must be 65 bytes")
+	}
+
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(accounts.TextHash([]byte(message)), sig)
+	if err != nil {
+		return "", err
+	}
+	return crypto.PubkeyToAddress(*publicKey).Hex(), nil
+}
+
+// RetryBackoff doubles the base delay for every failed attempt, capped at max.
+func RetryBackoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// IsPublicWebhookIP reports whether a webhook may be delivered to ip. Loopback, private, link-local
+// (including the 169.254.169.254 cloud metadata endpoint), multicast, unspecified and reserved
+// addresses are rejected so subscribers cannot make the backend call into its own network.
+func IsPublicWebhookIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestShouldTriggerAlert(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	threshold := big.NewInt(1300000000000000000)
+	recently := now.Add(-10 * time.Minute)
+	longAgo := now.Add(-2 * time.Hour)
+
+	tests := []struct {
+		name         string
+		state        AlertState
+		healthFactor *big.Int
+		expected     bool
+	}{
+		{name: "above threshold", state: AlertState{Threshold: threshold}, healthFactor: big.NewInt(1500000000000000000), expected: false},
+		{name: "at threshold", state: AlertState{Threshold: threshold}, healthFactor: threshold, expected: false},
+		{name: "below threshold", state: AlertState{Threshold: threshold}, healthFactor: big.NewInt(1200000000000000000), expected: true},
+		{name: "already triggered", state: AlertState{Threshold: threshold, Triggered: true}, healthFactor: big.NewInt(1100000000000000000), expected: false},
+		{name: "within cooldown", state: AlertState{Threshold: threshold, LastTriggeredAt: &recently}, healthFactor: big.NewInt(1200000000000000000), expected: false},
+		{name: "after cooldown", state: AlertState{Threshold: threshold, LastTriggeredAt: &longAgo}, healthFactor: big.NewInt(1200000000000000000), expected: true},
+		{name: "nil health factor", state: AlertState{Threshold: threshold}, healthFactor: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ShouldTriggerAlert(tt.state, tt.healthFactor, now, time.Hour)
+			if result != tt.expected {
+				t.Errorf("ShouldTriggerAlert() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseAlertThreshold(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "1.3", expected: "1300000000000000000"},
+		{value: "2", expected: "2000000000000000000"},
+		{value: "0.000000000000000001", expected: "1"},
+		{value: "0"},
+		{value: "0.0"},
+		{value: "-1.3"},
+		{value: "+1.3"},
+		{value: "NaN"},
+		{value: "Inf"},
+		{value: "1e3"},
+		{value: "1.2.3"},
+		{value: ".5"},
+		{value: "1.0000000000000000001"},
+		{value: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			threshold, err := ParseAlertThreshold(tt.value)
+			if tt.expected == "" {
+				if !errors.Is(err, model.ErrInvalidAmount) {
+					t.Errorf("ParseAlertThreshold(%q) error = %v, want ErrInvalidAmount", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAlertThreshold(%q) unexpected error: %v", tt.value, err)
+			}
+			if threshold.String() != tt.expected {
+				t.Errorf("ParseAlertThreshold(%q) = %s, want %s", tt.value, threshold, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShouldRearmAlert(t *testing.T) {
+	threshold := big.NewInt(1300000000000000000)
+
+	tests := []struct {
+		name         string
+		state        AlertState
+		healthFactor *big.Int
+		expected     bool
+	}{
+		{name: "not triggered", state: AlertState{Threshold: threshold}, healthFactor: big.NewInt(1500000000000000000), expected: false},
+		{name: "still below threshold", state: AlertState{Threshold: threshold, Triggered: true}, healthFactor: big.NewInt(1200000000000000000), expected: false},
+		{name: "recovered", state: AlertState{Threshold: threshold, Triggered: true}, healthFactor: big.NewInt(1300000000000000000), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ShouldRearmAlert(tt.state, tt.healthFactor)
+			if result != tt.expected {
+				t.Errorf("ShouldRearmAlert() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	signature := SignWebhookPayload("secret", 1700000000, []byte(`{"healthFactor":"1"}`))
+
+	if len(signature) != 64 {
+		t.Fatalf("SignWebhookPayload() length = %d, want 64", len(signature))
+	}
+	if signature != SignWebhookPayload("secret", 1700000000, []byte(`{"healthFactor":"1"}`)) {
+		t.Errorf("SignWebhookPayload() is not deterministic")
+	}
+	if signature == SignWebhookPayload("other", 1700000000, []byte(`{"healthFactor":"1"}`)) {
+		t.Errorf("SignWebhookPayload() ignores the secret")
+	}
+	if signature == SignWebhookPayload("secret", 1700000001, []byte(`{"healthFactor":"1"}`)) {
+		t.Errorf("SignWebhookPayload() ignores the timestamp")
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 0, expected: time.Second},
+		{attempt: 1, expected: time.Second},
+		{attempt: 2, expected: 2 * time.Second},
+		{attempt: 4, expected: 8 * time.Second},
+		{attempt: 10, expected: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		result := RetryBackoff(tt.attempt, time.Second, 30*time.Second)
+		if result != tt.expected {
+			t.Errorf("RetryBackoff(%d) = %s, want %s", tt.attempt, result, tt.expected)
+		}
+	}
+}
+
+func TestIsPublicWebhookIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected bool
+	}{
+		{ip: "93.184.215.14", expected: true},
+		{ip: "2606:2800:21f:cb07:6820:80da:af6b:8b2c", expected: true},
+		{ip: "127.0.0.1", expected: false},
+		{ip: "::1", expected: false},
+		{ip: "10.1.2.3", expected: false},
+		{ip: "172.16.0.1", expected: false},
+		{ip: "192.168.1.1", expected: false},
+		{ip: "169.254.169.254", expected: false},
+		{ip: "fe80::1", expected: false},
+		{ip: "fd00:ec2::254", expected: false},
+		{ip: "100.100.100.200", expected: false},
+		{ip: "0.0.0.0", expected: false},
+		{ip: "::", expected: false},
+		{ip: "::ffff:127.0.0.1", expected: false},
+		{ip: "255.255.255.255", expected: false},
+		{ip: "224.0.0.1", expected: false},
+	}
+
+	for _, tt := range tests {
+		if result := IsPublicWebhookIP(net.ParseIP(tt.ip)); result != tt.expected {
+			t.Errorf("IsPublicWebhookIP(%s) = %v, want %v", tt.ip, result, tt.expected)
+		}
+	}
+}
+
+func TestRecoverMessageSigner(t *testing.T) {
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	message := AlertOwnerMessage(address, 1700000000)
+
+	signature, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := RecoverMessageSigner(message, signature)
+	if err != nil || signer != address {
+		t.Errorf("RecoverMessageSigner() = %s, %v, want %s", signer, err, address)
+	}
+
+	walletSignature := append([]byte{}, signature...)
+	walletSignature[crypto.RecoveryIDOffset] += 27
+	signer, err = RecoverMessageSigner(message, walletSignature)
+	if err != nil || signer != address {
+		t.Errorf("RecoverMessageSigner() with v=27/28 = %s, %v, want %s", signer, err, address)
+	}
+
+	signer, err = RecoverMessageSigner(AlertOwnerMessage(address, 1700000001), signature)
+	if err == nil && signer == address {
+		t.Errorf("RecoverMessageSigner() recovered the owner from a different message")
+	}
+
+	if _, err := RecoverMessageSigner(message, signature[:64]); err == nil {
+		t.Errorf("RecoverMessageSigner() accepted a short signature")
+	}
+}
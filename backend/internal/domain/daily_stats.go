@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+)
+
+// StartOfDay truncates t to midnight UTC, the boundary daily stats are rolled up on.
+func StartOfDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// RollDailyCollateral carries the previous day's collateral balances through one day of flows.
+// Collateral with neither a balance nor activity is left out. TVL is not set.
+func RollDailyCollateral(day time.Time, previous []model.DailyCollateralStats, flows []model.DailyCollateralFlow) []model.DailyCollateralStats {
+	byAsset := make(map[string]*model.DailyCollateralStats, len(previous)+len(flows))
+	get := func(asset string) *model.DailyCollateralStats {
+		stats, ok := byAsset[asset]
+		if !ok {
+			stats = &model.DailyCollateralStats{
+				Day:               day,
+				CollateralAddress: asset,
+				DepositVolume:     model.NewBigInt(nil),
+				RedeemVolume:      model.NewBigInt(nil),
+				LiquidatedAmount:  model.NewBigInt(nil),
+				Balance:           model.NewBigInt(nil),
+				TVLUSD:            model.NewBigInt(nil),
+			}
+			byAsset[asset] = stats
+		}
+		return stats
+	}
+
+	for _, stats := range previous {
+		if stats.Balance.Int.Sign() != 0 {
+			get(stats.CollateralAddress).Balance = model.NewBigInt(stats.Balance.Int)
+		}
+	}
+
+	for _, flow := range flows {
+		stats := get(flow.CollateralAddress)
+		stats.DepositVolume = model.NewBigInt(flow.DepositVolume.Int)
+		stats.RedeemVolume = model.NewBigInt(flow.RedeemVolume.Int)
+		stats.LiquidatedAmount = model.NewBigInt(flow.LiquidatedAmount.Int)
+
+		stats.Balance.Int.Add(stats.Balance.Int, flow.DepositVolume.Int)
+		stats.Balance.Int.Sub(stats.Balance.Int, flow.RedeemVolume.Int)
+		stats.Balance.Int.Sub(stats.Balance.Int, flow.LiquidatedAmount.Int)
+	}
+
+	rolled := make([]model.DailyCollateralStats, 0, len(byAsset))
+	for _, stats := range byAsset {
+		rolled = append(rolled, *stats)
+	}
+	slices.SortFunc(rolled, func(a, b model.DailyCollateralStats) int {
+		return strings.Compare(a.CollateralAddress, b.CollateralAddress)
+	})
+	return rolled
+}
+
+// SumTVL adds up the collateral TVLs of a day.
+func SumTVL(collateral []model.DailyCollateralStats) *big.Int {
+	total := big.NewInt(0)
+	for _, stats := range collateral {
+		total.Add(total, stats.TVLUSD.Int)
+	}
+	return total
+}
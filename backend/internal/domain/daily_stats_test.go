@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+)
+
+func TestStartOfDay(t *testing.T) {
+	tests := []struct {
+		name string
+		in   time.Time
+		want time.Time
+	}{
+		{"utc afternoon", time.Date(2024, 3, 5, 15, 4, 5, 6, time.UTC), time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"midnight", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"other zone crosses day", time.Date(2024, 3, 5, 22, 0, 0, 0, time.FixedZone("UTC-3", -3*60*60)), time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StartOfDay(tt.in); !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("StartOfDay(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func dailyBigInt(value int64) model.BigInt {
+	return model.BigInt{Int: big.NewInt(value)}
+}
+
+func TestRollDailyCollateral(t *testing.T) {
+	day := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	previous := []model.DailyCollateralStats{
+		{CollateralAddress: "0xeth", Balance: dailyBigInt(100)},
+		{CollateralAddress: "0xold", Balance: dailyBigInt(0)},
+		{CollateralAddress: "0xbtc", Balance: dailyBigInt(7)},
+	}
+	flows := []model.DailyCollateralFlow{
+		{CollateralAddress: "0xeth", DepositVolume: dailyBigInt(50), RedeemVolume: dailyBigInt(20), LiquidatedAmount: dailyBigInt(5)},
+		{CollateralAddress: "0xnew", DepositVolume: dailyBigInt(3), RedeemVolume: dailyBigInt(0), LiquidatedAmount: dailyBigInt(0)},
+	}
+
+	rolled := RollDailyCollateral(day, previous, flows)
+
+	want := []struct {
+		asset     string
+		deposited int64
+		balance   int64
+	}{
+		{"0xbtc", 0, 7},
+		{"0xeth", 50, 125},
+		{"0xnew", 3, 3},
+	}
+	if len(rolled) != len(want) {
+		t.Fatalf("RollDailyCollateral() returned %d rows, want %d: %+v", len(rolled), len(want), rolled)
+	}
+	for i, w := range want {
+		got := rolled[i]
+		if got.CollateralAddress != w.asset || got.DepositVolume.Int.Int64() != w.deposited || got.Balance.Int.Int64() != w.balance {
+			t.Errorf("row %d = {%s deposited %s balance %s}, want {%s deposited %d balance %d}",
+				i, got.CollateralAddress, got.DepositVolume.Int, got.Balance.Int, w.asset, w.deposited, w.balance)
+		}
+		if !got.Day.Equal(day) || got.TVLUSD.Int.Sign() != 0 {
+			t.Errorf("row %d has day %v and TVL %s", i, got.Day, got.TVLUSD.Int)
+		}
+	}
+
+	if previous[0].Balance.Int.Int64() != 100 {
+		t.Errorf("previous balance was modified to %s", previous[0].Balance.Int)
+	}
+}
+
+func TestSumTVL(t *testing.T) {
+	collateral := []model.DailyCollateralStats{{TVLUSD: dailyBigInt(150)}, {TVLUSD: dailyBigInt(50)}}
+
+	if got := SumTVL(collateral); got.Int64() != 200 {
+		t.Errorf("SumTVL() = %s, want 200", got)
+	}
+	if got := SumTVL(nil); got.Sign() != 0 {
+		t.Errorf("SumTVL(nil) = %s, want 0", got)
+	}
+}
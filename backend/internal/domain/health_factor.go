@@ -6,17 +6,15 @@ import (
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
 )
 
+// CalculateHealthFactor returns the 18 decimal health factor of an 8 decimal collateral USD value and
+// 18 decimal debt, scaling before dividing as AUSDEngine does so fractional health factors are kept.
 func CalculateHealthFactor(collateralValueUSD, debtValueUSD *big.Int) *big.Int {
 	if debtValueUSD.Sign() == 0 {
 		return big.NewInt(0).Mul(collateralValueUSD, constants.ADDITIONAL_PRICE_PRECISION)
 	}
 
-	collateralAdjusted := big.NewInt(0).Mul(collateralValueUSD, constants.LIQUIDATION_THRESHOLD)
-	collateralAdjusted.Div(collateralAdjusted, constants.LIQUIDATION_PRECISION)
-	healthFactor := big.NewInt(0).Mul(collateralAdjusted, constants.ADDITIONAL_PRICE_PRECISION)
-	healthFactor.Div(healthFactor, debtValueUSD)
-
-	return healthFactor.Mul(healthFactor, constants.PRECISION)
+	collateralValue := new(big.Int).Mul(collateralValueUSD, constants.ADDITIONAL_PRICE_PRECISION)
+	return ContractHealthFactor(collateralValue, debtValueUSD)
 }
 
 func CalculateHealthFactorAfterMint(currentCollateralUSD, currentDebt, mintAmount *big.Int) *big.Int {
@@ -55,4 +53,23 @@ func IsAtRisk(hf *big.Int) bool {
 		return false
 	}
 	return hf.Cmp(constants.RISK_THRESHOLD) < 0
-}
\ No newline at end of file
+}
+
+// IsLiquidatable reports whether a health factor is below MIN_HEALTH_FACTOR, the point at which
+// AUSDEngine.liquidate accepts the position. A missing health factor is not liquidatable.
+func IsLiquidatable(hf *big.Int) bool {
+	if hf == nil {
+		return false
+	}
+	return hf.Cmp(constants.MIN_HEALTH_FACTOR) < 0
+}
+
+// IsPositionLiquidatable reports whether a position with the given health factor and 18 decimal debt
+// can be liquidated. A position without debt is never liquidatable, even though a closed position's
+// health factor is zero.
+func IsPositionLiquidatable(hf, debt *big.Int) bool {
+	if debt == nil || debt.Sign() <= 0 {
+		return false
+	}
+	return IsLiquidatable(hf)
+}
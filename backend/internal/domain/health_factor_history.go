@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+)
+
+func DownsampleHealthFactors(snapshots []model.HealthFactorSnapshot, resolution time.Duration) []model.HealthFactorPoint {
+	points := []model.HealthFactorPoint{}
+
+	var bucketStart time.Time
+	var minHF, maxHF *big.Int
+
+	for _, snapshot := range snapshots {
+		hf, ok := new(big.Int).SetString(snapshot.HealthFactor, 10)
+		if !ok {
+			continue
+		}
+
+		start := snapshot.Timestamp.UTC()
+		if resolution > 0 {
+			start = start.Truncate(resolution)
+		}
+
+		if len(points) == 0 || resolution <= 0 || !start.Equal(bucketStart) {
+			bucketStart = start
+			minHF = new(big.Int).Set(hf)
+			maxHF = new(big.Int).Set(hf)
+			points = append(points, model.HealthFactorPoint{Timestamp: start.Format(time.RFC3339)})
+		}
+
+		if hf.Cmp(minHF) < 0 {
+			minHF.Set(hf)
+		}
+		if hf.Cmp(maxHF) > 0 {
+			maxHF.Set(hf)
+		}
+
+		point := &points[len(points)-1]
+		point.HealthFactor = hf.String()
+		point.MinHealthFactor = minHF.String()
+		point.MaxHealthFactor = maxHF.String()
+		point.CollateralUsd = snapshot.CollateralUSD
+		point.Debt = snapshot.Debt
+		point.Samples++
+	}
+
+	return points
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+)
+
+func TestDownsampleHealthFactors(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	snapshots := []model.HealthFactorSnapshot{
+		{HealthFactor: "2000000000000000000", CollateralUSD: "400", Debt: "100", Timestamp: base},
+		{HealthFactor: "1500000000000000000", CollateralUSD: "300", Debt: "100", Timestamp: base.Add(10 * time.Minute)},
+		{HealthFactor: "1800000000000000000", CollateralUSD: "360", Debt: "100", Timestamp: base.Add(20 * time.Minute)},
+		{HealthFactor: "900000000000000000", CollateralUSD: "180", Debt: "100", Timestamp: base.Add(70 * time.Minute)},
+	}
+
+	tests := []struct {
+		name       string
+		resolution time.Duration
+		expected   []model.HealthFactorPoint
+	}{
+		{
+			name:       "raw resolution keeps every snapshot",
+			resolution: 0,
+			expected: []model.HealthFactorPoint{
+				{Timestamp: "2024-01-01T10:00:00Z", HealthFactor: "2000000000000000000", MinHealthFactor: "2000000000000000000", MaxHealthFactor: "2000000000000000000", CollateralUsd: "400", Debt: "100", Samples: 1},
+				{Timestamp: "2024-01-01T10:10:00Z", HealthFactor: "1500000000000000000", MinHealthFactor: "1500000000000000000", MaxHealthFactor: "1500000000000000000", CollateralUsd: "300", Debt: "100", Samples: 1},
+				{Timestamp: "2024-01-01T10:20:00Z", HealthFactor: "1800000000000000000", MinHealthFactor: "1800000000000000000", MaxHealthFactor: "1800000000000000000", CollateralUsd: "360", Debt: "100", Samples: 1},
+				{Timestamp: "2024-01-01T11:10:00Z", HealthFactor: "900000000000000000", MinHealthFactor: "900000000000000000", MaxHealthFactor: "900000000000000000", CollateralUsd: "180", Debt: "100", Samples: 1},
+			},
+		},
+		{
+			name:       "hourly resolution keeps last value and range per bucket",
+			resolution: time.Hour,
+			expected: []model.HealthFactorPoint{
+				{Timestamp: "2024-01-01T10:00:00Z", HealthFactor: "1800000000000000000", MinHealthFactor: "1500000000000000000", MaxHealthFactor: "2000000000000000000", CollateralUsd: "360", Debt: "100", Samples: 3},
+				{Timestamp: "2024-01-01T11:00:00Z", HealthFactor: "900000000000000000", MinHealthFactor: "900000000000000000", MaxHealthFactor: "900000000000000000", CollateralUsd: "180", Debt: "100", Samples: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DownsampleHealthFactors(snapshots, tt.resolution)
+
+			if len(result) != len(tt.expected) {
+				t.Fatalf("DownsampleHealthFactors() returned %d points, want %d", len(result), len(tt.expected))
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("DownsampleHealthFactors()[%d] = %+v, want %+v", i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDownsampleHealthFactors_SkipsInvalidValues(t *testing.T) {
+	snapshots := []model.HealthFactorSnapshot{
+		{HealthFactor: "invalid", Timestamp: time.Unix(0, 0)},
+	}
+
+	result := DownsampleHealthFactors(snapshots, time.Minute)
+
+	if len(result) != 0 {
+		t.Errorf("DownsampleHealthFactors() returned %d points, want 0", len(result))
+	}
+}
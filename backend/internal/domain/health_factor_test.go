@@ -71,6 +71,29 @@ func TestCalculateHealthFactor(t *testing.T) {
 	}
 }
 
+func TestCalculateHealthFactor_Fractional(t *testing.T) {
+	tests := []struct {
+		name          string
+		collateralUSD *big.Int
+		debt          *big.Int
+		expected      string
+	}{
+		{name: "1.9", collateralUSD: usd8(3800), debt: usd18(1000), expected: "1900000000000000000"},
+		{name: "1.3", collateralUSD: usd8(2600), debt: usd18(1000), expected: "1300000000000000000"},
+		{name: "just below 1", collateralUSD: usd8(1999), debt: usd18(1000), expected: "999500000000000000"},
+		{name: "repeating fraction", collateralUSD: usd8(1000), debt: usd18(3000), expected: "166666666666666666"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CalculateHealthFactor(tt.collateralUSD, tt.debt)
+			if result.String() != tt.expected {
+				t.Errorf("CalculateHealthFactor() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestCalculateHealthFactorAfterMint(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -316,3 +339,50 @@ func TestIsAtRisk(t *testing.T) {
 		})
 	}
 }
+
+func TestIsLiquidatable(t *testing.T) {
+	tests := []struct {
+		name     string
+		hf       *big.Int
+		expected bool
+	}{
+		{name: "nil health factor", hf: nil, expected: false},
+		{name: "below minimum", hf: new(big.Int).Sub(constants.MIN_HEALTH_FACTOR, big.NewInt(1)), expected: true},
+		{name: "at minimum", hf: constants.MIN_HEALTH_FACTOR, expected: false},
+		{name: "zero", hf: big.NewInt(0), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsLiquidatable(tt.hf); result != tt.expected {
+				t.Errorf("IsLiquidatable() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsPositionLiquidatable(t *testing.T) {
+	healthy := new(big.Int).Mul(big.NewInt(2), constants.PRECISION)
+	unhealthy := new(big.Int).Div(constants.PRECISION, big.NewInt(2))
+	debt := new(big.Int).Mul(big.NewInt(100), constants.PRECISION)
+
+	tests := []struct {
+		name     string
+		hf       *big.Int
+		debt     *big.Int
+		expected bool
+	}{
+		{name: "unhealthy with debt", hf: unhealthy, debt: debt, expected: true},
+		{name: "healthy with debt", hf: healthy, debt: debt, expected: false},
+		{name: "closed position", hf: big.NewInt(0), debt: big.NewInt(0), expected: false},
+		{name: "unknown debt", hf: unhealthy, debt: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsPositionLiquidatable(tt.hf, tt.debt); result != tt.expected {
+				t.Errorf("IsPositionLiquidatable() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
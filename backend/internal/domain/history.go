@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+)
+
+// EncodeHistoryCursor returns an opaque cursor pointing at the given transaction.
+func EncodeHistoryCursor(cursor model.HistoryCursor) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d:%d:%s", cursor.BlockNumber, cursor.LogIndex, cursor.Key))
+}
+
+// DecodeHistoryCursor parses a cursor produced by EncodeHistoryCursor.
+func DecodeHistoryCursor(value string) (model.HistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return model.HistoryCursor{}, model.ErrInvalidHistoryCursor
+	}
+
+	// Cursors issued before the key was added have two parts and resume after every row at their position.
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) < 2 {
+		return model.HistoryCursor{}, model.ErrInvalidHistoryCursor
+	}
+	blockNumber, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return model.HistoryCursor{}, model.ErrInvalidHistoryCursor
+	}
+	logIndex, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return model.HistoryCursor{}, model.ErrInvalidHistoryCursor
+	}
+
+	cursor := model.HistoryCursor{BlockNumber: blockNumber, LogIndex: uint(logIndex)}
+	if len(parts) == 3 {
+		cursor.Key = parts[2]
+	}
+	return cursor, nil
+}
+
+// HistoryBalances is a user's collateral per asset and debt, rebuilt by replaying history rows.
+type HistoryBalances struct {
+	Collateral map[string]*big.Int
+	Debt       *big.Int
+}
+
+func NewHistoryBalances() HistoryBalances {
+	return HistoryBalances{
+		Collateral: map[string]*big.Int{},
+		Debt:       big.NewInt(0),
+	}
+}
+
+// Apply updates the balances with one history row. Liquidations remove the seized collateral,
+// bonus included, and the debt covered.
+func (b HistoryBalances) Apply(entry model.HistoryEntry) {
+	switch entry.Type {
+	case model.TransactionTypeDeposit:
+		b.collateral(entry.Asset).Add(b.collateral(entry.Asset), entry.Amount.Int)
+	case model.TransactionTypeRedeem:
+		b.collateral(entry.Asset).Sub(b.collateral(entry.Asset), entry.Amount.Int)
+	case model.TransactionTypeMint:
+		b.Debt.Add(b.Debt, entry.Amount.Int)
+	case model.TransactionTypeBurn:
+		b.Debt.Sub(b.Debt, entry.Amount.Int)
+	case model.TransactionTypeLiquidation:
+		b.collateral(entry.Asset).Sub(b.collateral(entry.Asset), entry.CollateralAmount.Int)
+		b.Debt.Sub(b.Debt, entry.Amount.Int)
+	}
+}
+
+func (b HistoryBalances) collateral(asset string) *big.Int {
+	amount, ok := b.Collateral[asset]
+	if !ok {
+		amount = big.NewInt(0)
+		b.Collateral[asset] = amount
+	}
+	return amount
+}
+
+// CollateralChanges lists the non-zero collateral balances, sorted by asset.
+func (b HistoryBalances) CollateralChanges() []model.CollateralChange {
+	changes := make([]model.CollateralChange, 0, len(b.Collateral))
+	for asset, amount := range b.Collateral {
+		if amount.Sign() != 0 {
+			changes = append(changes, model.CollateralChange{Asset: asset, Amount: amount.String()})
+		}
+	}
+	slices.SortFunc(changes, func(a, b model.CollateralChange) int { return strings.Compare(a.Asset, b.Asset) })
+	return changes
+}
+
+// Position prices the balances with prices keyed by lowercased asset address. Assets without a
+// balance need no price.
+func (b HistoryBalances) Position(prices map[string]string) (Position, error) {
+	position := Position{Debt: new(big.Int).Set(b.Debt)}
+	for asset, amount := range b.Collateral {
+		if amount.Sign() == 0 {
+			continue
+		}
+		collateral, err := NewCollateralPosition(asset, amount, prices[strings.ToLower(asset)])
+		if err != nil {
+			return Position{}, err
+		}
+		position.Collateral = append(position.Collateral, collateral)
+	}
+	return position, nil
+}
+
+// HistoryOperationKind names an operation after its legs in the order they were emitted,
+// such as "deposit + mint".
+func HistoryOperationKind(legs []model.HistoryEntry) string {
+	types := make([]string, len(legs))
+	for i, leg := range legs {
+		types[i] = string(leg.Type)
+	}
+	return strings.Join(types, " + ")
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+)
+
+func TestHistoryCursorRoundTrip(t *testing.T) {
+	tests := []model.HistoryCursor{
+		{BlockNumber: 0, LogIndex: 0},
+		{BlockNumber: 19000000, LogIndex: 7},
+		{BlockNumber: 18446744073709551615, LogIndex: 4294967295},
+		{BlockNumber: 0, LogIndex: 0, Key: "deposit:12"},
+		{BlockNumber: 19000000, LogIndex: 7, Key: "0xabc"},
+	}
+
+	for _, cursor := range tests {
+		decoded, err := DecodeHistoryCursor(EncodeHistoryCursor(cursor))
+		if err != nil {
+			t.Fatalf("DecodeHistoryCursor(EncodeHistoryCursor(%+v)) unexpected error: %v", cursor, err)
+		}
+		if decoded != cursor {
+			t.Errorf("DecodeHistoryCursor(EncodeHistoryCursor(%+v)) = %+v", cursor, decoded)
+		}
+	}
+}
+
+func TestDecodeHistoryCursor_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "not base64", value: "!!!"},
+		{name: "missing log index", value: base64.RawURLEncoding.EncodeToString([]byte("123"))},
+		{name: "not numbers", value: base64.RawURLEncoding.EncodeToString([]byte("a:b"))},
+		{name: "negative block", value: base64.RawURLEncoding.EncodeToString([]byte("-1:2"))},
+		{name: "log index overflow", value: base64.RawURLEncoding.EncodeToString([]byte("1:4294967296:deposit:1"))},
+		{name: "empty", value: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeHistoryCursor(tt.value)
+			if !errors.Is(err, model.ErrInvalidHistoryCursor) {
+				t.Errorf("DecodeHistoryCursor(%q) error = %v, want ErrInvalidHistoryCursor", tt.value, err)
+			}
+		})
+	}
+}
+
+func TestDecodeHistoryCursor_WithoutKey(t *testing.T) {
+	cursor, err := DecodeHistoryCursor(base64.RawURLEncoding.EncodeToString([]byte("19000000:7")))
+	if err != nil {
+		t.Fatalf("DecodeHistoryCursor() unexpected error: %v", err)
+	}
+	if want := (model.HistoryCursor{BlockNumber: 19000000, LogIndex: 7}); cursor != want {
+		t.Errorf("DecodeHistoryCursor() = %+v, want %+v", cursor, want)
+	}
+}
+
+func historyEntry(entryType model.TransactionType, asset string, amount, collateralAmount int64) model.HistoryEntry {
+	return model.HistoryEntry{
+		Type:             entryType,
+		Asset:            asset,
+		Amount:           model.BigInt{Int: big.NewInt(amount)},
+		CollateralAmount: model.BigInt{Int: big.NewInt(collateralAmount)},
+	}
+}
+
+func TestHistoryBalancesApply(t *testing.T) {
+	tests := []struct {
+		name           string
+		entries        []model.HistoryEntry
+		wantCollateral map[string]int64
+		wantDebt       int64
+	}{
+		{
+			name:           "deposit and mint",
+			entries:        []model.HistoryEntry{historyEntry(model.TransactionTypeDeposit, "0xeth", 100, 100), historyEntry(model.TransactionTypeMint, "", 40, 0)},
+			wantCollateral: map[string]int64{"0xeth": 100},
+			wantDebt:       40,
+		},
+		{
+			name:           "burn and redeem",
+			entries:        []model.HistoryEntry{historyEntry(model.TransactionTypeBurn, "", 40, 0), historyEntry(model.TransactionTypeRedeem, "0xeth", 30, 30)},
+			wantCollateral: map[string]int64{"0xeth": -30},
+			wantDebt:       -40,
+		},
+		{
+			name:           "liquidation removes seized collateral and covered debt",
+			entries:        []model.HistoryEntry{historyEntry(model.TransactionTypeDeposit, "0xbtc", 100, 100), historyEntry(model.TransactionTypeMint, "", 50, 0), historyEntry(model.TransactionTypeLiquidation, "0xbtc", 20, 22)},
+			wantCollateral: map[string]int64{"0xbtc": 78},
+			wantDebt:       30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balances := NewHistoryBalances()
+			for _, entry := range tt.entries {
+				balances.Apply(entry)
+			}
+
+			for asset, want := range tt.wantCollateral {
+				if got := balances.Collateral[asset]; got == nil || got.Int64() != want {
+					t.Errorf("Collateral[%s] = %v, want %d", asset, got, want)
+				}
+			}
+			if balances.Debt.Int64() != tt.wantDebt {
+				t.Errorf("Debt = %s, want %d", balances.Debt, tt.wantDebt)
+			}
+		})
+	}
+}
+
+func TestHistoryBalancesCollateralChanges(t *testing.T) {
+	balances := NewHistoryBalances()
+	balances.Apply(historyEntry(model.TransactionTypeDeposit, "0xeth", 100, 100))
+	balances.Apply(historyEntry(model.TransactionTypeRedeem, "0xbtc", 5, 5))
+	balances.Apply(historyEntry(model.TransactionTypeDeposit, "0xaaa", 7, 7))
+	balances.Apply(historyEntry(model.TransactionTypeRedeem, "0xaaa", 7, 7))
+
+	got := balances.CollateralChanges()
+	want := []model.CollateralChange{{Asset: "0xbtc", Amount: "-5"}, {Asset: "0xeth", Amount: "100"}}
+	if len(got) != len(want) {
+		t.Fatalf("CollateralChanges() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("CollateralChanges()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestHistoryBalancesPosition(t *testing.T) {
+	balances := NewHistoryBalances()
+	balances.Apply(historyEntry(model.TransactionTypeDeposit, "0xETH", 100, 100))
+	balances.Apply(historyEntry(model.TransactionTypeDeposit, "0xbtc", 5, 5))
+	balances.Apply(historyEntry(model.TransactionTypeRedeem, "0xbtc", 5, 5))
+
+	position, err := balances.Position(map[string]string{"0xeth": "2000"})
+	if err != nil {
+		t.Fatalf("Position() unexpected error: %v", err)
+	}
+	if len(position.Collateral) != 1 || position.Collateral[0].Token != "0xETH" {
+		t.Errorf("Position().Collateral = %+v, want only 0xETH", position.Collateral)
+	}
+
+	if _, err := balances.Position(map[string]string{}); err == nil {
+		t.Error("Position() without a price for a held asset should fail")
+	}
+}
+
+func TestHistoryOperationKind(t *testing.T) {
+	tests := []struct {
+		name string
+		legs []model.HistoryEntry
+		want string
+	}{
+		{"single leg", []model.HistoryEntry{{Type: model.TransactionTypeDeposit}}, "deposit"},
+		{"deposit and mint", []model.HistoryEntry{{Type: model.TransactionTypeDeposit}, {Type: model.TransactionTypeMint}}, "deposit + mint"},
+		{"burn and redeem", []model.HistoryEntry{{Type: model.TransactionTypeBurn}, {Type: model.TransactionTypeRedeem}}, "burn + redeem"},
+		{"no legs", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HistoryOperationKind(tt.legs); got != tt.want {
+				t.Errorf("HistoryOperationKind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
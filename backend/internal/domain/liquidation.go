@@ -0,0 +1,315 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+)
+
+// CollateralPosition is a single collateral balance priced with the 8 decimal feed answer used on-chain.
+type CollateralPosition struct {
+	Token  string
+	Amount *big.Int
+	Price  *big.Int
+}
+
+// Position mirrors the AUSDEngine account state. Unlike the cached 8 decimal collateral values,
+// every USD amount derived from it uses the contract's 18 decimal math and rounding.
+type Position struct {
+	Collateral []CollateralPosition
+	Debt       *big.Int
+}
+
+type LiquidationPlan struct {
+	Token              string
+	DebtToCover        *big.Int
+	CollateralSeized   *big.Int
+	BonusCollateral    *big.Int
+	CollateralValueUSD *big.Int
+	ProfitUSD          *big.Int
+	HealthFactorBefore *big.Int
+	HealthFactorAfter  *big.Int
+}
+
+func NewCollateralPosition(token string, amount *big.Int, priceUSD string) (CollateralPosition, error) {
+	price, ok := ParseDecimalToScaledInt(priceUSD, constants.PRICE_PRECISION)
+	if !ok || price.Sign() <= 0 {
+		return CollateralPosition{}, fmt.Errorf("invalid price for %s", token)
+	}
+
+	return CollateralPosition{
+		Token:  token,
+		Amount: new(big.Int).Set(amount),
+		Price:  price,
+	}, nil
+}
+
+// ContractUSDValue follows AUSDEngine._getCollateralInUSD: price * 1e10 * amount / 1e18.
+func ContractUSDValue(amount, price *big.Int) *big.Int {
+	value := new(big.Int).Mul(price, constants.ADDITIONAL_PRICE_PRECISION)
+	value.Mul(value, amount)
+	return value.Div(value, constants.PRECISION)
+}
+
+// ContractTokenAmountFromUSD follows AUSDEngine.getTokenAmountFromUSD: usd * 1e18 / (price * 1e10).
+func ContractTokenAmountFromUSD(usdAmount, price *big.Int) *big.Int {
+	priceAdjusted := new(big.Int).Mul(price, constants.ADDITIONAL_PRICE_PRECISION)
+	amount := new(big.Int).Mul(usdAmount, constants.PRECISION)
+	return amount.Div(amount, priceAdjusted)
+}
+
+// ContractHealthFactor follows AUSDEngine._calculateHealthFactor, returning max uint256 when there is no debt.
+func ContractHealthFactor(collateralValueUSD, debt *big.Int) *big.Int {
+	if debt == nil || debt.Sign() == 0 {
+		return new(big.Int).Set(constants.MAX_UINT256)
+	}
+
+	collateralAdjusted := new(big.Int).Mul(collateralValueUSD, constants.LIQUIDATION_THRESHOLD)
+	collateralAdjusted.Div(collateralAdjusted, constants.LIQUIDATION_PRECISION)
+	collateralAdjusted.Mul(collateralAdjusted, constants.PRECISION)
+	return collateralAdjusted.Div(collateralAdjusted, debt)
+}
+
+// LiquidationCollateral returns the collateral the engine pays out for covering debtToCover,
+// split into the base amount and the LIQUIDATION_BONUS.
+func LiquidationCollateral(debtToCover, price *big.Int) (*big.Int, *big.Int) {
+	tokenAmount := ContractTokenAmountFromUSD(debtToCover, price)
+	bonus := new(big.Int).Mul(tokenAmount, constants.LIQUIDATION_BONUS)
+	bonus.Div(bonus, constants.LIQUIDATION_PRECISION)
+	return tokenAmount, bonus
+}
+
+// EstimateLiquidationBonusUSD values the LIQUIDATION_BONUS paid for covering debtToCover. The bonus is
+// a share of the collateral bought at the liquidation's own price, so in USD it is that share of the
+// debt covered, up to the engine's rounding.
+func EstimateLiquidationBonusUSD(debtToCover *big.Int) *big.Int {
+	bonus := new(big.Int).Mul(debtToCover, constants.LIQUIDATION_BONUS)
+	return bonus.Div(bonus, constants.LIQUIDATION_PRECISION)
+}
+
+func (p Position) CollateralValueUSD() *big.Int {
+	total := big.NewInt(0)
+	for _, collateral := range p.Collateral {
+		total.Add(total, ContractUSDValue(collateral.Amount, collateral.Price))
+	}
+	return total
+}
+
+func (p Position) HealthFactor() *big.Int {
+	return ContractHealthFactor(p.CollateralValueUSD(), p.Debt)
+}
+
+func (p Position) collateral(token string) (CollateralPosition, bool) {
+	for _, collateral := range p.Collateral {
+		if collateral.Token == token {
+			return collateral, true
+		}
+	}
+	return CollateralPosition{}, false
+}
+
+// Liquidate applies a liquidation of debtToCover against token the same way AUSDEngine.liquidate
+// updates storage, without any of the revert checks.
+func (p Position) Liquidate(token string, debtToCover *big.Int) Position {
+	next := Position{
+		Collateral: make([]CollateralPosition, 0, len(p.Collateral)),
+		Debt:       new(big.Int).Sub(p.Debt, debtToCover),
+	}
+
+	for _, collateral := range p.Collateral {
+		amount := new(big.Int).Set(collateral.Amount)
+		if collateral.Token == token {
+			tokenAmount, bonus := LiquidationCollateral(debtToCover, collateral.Price)
+			amount.Sub(amount, tokenAmount)
+			amount.Sub(amount, bonus)
+		}
+		next.Collateral = append(next.Collateral, CollateralPosition{
+			Token:  collateral.Token,
+			Amount: amount,
+			Price:  collateral.Price,
+		})
+	}
+
+	return next
+}
+
+type LiquidationSimulation struct {
+	LiquidationPlan
+	Revert string
+	After  Position
+}
+
+// SimulateLiquidation replays AUSDEngine.liquidate for debtToCover against token, in the same order of
+// checks, and reports the custom error the engine would revert with. Revert is empty when the call
+// would succeed. The liquidator's own health factor check is not covered.
+func SimulateLiquidation(position Position, token string, debtToCover *big.Int) LiquidationSimulation {
+	simulation := LiquidationSimulation{
+		LiquidationPlan: LiquidationPlan{
+			Token:              token,
+			DebtToCover:        new(big.Int).Set(debtToCover),
+			CollateralSeized:   big.NewInt(0),
+			BonusCollateral:    big.NewInt(0),
+			CollateralValueUSD: big.NewInt(0),
+			ProfitUSD:          big.NewInt(0),
+			HealthFactorBefore: position.HealthFactor(),
+		},
+		After: position,
+	}
+	simulation.HealthFactorAfter = simulation.HealthFactorBefore
+
+	if debtToCover.Sign() <= 0 {
+		simulation.Revert = constants.REVERT_MUST_BE_MORE_THAN_ZERO
+		return simulation
+	}
+
+	if !IsLiquidatable(simulation.HealthFactorBefore) {
+		simulation.Revert = constants.REVERT_HEALTH_FACTOR_OK
+		return simulation
+	}
+
+	collateral, ok := position.collateral(token)
+	if !ok || collateral.Amount.Sign() == 0 {
+		simulation.Revert = constants.REVERT_INSUFFICIENT_COLLATERAL
+		return simulation
+	}
+
+	tokenAmount, bonus := LiquidationCollateral(debtToCover, collateral.Price)
+	seized := new(big.Int).Add(tokenAmount, bonus)
+	collateralValueUSD := ContractUSDValue(seized, collateral.Price)
+
+	simulation.CollateralSeized = seized
+	simulation.BonusCollateral = bonus
+	simulation.CollateralValueUSD = collateralValueUSD
+	simulation.ProfitUSD = new(big.Int).Sub(collateralValueUSD, debtToCover)
+
+	if collateral.Amount.Cmp(seized) < 0 {
+		simulation.Revert = constants.REVERT_INSUFFICIENT_COLLATERAL
+		return simulation
+	}
+
+	if position.Debt.Cmp(debtToCover) < 0 {
+		simulation.Revert = constants.REVERT_BURN_AMOUNT_EXCEEDS_DEBT
+		return simulation
+	}
+
+	simulation.After = position.Liquidate(token, debtToCover)
+	simulation.HealthFactorAfter = simulation.After.HealthFactor()
+
+	if simulation.HealthFactorAfter.Cmp(simulation.HealthFactorBefore) <= 0 {
+		simulation.Revert = constants.REVERT_HEALTH_FACTOR_NOT_IMPROVED
+	}
+
+	return simulation
+}
+
+// MaxLiquidation returns the largest debtToCover on token that the engine would accept: it is bounded
+// by the user's debt and by the collateral available to pay out the bonus, and must improve the
+// health factor. Because the post-liquidation health factor moves monotonically with debtToCover,
+// if the largest amount does not improve it no smaller amount will either.
+func MaxLiquidation(position Position, token string) (LiquidationPlan, bool) {
+	collateral, ok := position.collateral(token)
+	if !ok || collateral.Amount.Sign() <= 0 || position.Debt == nil || position.Debt.Sign() <= 0 {
+		return LiquidationPlan{}, false
+	}
+
+	seizable := new(big.Int).Mul(collateral.Amount, constants.LIQUIDATION_PRECISION)
+	seizable.Div(seizable, new(big.Int).Add(constants.LIQUIDATION_PRECISION, constants.LIQUIDATION_BONUS))
+
+	debtToCover := ContractUSDValue(seizable, collateral.Price)
+	if debtToCover.Cmp(position.Debt) > 0 {
+		debtToCover = new(big.Int).Set(position.Debt)
+	}
+
+	simulation := SimulateLiquidation(position, token, debtToCover)
+	if simulation.Revert != "" {
+		return LiquidationPlan{}, false
+	}
+
+	return simulation.LiquidationPlan, true
+}
+
+// BestLiquidation picks the collateral token whose maximum liquidation yields the highest USD profit.
+func BestLiquidation(position Position) (LiquidationPlan, bool) {
+	var best LiquidationPlan
+	found := false
+
+	for _, collateral := range position.Collateral {
+		plan, ok := MaxLiquidation(position, collateral.Token)
+		if !ok {
+			continue
+		}
+		if !found || plan.ProfitUSD.Cmp(best.ProfitUSD) > 0 {
+			best = plan
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// LiquidationPrice returns the highest 8 decimal price of token at which the position becomes
+// liquidatable, assuming every other collateral price stays fixed. Since the engine's health factor
+// is below MIN_HEALTH_FACTOR exactly when collateral value < debt * LIQUIDATION_PRECISION / LIQUIDATION_THRESHOLD,
+// the result is exact for the contract's rounding. A zero price means the other collateral alone keeps
+// the position safe. It reports false when the position has no debt or does not hold token.
+func LiquidationPrice(position Position, token string) (*big.Int, bool) {
+	if position.Debt == nil || position.Debt.Sign() == 0 {
+		return nil, false
+	}
+
+	collateral, ok := position.collateral(token)
+	if !ok || collateral.Amount.Sign() <= 0 {
+		return nil, false
+	}
+
+	otherCollateralUSD := big.NewInt(0)
+	for _, other := range position.Collateral {
+		if other.Token == token {
+			continue
+		}
+		otherCollateralUSD.Add(otherCollateralUSD, ContractUSDValue(other.Amount, other.Price))
+	}
+
+	remaining := new(big.Int).Sub(liquidationCollateralValue(position.Debt), otherCollateralUSD)
+	if remaining.Sign() <= 0 {
+		return big.NewInt(0), true
+	}
+
+	numerator := new(big.Int).Mul(remaining, constants.PRECISION)
+	denominator := new(big.Int).Mul(collateral.Amount, constants.ADDITIONAL_PRICE_PRECISION)
+
+	price, mod := new(big.Int).DivMod(numerator, denominator, new(big.Int))
+	if mod.Sign() == 0 {
+		price.Sub(price, big.NewInt(1))
+	}
+
+	return price, true
+}
+
+// LiquidationPriceDropPercentage returns the uniform drop, in percent, of every collateral price
+// beyond which the position becomes liquidatable. It is zero for positions that are already
+// liquidatable and reports false when the position has no debt.
+func LiquidationPriceDropPercentage(position Position) (float64, bool) {
+	if position.Debt == nil || position.Debt.Sign() == 0 {
+		return 0.0, false
+	}
+
+	collateralValueUSD := position.CollateralValueUSD()
+	threshold := liquidationCollateralValue(position.Debt)
+	if collateralValueUSD.Cmp(threshold) <= 0 {
+		return 0.0, true
+	}
+
+	drop := new(big.Int).Sub(collateralValueUSD, threshold)
+	drop.Mul(drop, constants.PERCENTAGE_MULTIPLIER)
+	drop.Div(drop, collateralValueUSD)
+
+	return float64(drop.Int64()) / constants.PERCENTAGE_BASE_DIVISOR, true
+}
+
+// liquidationCollateralValue is the collateral value below which debt makes a position liquidatable.
+func liquidationCollateralValue(debt *big.Int) *big.Int {
+	value := new(big.Int).Mul(debt, constants.LIQUIDATION_PRECISION)
+	return value.Div(value, constants.LIQUIDATION_THRESHOLD)
+}
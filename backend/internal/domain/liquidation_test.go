@@ -0,0 +1,448 @@
+package domain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+)
+
+func usd(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), constants.PRECISION)
+}
+
+func mustBigInt(t *testing.T, value string) *big.Int {
+	t.Helper()
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		t.Fatalf("invalid big int %q", value)
+	}
+	return n
+}
+
+func mustCollateral(t *testing.T, token string, amount *big.Int, price string) CollateralPosition {
+	t.Helper()
+	collateral, err := NewCollateralPosition(token, amount, price)
+	if err != nil {
+		t.Fatalf("NewCollateralPosition() error = %v", err)
+	}
+	return collateral
+}
+
+func TestContractHealthFactor(t *testing.T) {
+	tests := []struct {
+		name       string
+		collateral *big.Int
+		debt       *big.Int
+		expected   *big.Int
+	}{
+		{
+			name:       "exactly at threshold",
+			collateral: usd(200),
+			debt:       usd(100),
+			expected:   constants.MIN_HEALTH_FACTOR,
+		},
+		{
+			name:       "below threshold",
+			collateral: usd(2000),
+			debt:       usd(1100),
+			expected:   mustBigInt(t, "909090909090909090"),
+		},
+		{
+			name:       "no debt returns max uint256",
+			collateral: usd(1),
+			debt:       big.NewInt(0),
+			expected:   constants.MAX_UINT256,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ContractHealthFactor(tt.collateral, tt.debt)
+			if result.Cmp(tt.expected) != 0 {
+				t.Errorf("ContractHealthFactor() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLiquidationCollateral(t *testing.T) {
+	price, _ := ParseDecimalToScaledInt("2000", constants.PRICE_PRECISION)
+
+	tokenAmount, bonus := LiquidationCollateral(usd(1100), price)
+
+	if tokenAmount.Cmp(mustBigInt(t, "550000000000000000")) != 0 {
+		t.Errorf("tokenAmount = %s, want 550000000000000000", tokenAmount)
+	}
+	if bonus.Cmp(mustBigInt(t, "55000000000000000")) != 0 {
+		t.Errorf("bonus = %s, want 55000000000000000", bonus)
+	}
+}
+
+func TestEstimateLiquidationBonusUSD(t *testing.T) {
+	price, _ := ParseDecimalToScaledInt("2000", constants.PRICE_PRECISION)
+	_, bonus := LiquidationCollateral(usd(1100), price)
+
+	estimate := EstimateLiquidationBonusUSD(usd(1100))
+
+	if estimate.Cmp(usd(110)) != 0 {
+		t.Errorf("EstimateLiquidationBonusUSD = %s, want %s", estimate, usd(110))
+	}
+	if value := ContractUSDValue(bonus, price); value.Cmp(estimate) != 0 {
+		t.Errorf("bonus collateral is worth %s, estimate %s", value, estimate)
+	}
+}
+
+func TestMaxLiquidation(t *testing.T) {
+	tests := []struct {
+		name            string
+		position        Position
+		token           string
+		ok              bool
+		debtToCover     string
+		collateralSeize string
+		profit          string
+	}{
+		{
+			name: "full debt covered when collateral allows it",
+			position: Position{
+				Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")},
+				Debt:       usd(1100),
+			},
+			token:           "ETH",
+			ok:              true,
+			debtToCover:     "1100000000000000000000",
+			collateralSeize: "605000000000000000",
+			profit:          "110000000000000000000",
+		},
+		{
+			name: "bounded by collateral available for the bonus",
+			position: Position{
+				Collateral: []CollateralPosition{
+					mustCollateral(t, "ETH", usd(1), "2000"),
+					mustCollateral(t, "BTC", new(big.Int).Div(usd(1), big.NewInt(100)), "40000"),
+				},
+				Debt: usd(1300),
+			},
+			token:           "BTC",
+			ok:              true,
+			debtToCover:     "363636363636363600000",
+			collateralSeize: "9999999999999999",
+			profit:          "36363636363636360000",
+		},
+		{
+			name: "health factor not improved when collateral is below 110% of debt",
+			position: Position{
+				Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")},
+				Debt:       usd(1900),
+			},
+			token: "ETH",
+			ok:    false,
+		},
+		{
+			name: "healthy position cannot be liquidated",
+			position: Position{
+				Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")},
+				Debt:       usd(500),
+			},
+			token: "ETH",
+			ok:    false,
+		},
+		{
+			name: "token without collateral",
+			position: Position{
+				Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")},
+				Debt:       usd(1100),
+			},
+			token: "BTC",
+			ok:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, ok := MaxLiquidation(tt.position, tt.token)
+			if ok != tt.ok {
+				t.Fatalf("MaxLiquidation() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+
+			if plan.DebtToCover.String() != tt.debtToCover {
+				t.Errorf("DebtToCover = %s, want %s", plan.DebtToCover, tt.debtToCover)
+			}
+			if plan.CollateralSeized.String() != tt.collateralSeize {
+				t.Errorf("CollateralSeized = %s, want %s", plan.CollateralSeized, tt.collateralSeize)
+			}
+			if plan.ProfitUSD.String() != tt.profit {
+				t.Errorf("ProfitUSD = %s, want %s", plan.ProfitUSD, tt.profit)
+			}
+			if plan.HealthFactorAfter.Cmp(plan.HealthFactorBefore) <= 0 {
+				t.Errorf("HealthFactorAfter = %s, want above %s", plan.HealthFactorAfter, plan.HealthFactorBefore)
+			}
+		})
+	}
+}
+
+func TestBestLiquidation(t *testing.T) {
+	position := Position{
+		Collateral: []CollateralPosition{
+			mustCollateral(t, "ETH", usd(1), "2000"),
+			mustCollateral(t, "BTC", new(big.Int).Div(usd(1), big.NewInt(100)), "40000"),
+		},
+		Debt: usd(1300),
+	}
+
+	plan, ok := BestLiquidation(position)
+	if !ok {
+		t.Fatal("BestLiquidation() found no opportunity")
+	}
+	if plan.Token != "ETH" {
+		t.Errorf("Token = %s, want ETH", plan.Token)
+	}
+	if plan.DebtToCover.Cmp(usd(1300)) != 0 {
+		t.Errorf("DebtToCover = %s, want %s", plan.DebtToCover, usd(1300))
+	}
+}
+
+func TestSimulateLiquidation(t *testing.T) {
+	position := Position{
+		Collateral: []CollateralPosition{
+			mustCollateral(t, "ETH", usd(1), "2000"),
+			mustCollateral(t, "BTC", big.NewInt(0), "40000"),
+		},
+		Debt: usd(1300),
+	}
+
+	tests := []struct {
+		name        string
+		position    Position
+		token       string
+		debtToCover *big.Int
+		revert      string
+		seized      string
+	}{
+		{
+			name:        "successful liquidation",
+			position:    position,
+			token:       "ETH",
+			debtToCover: usd(500),
+			revert:      "",
+			seized:      "275000000000000000",
+		},
+		{
+			name:        "zero debt to cover",
+			position:    position,
+			token:       "ETH",
+			debtToCover: big.NewInt(0),
+			revert:      constants.REVERT_MUST_BE_MORE_THAN_ZERO,
+			seized:      "0",
+		},
+		{
+			name: "healthy position",
+			position: Position{
+				Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")},
+				Debt:       usd(1000),
+			},
+			token:       "ETH",
+			debtToCover: usd(100),
+			revert:      constants.REVERT_HEALTH_FACTOR_OK,
+			seized:      "0",
+		},
+		{
+			name:        "no collateral in token",
+			position:    position,
+			token:       "BTC",
+			debtToCover: usd(100),
+			revert:      constants.REVERT_INSUFFICIENT_COLLATERAL,
+			seized:      "0",
+		},
+		{
+			name:        "seizure exceeds deposited collateral",
+			position:    position,
+			token:       "ETH",
+			debtToCover: usd(1900),
+			revert:      constants.REVERT_INSUFFICIENT_COLLATERAL,
+			seized:      "1045000000000000000",
+		},
+		{
+			name: "debt to cover exceeds debt",
+			position: Position{
+				Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(2), "2000")},
+				Debt:       usd(2100),
+			},
+			token:       "ETH",
+			debtToCover: usd(2200),
+			revert:      constants.REVERT_BURN_AMOUNT_EXCEEDS_DEBT,
+			seized:      "1210000000000000000",
+		},
+		{
+			name: "health factor not improved",
+			position: Position{
+				Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")},
+				Debt:       usd(1900),
+			},
+			token:       "ETH",
+			debtToCover: usd(100),
+			revert:      constants.REVERT_HEALTH_FACTOR_NOT_IMPROVED,
+			seized:      "55000000000000000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simulation := SimulateLiquidation(tt.position, tt.token, tt.debtToCover)
+
+			if simulation.Revert != tt.revert {
+				t.Errorf("Revert = %q, want %q", simulation.Revert, tt.revert)
+			}
+			if simulation.CollateralSeized.String() != tt.seized {
+				t.Errorf("CollateralSeized = %s, want %s", simulation.CollateralSeized, tt.seized)
+			}
+		})
+	}
+}
+
+func TestLiquidationPrice(t *testing.T) {
+	mixed := Position{
+		Collateral: []CollateralPosition{
+			mustCollateral(t, "ETH", usd(1), "2000"),
+			mustCollateral(t, "BTC", new(big.Int).Div(usd(1), big.NewInt(100)), "40000"),
+		},
+		Debt: usd(800),
+	}
+
+	tests := []struct {
+		name     string
+		position Position
+		token    string
+		ok       bool
+		expected string
+	}{
+		{
+			name: "single collateral",
+			position: Position{
+				Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")},
+				Debt:       usd(800),
+			},
+			token:    "ETH",
+			ok:       true,
+			expected: "159999999999",
+		},
+		{
+			name:     "other collateral keeps fixed prices",
+			position: mixed,
+			token:    "ETH",
+			ok:       true,
+			expected: "119999999999",
+		},
+		{
+			name:     "other collateral alone keeps the position safe",
+			position: mixed,
+			token:    "BTC",
+			ok:       true,
+			expected: "0",
+		},
+		{
+			name: "no debt",
+			position: Position{
+				Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")},
+				Debt:       big.NewInt(0),
+			},
+			token: "ETH",
+			ok:    false,
+		},
+		{
+			name:     "token not held",
+			position: Position{Debt: usd(800)},
+			token:    "ETH",
+			ok:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, ok := LiquidationPrice(tt.position, tt.token)
+			if ok != tt.ok {
+				t.Fatalf("LiquidationPrice() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && price.String() != tt.expected {
+				t.Errorf("LiquidationPrice() = %s, want %s", price, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLiquidationPriceIsExactBoundary(t *testing.T) {
+	position := Position{
+		Collateral: []CollateralPosition{mustCollateral(t, "ETH", mustBigInt(t, "1234567890123456789"), "1987.65")},
+		Debt:       usd(1000),
+	}
+
+	price, ok := LiquidationPrice(position, "ETH")
+	if !ok {
+		t.Fatal("LiquidationPrice() reported no price")
+	}
+
+	at := Position{Collateral: []CollateralPosition{{Token: "ETH", Amount: position.Collateral[0].Amount, Price: price}}, Debt: position.Debt}
+	if !IsLiquidatable(at.HealthFactor()) {
+		t.Errorf("position at liquidation price %s should be liquidatable, health factor %s", price, at.HealthFactor())
+	}
+
+	above := Position{Collateral: []CollateralPosition{{Token: "ETH", Amount: position.Collateral[0].Amount, Price: new(big.Int).Add(price, big.NewInt(1))}}, Debt: position.Debt}
+	if IsLiquidatable(above.HealthFactor()) {
+		t.Errorf("position above liquidation price should not be liquidatable, health factor %s", above.HealthFactor())
+	}
+}
+
+func TestLiquidationPriceDropPercentage(t *testing.T) {
+	tests := []struct {
+		name     string
+		position Position
+		ok       bool
+		expected float64
+	}{
+		{
+			name: "mixed collateral",
+			position: Position{
+				Collateral: []CollateralPosition{
+					mustCollateral(t, "ETH", usd(1), "2000"),
+					mustCollateral(t, "BTC", new(big.Int).Div(usd(1), big.NewInt(100)), "40000"),
+				},
+				Debt: usd(800),
+			},
+			ok:       true,
+			expected: 33.33,
+		},
+		{
+			name: "already liquidatable",
+			position: Position{
+				Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")},
+				Debt:       usd(1100),
+			},
+			ok:       true,
+			expected: 0.0,
+		},
+		{
+			name: "no debt",
+			position: Position{
+				Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")},
+				Debt:       big.NewInt(0),
+			},
+			ok: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drop, ok := LiquidationPriceDropPercentage(tt.position)
+			if ok != tt.ok {
+				t.Fatalf("LiquidationPriceDropPercentage() ok = %v, want %v", ok, tt.ok)
+			}
+			if drop != tt.expected {
+				t.Errorf("LiquidationPriceDropPercentage() = %v, want %v", drop, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"math/big"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+)
+
+// PositionStep is a single AUSDEngine call made by the position's owner. Token is the collateral
+// name and is ignored for mints and burns.
+type PositionStep struct {
+	Type   model.TransactionType
+	Token  string
+	Amount *big.Int
+}
+
+type StepSimulation struct {
+	PositionStep
+	Revert             string
+	HealthFactorBefore *big.Int
+	HealthFactorAfter  *big.Int
+	After              Position
+}
+
+type OperationsSimulation struct {
+	Steps []StepSimulation
+	// RevertIndex is the index of the first step that reverts, or -1 when every step succeeds.
+	RevertIndex int
+	Final       Position
+}
+
+// SimulateOperations applies steps in order the way AUSDEngine would, stopping at the first step
+// that reverts. Final is the position after the last step that succeeded. prices holds the 8 decimal
+// price of every allowed collateral, since a deposit may add a token the position does not hold yet.
+func SimulateOperations(position Position, steps []PositionStep, prices map[string]*big.Int) OperationsSimulation {
+	simulation := OperationsSimulation{RevertIndex: -1, Final: position}
+
+	for i, step := range steps {
+		result := SimulateStep(simulation.Final, step, prices)
+		simulation.Steps = append(simulation.Steps, result)
+		if result.Revert != "" {
+			simulation.RevertIndex = i
+			return simulation
+		}
+		simulation.Final = result.After
+	}
+
+	return simulation
+}
+
+// SimulateStep replays one depositCollateral, redeemCollateral, mintAUSD or burnAUSD call with the
+// engine's checks in the engine's order. As with SimulateLiquidation, After and HealthFactorAfter
+// describe the state the engine computed before reverting on a broken health factor.
+func SimulateStep(position Position, step PositionStep, prices map[string]*big.Int) StepSimulation {
+	simulation := StepSimulation{
+		PositionStep:       step,
+		HealthFactorBefore: position.HealthFactor(),
+		After:              position,
+	}
+	simulation.HealthFactorAfter = simulation.HealthFactorBefore
+
+	switch step.Type {
+	case model.TransactionTypeDeposit, model.TransactionTypeRedeem:
+		price, ok := prices[step.Token]
+		if !ok {
+			simulation.Revert = constants.REVERT_TOKEN_NOT_ALLOWED
+			return simulation
+		}
+		if step.Amount.Sign() <= 0 {
+			simulation.Revert = constants.REVERT_MUST_BE_MORE_THAN_ZERO
+			return simulation
+		}
+
+		if step.Type == model.TransactionTypeDeposit {
+			simulation.After = position.withCollateral(step.Token, step.Amount, price)
+			break
+		}
+
+		collateral, _ := position.collateral(step.Token)
+		if collateral.Amount == nil || collateral.Amount.Cmp(step.Amount) < 0 {
+			simulation.Revert = constants.REVERT_INSUFFICIENT_COLLATERAL
+			return simulation
+		}
+		simulation.After = position.withCollateral(step.Token, new(big.Int).Neg(step.Amount), price)
+	case model.TransactionTypeMint, model.TransactionTypeBurn:
+		if step.Amount.Sign() <= 0 {
+			simulation.Revert = constants.REVERT_MUST_BE_MORE_THAN_ZERO
+			return simulation
+		}
+
+		debt := new(big.Int).Set(position.Debt)
+		if step.Type == model.TransactionTypeMint {
+			debt.Add(debt, step.Amount)
+		} else {
+			if debt.Cmp(step.Amount) < 0 {
+				simulation.Revert = constants.REVERT_BURN_AMOUNT_EXCEEDS_DEBT
+				return simulation
+			}
+			debt.Sub(debt, step.Amount)
+		}
+		simulation.After = Position{Collateral: position.Collateral, Debt: debt}
+	}
+
+	simulation.HealthFactorAfter = simulation.After.HealthFactor()
+
+	// Redeems and mints end with _revertIfHealthFactorBroken; deposits and burns never check it.
+	if (step.Type == model.TransactionTypeRedeem || step.Type == model.TransactionTypeMint) && IsLiquidatable(simulation.HealthFactorAfter) {
+		simulation.Revert = constants.REVERT_HEALTH_FACTOR_BROKEN
+	}
+
+	return simulation
+}
+
+// withCollateral returns a copy of the position with delta added to token's balance, adding the
+// token at price when the position does not hold it.
+func (p Position) withCollateral(token string, delta *big.Int, price *big.Int) Position {
+	next := Position{
+		Collateral: make([]CollateralPosition, 0, len(p.Collateral)+1),
+		Debt:       p.Debt,
+	}
+
+	found := false
+	for _, collateral := range p.Collateral {
+		amount := collateral.Amount
+		if collateral.Token == token {
+			amount = new(big.Int).Add(amount, delta)
+			found = true
+		}
+		next.Collateral = append(next.Collateral, CollateralPosition{
+			Token:  collateral.Token,
+			Amount: amount,
+			Price:  collateral.Price,
+		})
+	}
+
+	if !found {
+		next.Collateral = append(next.Collateral, CollateralPosition{
+			Token:  token,
+			Amount: new(big.Int).Set(delta),
+			Price:  price,
+		})
+	}
+
+	return next
+}
@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+)
+
+func TestSimulateStep(t *testing.T) {
+	ethPrice, _ := ParseDecimalToScaledInt("2000", constants.PRICE_PRECISION)
+	prices := map[string]*big.Int{"ETH": ethPrice}
+	oneEth := usd(1)
+
+	position := Position{
+		Collateral: []CollateralPosition{mustCollateral(t, "ETH", oneEth, "2000")},
+		Debt:       usd(500),
+	}
+
+	tests := []struct {
+		name           string
+		step           PositionStep
+		expectedRevert string
+		expectedDebt   *big.Int
+		expectedUSD    *big.Int
+	}{
+		{
+			name:         "deposit adds collateral",
+			step:         PositionStep{Type: model.TransactionTypeDeposit, Token: "ETH", Amount: oneEth},
+			expectedDebt: usd(500),
+			expectedUSD:  usd(4000),
+		},
+		{
+			name:           "deposit of a token that is not allowed",
+			step:           PositionStep{Type: model.TransactionTypeDeposit, Token: "", Amount: oneEth},
+			expectedRevert: constants.REVERT_TOKEN_NOT_ALLOWED,
+			expectedDebt:   usd(500),
+			expectedUSD:    usd(2000),
+		},
+		{
+			name:           "zero deposit",
+			step:           PositionStep{Type: model.TransactionTypeDeposit, Token: "ETH", Amount: big.NewInt(0)},
+			expectedRevert: constants.REVERT_MUST_BE_MORE_THAN_ZERO,
+			expectedDebt:   usd(500),
+			expectedUSD:    usd(2000),
+		},
+		{
+			name:           "redeem more than deposited",
+			step:           PositionStep{Type: model.TransactionTypeRedeem, Token: "ETH", Amount: usd(2)},
+			expectedRevert: constants.REVERT_INSUFFICIENT_COLLATERAL,
+			expectedDebt:   usd(500),
+			expectedUSD:    usd(2000),
+		},
+		{
+			name:           "redeem that breaks the health factor",
+			step:           PositionStep{Type: model.TransactionTypeRedeem, Token: "ETH", Amount: mustBigInt(t, "600000000000000000")},
+			expectedRevert: constants.REVERT_HEALTH_FACTOR_BROKEN,
+			expectedDebt:   usd(500),
+			expectedUSD:    usd(800),
+		},
+		{
+			name:         "mint up to the minimum health factor",
+			step:         PositionStep{Type: model.TransactionTypeMint, Amount: usd(500)},
+			expectedDebt: usd(1000),
+			expectedUSD:  usd(2000),
+		},
+		{
+			name:           "mint that breaks the health factor",
+			step:           PositionStep{Type: model.TransactionTypeMint, Amount: usd(501)},
+			expectedRevert: constants.REVERT_HEALTH_FACTOR_BROKEN,
+			expectedDebt:   usd(1001),
+			expectedUSD:    usd(2000),
+		},
+		{
+			name:           "burn more than the debt",
+			step:           PositionStep{Type: model.TransactionTypeBurn, Amount: usd(501)},
+			expectedRevert: constants.REVERT_BURN_AMOUNT_EXCEEDS_DEBT,
+			expectedDebt:   usd(500),
+			expectedUSD:    usd(2000),
+		},
+		{
+			name:         "burn all debt",
+			step:         PositionStep{Type: model.TransactionTypeBurn, Amount: usd(500)},
+			expectedDebt: big.NewInt(0),
+			expectedUSD:  usd(2000),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := SimulateStep(position, tt.step, prices)
+			if result.Revert != tt.expectedRevert {
+				t.Errorf("Revert = %q, want %q", result.Revert, tt.expectedRevert)
+			}
+			if result.After.Debt.Cmp(tt.expectedDebt) != 0 {
+				t.Errorf("After.Debt = %s, want %s", result.After.Debt, tt.expectedDebt)
+			}
+			if usdValue := result.After.CollateralValueUSD(); usdValue.Cmp(tt.expectedUSD) != 0 {
+				t.Errorf("After.CollateralValueUSD() = %s, want %s", usdValue, tt.expectedUSD)
+			}
+			if result.HealthFactorAfter.Cmp(result.After.HealthFactor()) != 0 {
+				t.Errorf("HealthFactorAfter = %s, want %s", result.HealthFactorAfter, result.After.HealthFactor())
+			}
+		})
+	}
+
+	if position.Collateral[0].Amount.Cmp(oneEth) != 0 || position.Debt.Cmp(usd(500)) != 0 {
+		t.Errorf("SimulateStep() modified the original position")
+	}
+}
+
+func TestSimulateOperations(t *testing.T) {
+	ethPrice, _ := ParseDecimalToScaledInt("2000", constants.PRICE_PRECISION)
+	prices := map[string]*big.Int{"ETH": ethPrice}
+	empty := Position{Debt: big.NewInt(0)}
+
+	tests := []struct {
+		name                string
+		steps               []PositionStep
+		expectedRevertIndex int
+		expectedSteps       int
+		expectedDebt        *big.Int
+		expectedUSD         *big.Int
+	}{
+		{
+			name: "deposit and mint",
+			steps: []PositionStep{
+				{Type: model.TransactionTypeDeposit, Token: "ETH", Amount: usd(1)},
+				{Type: model.TransactionTypeMint, Amount: usd(1000)},
+			},
+			expectedRevertIndex: -1,
+			expectedSteps:       2,
+			expectedDebt:        usd(1000),
+			expectedUSD:         usd(2000),
+		},
+		{
+			name: "mint before deposit reverts and stops",
+			steps: []PositionStep{
+				{Type: model.TransactionTypeMint, Amount: usd(1000)},
+				{Type: model.TransactionTypeDeposit, Token: "ETH", Amount: usd(1)},
+			},
+			expectedRevertIndex: 0,
+			expectedSteps:       1,
+			expectedDebt:        big.NewInt(0),
+			expectedUSD:         big.NewInt(0),
+		},
+		{
+			name: "burn and redeem everything",
+			steps: []PositionStep{
+				{Type: model.TransactionTypeDeposit, Token: "ETH", Amount: usd(1)},
+				{Type: model.TransactionTypeMint, Amount: usd(100)},
+				{Type: model.TransactionTypeBurn, Amount: usd(100)},
+				{Type: model.TransactionTypeRedeem, Token: "ETH", Amount: usd(1)},
+			},
+			expectedRevertIndex: -1,
+			expectedSteps:       4,
+			expectedDebt:        big.NewInt(0),
+			expectedUSD:         big.NewInt(0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := SimulateOperations(empty, tt.steps, prices)
+			if result.RevertIndex != tt.expectedRevertIndex {
+				t.Errorf("RevertIndex = %d, want %d", result.RevertIndex, tt.expectedRevertIndex)
+			}
+			if len(result.Steps) != tt.expectedSteps {
+				t.Errorf("len(Steps) = %d, want %d", len(result.Steps), tt.expectedSteps)
+			}
+			if result.Final.Debt.Cmp(tt.expectedDebt) != 0 {
+				t.Errorf("Final.Debt = %s, want %s", result.Final.Debt, tt.expectedDebt)
+			}
+			if usdValue := result.Final.CollateralValueUSD(); usdValue.Cmp(tt.expectedUSD) != 0 {
+				t.Errorf("Final.CollateralValueUSD() = %s, want %s", usdValue, tt.expectedUSD)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"math/big"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+)
+
+// ClassifyPosition buckets a position by its 8 decimal collateral USD value and 18 decimal debt.
+// A position is insolvent once its collateral is worth less than its debt, since no liquidator
+// can profit from it regardless of the health factor. A position without debt, including a closed
+// one, is healthy.
+func ClassifyPosition(collateralValueUSD, debt *big.Int) model.PositionStatus {
+	if debt == nil || debt.Sign() <= 0 {
+		return model.PositionStatusHealthy
+	}
+
+	if BadDebt(collateralValueUSD, debt).Sign() > 0 {
+		return model.PositionStatusInsolvent
+	}
+
+	collateralValue := new(big.Int).Mul(collateralValueUSD, constants.ADDITIONAL_PRICE_PRECISION)
+	healthFactor := ContractHealthFactor(collateralValue, debt)
+	switch {
+	case IsLiquidatable(healthFactor):
+		return model.PositionStatusLiquidatable
+	case IsAtRisk(healthFactor):
+		return model.PositionStatusAtRisk
+	default:
+		return model.PositionStatusHealthy
+	}
+}
+
+// BadDebt returns the 18 decimal debt not covered by the 8 decimal collateral USD value, or zero.
+func BadDebt(collateralValueUSD, debt *big.Int) *big.Int {
+	if debt == nil || debt.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+
+	collateral := new(big.Int).Mul(collateralValueUSD, constants.ADDITIONAL_PRICE_PRECISION)
+	if collateral.Cmp(debt) >= 0 {
+		return big.NewInt(0)
+	}
+	return collateral.Sub(debt, collateral)
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+)
+
+func usd8(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), constants.PRICE_PRECISION)
+}
+
+func usd18(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), constants.PRECISION)
+}
+
+func TestClassifyPosition(t *testing.T) {
+	tests := []struct {
+		name          string
+		collateralUSD *big.Int
+		debt          *big.Int
+		expected      model.PositionStatus
+	}{
+		{name: "no debt", collateralUSD: usd8(1000), debt: big.NewInt(0), expected: model.PositionStatusHealthy},
+		{name: "closed position", collateralUSD: big.NewInt(0), debt: big.NewInt(0), expected: model.PositionStatusHealthy},
+		{name: "well collateralized", collateralUSD: usd8(4000), debt: usd18(1000), expected: model.PositionStatusHealthy},
+		{name: "below risk threshold", collateralUSD: usd8(2500), debt: usd18(1000), expected: model.PositionStatusAtRisk},
+		{name: "fractional health factor above risk threshold", collateralUSD: usd8(3800), debt: usd18(1000), expected: model.PositionStatusHealthy},
+		{name: "at risk threshold", collateralUSD: usd8(3000), debt: usd18(1000), expected: model.PositionStatusHealthy},
+		{name: "just below min health factor", collateralUSD: usd8(1999), debt: usd18(1000), expected: model.PositionStatusLiquidatable},
+		{name: "below min health factor", collateralUSD: usd8(1500), debt: usd18(1000), expected: model.PositionStatusLiquidatable},
+		{name: "collateral equals debt", collateralUSD: usd8(1000), debt: usd18(1000), expected: model.PositionStatusLiquidatable},
+		{name: "collateral below debt", collateralUSD: usd8(999), debt: usd18(1000), expected: model.PositionStatusInsolvent},
+		{name: "no collateral", collateralUSD: big.NewInt(0), debt: usd18(1), expected: model.PositionStatusInsolvent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ClassifyPosition(tt.collateralUSD, tt.debt)
+			if result != tt.expected {
+				t.Errorf("ClassifyPosition() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBadDebt(t *testing.T) {
+	tests := []struct {
+		name          string
+		collateralUSD *big.Int
+		debt          *big.Int
+		expected      *big.Int
+	}{
+		{name: "solvent", collateralUSD: usd8(1500), debt: usd18(1000), expected: big.NewInt(0)},
+		{name: "exactly covered", collateralUSD: usd8(1000), debt: usd18(1000), expected: big.NewInt(0)},
+		{name: "uncovered debt", collateralUSD: usd8(800), debt: usd18(1000), expected: usd18(200)},
+		{name: "nil debt", collateralUSD: usd8(800), debt: nil, expected: big.NewInt(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := BadDebt(tt.collateralUSD, tt.debt)
+			if result.Cmp(tt.expected) != 0 {
+				t.Errorf("BadDebt() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+)
+
+// PriceChangeBasisPoints returns the absolute change between two USD prices in basis points of the previous price.
+func PriceChangeBasisPoints(previousPrice, currentPrice string) (*big.Int, error) {
+	previous, ok := ParseDecimalToScaledInt(previousPrice, constants.PRICE_PRECISION)
+	if !ok {
+		return nil, fmt.Errorf("invalid previous price format")
+	}
+
+	current, ok := ParseDecimalToScaledInt(currentPrice, constants.PRICE_PRECISION)
+	if !ok {
+		return nil, fmt.Errorf("invalid current price format")
+	}
+
+	if previous.Sign() == 0 {
+		return nil, fmt.Errorf("previous price must be greater than zero")
+	}
+
+	change := new(big.Int).Sub(current, previous)
+	change.Abs(change)
+	change.Mul(change, constants.PERCENTAGE_MULTIPLIER)
+
+	return change.Div(change, previous), nil
+}
+
+func PriceMovedBeyondThreshold(previousPrice, currentPrice string, thresholdBasisPoints *big.Int) (bool, error) {
+	change, err := PriceChangeBasisPoints(previousPrice, currentPrice)
+	if err != nil {
+		return false, err
+	}
+
+	return change.Cmp(thresholdBasisPoints) >= 0, nil
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPriceChangeBasisPoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		previous    string
+		current     string
+		expected    *big.Int
+		shouldError bool
+	}{
+		{
+			name:     "10% drop",
+			previous: "3000.00",
+			current:  "2700.00",
+			expected: big.NewInt(1000),
+		},
+		{
+			name:     "2.5% rise",
+			previous: "40000",
+			current:  "41000",
+			expected: big.NewInt(250),
+		},
+		{
+			name:     "no change",
+			previous: "3000.12",
+			current:  "3000.12",
+			expected: big.NewInt(0),
+		},
+		{
+			name:        "zero previous price",
+			previous:    "0",
+			current:     "3000",
+			shouldError: true,
+		},
+		{
+			name:        "invalid current price",
+			previous:    "3000",
+			current:     "abc",
+			shouldError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := PriceChangeBasisPoints(tt.previous, tt.current)
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("PriceChangeBasisPoints() expected error, got %s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PriceChangeBasisPoints() unexpected error: %v", err)
+			}
+			if result.Cmp(tt.expected) != 0 {
+				t.Errorf("PriceChangeBasisPoints() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPriceMovedBeyondThreshold(t *testing.T) {
+	threshold := big.NewInt(100) // 1%
+
+	moved, err := PriceMovedBeyondThreshold("3000", "2960", threshold)
+	if err != nil || !moved {
+		t.Errorf("PriceMovedBeyondThreshold() = %v, %v; want true, nil", moved, err)
+	}
+
+	moved, err = PriceMovedBeyondThreshold("3000", "2990", threshold)
+	if err != nil || moved {
+		t.Errorf("PriceMovedBeyondThreshold() = %v, %v; want false, nil", moved, err)
+	}
+}
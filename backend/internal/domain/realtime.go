@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	RealtimeDashboardChannel    = "dashboard"
+	RealtimePricesChannel       = "prices"
+	RealtimeLiquidationsChannel = "liquidations"
+	RealtimeEventsChannel       = "events"
+	realtimeUserChannelPrefix   = "user:"
+)
+
+// RealtimeUserChannel returns the channel carrying updates for a single position. Addresses are
+// lowercased so clients do not need to know the checksummed form used by the indexer.
+func RealtimeUserChannel(address string) string {
+	return realtimeUserChannelPrefix + strings.ToLower(address)
+}
+
+// NormalizeRealtimeChannel validates a channel requested by a client and returns its canonical name.
+func NormalizeRealtimeChannel(channel string) (string, error) {
+	switch channel {
+	case RealtimeDashboardChannel, RealtimePricesChannel, RealtimeLiquidationsChannel, RealtimeEventsChannel:
+		return channel, nil
+	}
+
+	address, ok := strings.CutPrefix(channel, realtimeUserChannelPrefix)
+	if !ok {
+		return "", fmt.Errorf("unknown channel %q", channel)
+	}
+	if !common.IsHexAddress(address) {
+		return "", fmt.Errorf("invalid address in channel %q", channel)
+	}
+	return RealtimeUserChannel(address), nil
+}
+
+// ReplayRealtimeEvents returns the buffered events published after lastEventID on the given channels.
+// buffered must be ordered by ID and hold every event issued after evictedID, the newest event already
+// dropped from the buffer or 0 when none was. The second result is false when lastEventID is neither
+// buffered nor evictedID, because the events after it were evicted or it was issued by another
+// replica or before a restart, in which case the client cannot resume and must refetch its state.
+func ReplayRealtimeEvents(buffered []model.RealtimeEvent, evictedID, lastEventID uint64, channels []string) ([]model.RealtimeEvent, bool) {
+	known := evictedID != 0 && lastEventID == evictedID
+	if len(buffered) > 0 && lastEventID >= buffered[0].ID && lastEventID <= buffered[len(buffered)-1].ID {
+		known = true
+	}
+	if !known {
+		return []model.RealtimeEvent{}, false
+	}
+
+	wanted := make(map[string]struct{}, len(channels))
+	for _, channel := range channels {
+		wanted[channel] = struct{}{}
+	}
+
+	replay := []model.RealtimeEvent{}
+	for _, event := range buffered {
+		if event.ID <= lastEventID {
+			continue
+		}
+		if _, ok := wanted[event.Channel]; ok {
+			replay = append(replay, event)
+		}
+	}
+	return replay, true
+}
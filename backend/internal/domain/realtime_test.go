@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+)
+
+func TestNormalizeRealtimeChannel(t *testing.T) {
+	tests := []struct {
+		name        string
+		channel     string
+		expected    string
+		expectError bool
+	}{
+		{name: "dashboard", channel: "dashboard", expected: "dashboard"},
+		{name: "prices", channel: "prices", expected: "prices"},
+		{name: "liquidations", channel: "liquidations", expected: "liquidations"},
+		{name: "events", channel: "events", expected: "events"},
+		{name: "user lowercased", channel: "user:0xAbC0000000000000000000000000000000000001", expected: "user:0xabc0000000000000000000000000000000000001"},
+		{name: "invalid address", channel: "user:0x123", expectError: true},
+		{name: "unknown channel", channel: "blocks", expectError: true},
+		{name: "empty", channel: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NormalizeRealtimeChannel(tt.channel)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("NormalizeRealtimeChannel(%q) expected error", tt.channel)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeRealtimeChannel(%q) unexpected error: %v", tt.channel, err)
+			}
+			if result != tt.expected {
+				t.Errorf("NormalizeRealtimeChannel(%q) = %q, want %q", tt.channel, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRealtimeUserChannel(t *testing.T) {
+	result := RealtimeUserChannel("0xABCDEF")
+	if result != "user:0xabcdef" {
+		t.Errorf("RealtimeUserChannel() = %q, want %q", result, "user:0xabcdef")
+	}
+}
+
+func TestReplayRealtimeEvents(t *testing.T) {
+	buffered := []model.RealtimeEvent{
+		{ID: 11, Channel: "dashboard"},
+		{ID: 12, Channel: "user:0xabc"},
+		{ID: 13, Channel: "events"},
+		{ID: 14, Channel: "user:0xabc"},
+	}
+
+	tests := []struct {
+		name             string
+		buffered         []model.RealtimeEvent
+		evictedID        uint64
+		lastEventID      uint64
+		channels         []string
+		expectedIDs      []uint64
+		expectedComplete bool
+	}{
+		{name: "resumes after last event", buffered: buffered, evictedID: 10, lastEventID: 11, channels: []string{"user:0xabc", "events"}, expectedIDs: []uint64{12, 13, 14}, expectedComplete: true},
+		{name: "filters channels", buffered: buffered, evictedID: 10, lastEventID: 11, channels: []string{"user:0xabc"}, expectedIDs: []uint64{12, 14}, expectedComplete: true},
+		{name: "last event just evicted", buffered: buffered, evictedID: 10, lastEventID: 10, channels: []string{"dashboard"}, expectedIDs: []uint64{11}, expectedComplete: true},
+		{name: "up to date", buffered: buffered, evictedID: 10, lastEventID: 14, channels: []string{"dashboard"}, expectedIDs: []uint64{}, expectedComplete: true},
+		{name: "evicted events", buffered: buffered, evictedID: 10, lastEventID: 5, channels: []string{"dashboard"}, expectedIDs: []uint64{}, expectedComplete: false},
+		{name: "unknown future id", buffered: buffered, evictedID: 10, lastEventID: 20, channels: []string{"dashboard"}, expectedIDs: []uint64{}, expectedComplete: false},
+		{name: "id before first event", buffered: buffered, evictedID: 0, lastEventID: 10, channels: []string{"dashboard"}, expectedIDs: []uint64{}, expectedComplete: false},
+		{name: "empty buffer", buffered: []model.RealtimeEvent{}, evictedID: 0, lastEventID: 10, channels: []string{"dashboard"}, expectedIDs: []uint64{}, expectedComplete: false},
+		{name: "zero id", buffered: buffered, evictedID: 0, lastEventID: 0, channels: []string{"dashboard"}, expectedIDs: []uint64{}, expectedComplete: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replay, complete := ReplayRealtimeEvents(tt.buffered, tt.evictedID, tt.lastEventID, tt.channels)
+			if complete != tt.expectedComplete {
+				t.Errorf("ReplayRealtimeEvents() complete = %v, want %v", complete, tt.expectedComplete)
+			}
+			if len(replay) != len(tt.expectedIDs) {
+				t.Fatalf("ReplayRealtimeEvents() returned %d events, want %d", len(replay), len(tt.expectedIDs))
+			}
+			for i, event := range replay {
+				if event.ID != tt.expectedIDs[i] {
+					t.Errorf("ReplayRealtimeEvents()[%d].ID = %d, want %d", i, event.ID, tt.expectedIDs[i])
+				}
+			}
+		})
+	}
+}
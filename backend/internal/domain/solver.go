@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"math/big"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+)
+
+// The solvers below invert ContractHealthFactor for an 18 decimal target health factor. Every step of
+// the engine's math floors, so each bound is derived as the exact integer threshold rather than by
+// dividing the formula through: the amounts they return are the largest (or smallest) for which the
+// engine's own health factor is still at least the target.
+
+// SolveMaxMint returns the largest amount of AUSD the position can mint while keeping its health
+// factor at or above target. Targets below MIN_HEALTH_FACTOR are raised to it, since mintAUSD would
+// revert there. It reports false, with zero, when the position is already below the target.
+func SolveMaxMint(position Position, target *big.Int) (*big.Int, bool) {
+	target = atLeastMinHealthFactor(target)
+
+	maxDebt := maxDebtFor(position.CollateralValueUSD(), target)
+	if maxDebt.Cmp(position.Debt) < 0 {
+		return big.NewInt(0), false
+	}
+	return maxDebt.Sub(maxDebt, position.Debt), true
+}
+
+// SolveMaxRedeem returns the largest amount of token the position can redeem while keeping its health
+// factor at or above target, raised to MIN_HEALTH_FACTOR like SolveMaxMint. It reports false, with
+// zero, when the position is already below the target.
+func SolveMaxRedeem(position Position, token string, target *big.Int) (*big.Int, bool) {
+	target = atLeastMinHealthFactor(target)
+
+	collateral, ok := position.collateral(token)
+	if !ok || collateral.Amount.Sign() <= 0 {
+		return big.NewInt(0), !belowTarget(position.HealthFactor(), target)
+	}
+	if position.Debt == nil || position.Debt.Sign() == 0 {
+		return new(big.Int).Set(collateral.Amount), true
+	}
+
+	tokenValue := ContractUSDValue(collateral.Amount, collateral.Price)
+	otherValue := new(big.Int).Sub(position.CollateralValueUSD(), tokenValue)
+	neededValue := new(big.Int).Sub(minCollateralFor(position.Debt, target), otherValue)
+	if neededValue.Sign() <= 0 {
+		return new(big.Int).Set(collateral.Amount), true
+	}
+
+	keep := minTokenAmountFor(neededValue, collateral.Price)
+	if keep.Cmp(collateral.Amount) > 0 {
+		return big.NewInt(0), false
+	}
+	return keep.Sub(collateral.Amount, keep), true
+}
+
+// SolveRequiredDeposit returns the smallest amount of token, priced with the 8 decimal price, that
+// brings the position's health factor to at least target. It is zero when the position already
+// meets the target, including when it has no debt.
+func SolveRequiredDeposit(position Position, token string, price *big.Int, target *big.Int) *big.Int {
+	if position.Debt == nil || position.Debt.Sign() == 0 || !belowTarget(position.HealthFactor(), target) {
+		return big.NewInt(0)
+	}
+
+	held := big.NewInt(0)
+	if collateral, ok := position.collateral(token); ok {
+		held = collateral.Amount
+		price = collateral.Price
+	}
+
+	otherValue := new(big.Int).Sub(position.CollateralValueUSD(), ContractUSDValue(held, price))
+	neededValue := new(big.Int).Sub(minCollateralFor(position.Debt, target), otherValue)
+
+	deposit := minTokenAmountFor(neededValue, price)
+	deposit.Sub(deposit, held)
+	if deposit.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return deposit
+}
+
+func belowTarget(healthFactor, target *big.Int) bool {
+	return healthFactor.Cmp(target) < 0
+}
+
+func atLeastMinHealthFactor(target *big.Int) *big.Int {
+	if target.Cmp(constants.MIN_HEALTH_FACTOR) < 0 {
+		return constants.MIN_HEALTH_FACTOR
+	}
+	return target
+}
+
+// maxDebtFor is the largest debt with ContractHealthFactor(collateralValueUSD, debt) >= target:
+// floor(adjusted * PRECISION / debt) >= target holds exactly when debt <= adjusted * PRECISION / target.
+func maxDebtFor(collateralValueUSD, target *big.Int) *big.Int {
+	adjusted := new(big.Int).Mul(collateralValueUSD, constants.LIQUIDATION_THRESHOLD)
+	adjusted.Div(adjusted, constants.LIQUIDATION_PRECISION)
+	adjusted.Mul(adjusted, constants.PRECISION)
+	return adjusted.Div(adjusted, target)
+}
+
+// minCollateralFor is the smallest collateral value with ContractHealthFactor(value, debt) >= target.
+// The adjusted collateral must reach ceil(target * debt / PRECISION), which
+// floor(value * LIQUIDATION_THRESHOLD / LIQUIDATION_PRECISION) does exactly when
+// value >= ceil(adjusted * LIQUIDATION_PRECISION / LIQUIDATION_THRESHOLD).
+func minCollateralFor(debt, target *big.Int) *big.Int {
+	adjusted := ceilDiv(new(big.Int).Mul(target, debt), constants.PRECISION)
+	return ceilDiv(adjusted.Mul(adjusted, constants.LIQUIDATION_PRECISION), constants.LIQUIDATION_THRESHOLD)
+}
+
+// minTokenAmountFor is the smallest amount whose ContractUSDValue at price is at least value.
+func minTokenAmountFor(value, price *big.Int) *big.Int {
+	if value.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	priceAdjusted := new(big.Int).Mul(price, constants.ADDITIONAL_PRICE_PRECISION)
+	return ceilDiv(new(big.Int).Mul(value, constants.PRECISION), priceAdjusted)
+}
+
+func ceilDiv(numerator, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).DivMod(numerator, denominator, new(big.Int))
+	if remainder.Sign() > 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return quotient
+}
@@ -0,0 +1,261 @@
+package domain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+)
+
+func healthFactorTarget(t *testing.T, value string) *big.Int {
+	t.Helper()
+	target, ok := ParseDecimalToScaledInt(value, constants.PRECISION)
+	if !ok {
+		t.Fatalf("invalid target %q", value)
+	}
+	return target
+}
+
+func TestSolveMaxMint(t *testing.T) {
+	tests := []struct {
+		name              string
+		position          Position
+		target            string
+		expected          *big.Int
+		expectedReachable bool
+	}{
+		{
+			name:              "no debt at the minimum health factor",
+			position:          Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")}, Debt: big.NewInt(0)},
+			target:            "1",
+			expected:          usd(1000),
+			expectedReachable: true,
+		},
+		{
+			name:              "existing debt with a buffer",
+			position:          Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")}, Debt: usd(300)},
+			target:            "1.25",
+			expected:          usd(500),
+			expectedReachable: true,
+		},
+		{
+			name:              "targets below the minimum are raised to it",
+			position:          Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")}, Debt: big.NewInt(0)},
+			target:            "0.5",
+			expected:          usd(1000),
+			expectedReachable: true,
+		},
+		{
+			name:              "already below the target",
+			position:          Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")}, Debt: usd(900)},
+			target:            "1.5",
+			expected:          big.NewInt(0),
+			expectedReachable: false,
+		},
+		{
+			name:              "odd prices keep the contract's rounding",
+			position:          Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", mustBigInt(t, "1234567890123456789"), "1999.12345678")}, Debt: mustBigInt(t, "7")},
+			target:            "1.3",
+			expected:          nil,
+			expectedReachable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := healthFactorTarget(t, tt.target)
+			result, reachable := SolveMaxMint(tt.position, target)
+			if reachable != tt.expectedReachable {
+				t.Errorf("reachable = %v, want %v", reachable, tt.expectedReachable)
+			}
+			if tt.expected != nil && result.Cmp(tt.expected) != 0 {
+				t.Errorf("SolveMaxMint() = %s, want %s", result, tt.expected)
+			}
+			if !reachable {
+				return
+			}
+
+			target = atLeastMinHealthFactor(target)
+			collateralValueUSD := tt.position.CollateralValueUSD()
+			debt := new(big.Int).Add(tt.position.Debt, result)
+			if ContractHealthFactor(collateralValueUSD, debt).Cmp(target) < 0 {
+				t.Errorf("minting %s leaves the health factor below the target", result)
+			}
+			debt.Add(debt, big.NewInt(1))
+			if ContractHealthFactor(collateralValueUSD, debt).Cmp(target) >= 0 {
+				t.Errorf("minting %s + 1 still meets the target", result)
+			}
+		})
+	}
+}
+
+func TestSolveMaxRedeem(t *testing.T) {
+	tests := []struct {
+		name              string
+		position          Position
+		token             string
+		target            string
+		expected          *big.Int
+		expectedReachable bool
+	}{
+		{
+			name:              "no debt redeems everything",
+			position:          Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(2), "2000")}, Debt: big.NewInt(0)},
+			token:             "ETH",
+			target:            "1.5",
+			expected:          usd(2),
+			expectedReachable: true,
+		},
+		{
+			name:              "redeem down to the target",
+			position:          Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(2), "2000")}, Debt: usd(1000)},
+			token:             "ETH",
+			target:            "1.5",
+			expected:          mustBigInt(t, "500000000000000000"),
+			expectedReachable: true,
+		},
+		{
+			name: "other collateral covers the target",
+			position: Position{Collateral: []CollateralPosition{
+				mustCollateral(t, "ETH", usd(1), "2000"),
+				mustCollateral(t, "BTC", usd(1), "60000"),
+			}, Debt: usd(1000)},
+			token:             "ETH",
+			target:            "1.5",
+			expected:          usd(1),
+			expectedReachable: true,
+		},
+		{
+			name:              "already below the target",
+			position:          Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")}, Debt: usd(900)},
+			token:             "ETH",
+			target:            "1.5",
+			expected:          big.NewInt(0),
+			expectedReachable: false,
+		},
+		{
+			name:              "token not held",
+			position:          Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")}, Debt: usd(100)},
+			token:             "BTC",
+			target:            "1.5",
+			expected:          big.NewInt(0),
+			expectedReachable: true,
+		},
+		{
+			name:              "odd prices keep the contract's rounding",
+			position:          Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", mustBigInt(t, "3333333333333333333"), "1999.12345678")}, Debt: mustBigInt(t, "1234567890123456789012")},
+			token:             "ETH",
+			target:            "1.5",
+			expected:          nil,
+			expectedReachable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := healthFactorTarget(t, tt.target)
+			result, reachable := SolveMaxRedeem(tt.position, tt.token, target)
+			if reachable != tt.expectedReachable {
+				t.Errorf("reachable = %v, want %v", reachable, tt.expectedReachable)
+			}
+			if tt.expected != nil && result.Cmp(tt.expected) != 0 {
+				t.Errorf("SolveMaxRedeem() = %s, want %s", result, tt.expected)
+			}
+
+			collateral, held := tt.position.collateral(tt.token)
+			if !reachable || !held || result.Cmp(collateral.Amount) == 0 {
+				return
+			}
+
+			after := tt.position.withCollateral(tt.token, new(big.Int).Neg(result), collateral.Price)
+			if after.HealthFactor().Cmp(target) < 0 {
+				t.Errorf("redeeming %s leaves the health factor below the target", result)
+			}
+			after = after.withCollateral(tt.token, big.NewInt(-1), collateral.Price)
+			if after.HealthFactor().Cmp(target) >= 0 {
+				t.Errorf("redeeming %s + 1 still meets the target", result)
+			}
+		})
+	}
+}
+
+func TestSolveRequiredDeposit(t *testing.T) {
+	btcPrice, _ := ParseDecimalToScaledInt("60000", constants.PRICE_PRECISION)
+	oddPrice, _ := ParseDecimalToScaledInt("59999.87654321", constants.PRICE_PRECISION)
+
+	tests := []struct {
+		name     string
+		position Position
+		token    string
+		price    *big.Int
+		target   string
+		expected *big.Int
+	}{
+		{
+			name:     "no debt needs no deposit",
+			position: Position{Debt: big.NewInt(0)},
+			token:    "BTC",
+			price:    btcPrice,
+			target:   "2",
+			expected: big.NewInt(0),
+		},
+		{
+			name:     "already above the target",
+			position: Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(10), "2000")}, Debt: usd(1000)},
+			token:    "BTC",
+			price:    btcPrice,
+			target:   "2",
+			expected: big.NewInt(0),
+		},
+		{
+			name:     "new token",
+			position: Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")}, Debt: usd(1000)},
+			token:    "BTC",
+			price:    btcPrice,
+			target:   "2",
+			expected: mustBigInt(t, "33333333333333334"),
+		},
+		{
+			name:     "token already held",
+			position: Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")}, Debt: usd(1500)},
+			token:    "ETH",
+			price:    nil,
+			target:   "2",
+			expected: usd(2),
+		},
+		{
+			name:     "odd prices keep the contract's rounding",
+			position: Position{Collateral: []CollateralPosition{mustCollateral(t, "ETH", mustBigInt(t, "3333333333333333333"), "1999.12345678")}, Debt: mustBigInt(t, "9876543210987654321098")},
+			token:    "BTC",
+			price:    oddPrice,
+			target:   "2",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := healthFactorTarget(t, tt.target)
+			result := SolveRequiredDeposit(tt.position, tt.token, tt.price, target)
+			if tt.expected != nil && result.Cmp(tt.expected) != 0 {
+				t.Errorf("SolveRequiredDeposit() = %s, want %s", result, tt.expected)
+			}
+			if result.Sign() == 0 {
+				return
+			}
+
+			price := tt.price
+			if collateral, ok := tt.position.collateral(tt.token); ok {
+				price = collateral.Price
+			}
+			after := tt.position.withCollateral(tt.token, result, price)
+			if after.HealthFactor().Cmp(target) < 0 {
+				t.Errorf("depositing %s leaves the health factor below the target", result)
+			}
+			after = after.withCollateral(tt.token, big.NewInt(-1), price)
+			if after.HealthFactor().Cmp(target) >= 0 {
+				t.Errorf("depositing %s - 1 already meets the target", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+)
+
+type StressedPosition struct {
+	Address            string
+	HealthFactorBefore *big.Int
+	HealthFactorAfter  *big.Int
+	CollateralUSDAfter *big.Int
+	Debt               *big.Int
+}
+
+type StressTestOutcome struct {
+	UsersEvaluated               int
+	NewlyLiquidatable            []StressedPosition
+	LiquidatableUsers            int
+	DebtAtRisk                   *big.Int
+	BadDebt                      *big.Int
+	TotalDebt                    *big.Int
+	TotalCollateralUSDBefore     *big.Int
+	TotalCollateralUSDAfter      *big.Int
+	CollateralizationRatioBefore float64
+	CollateralizationRatioAfter  float64
+}
+
+// ApplyPriceShock moves an 8 decimal price by the given change in basis points, floored at zero.
+func ApplyPriceShock(price *big.Int, changeBasisPoints int64) *big.Int {
+	factor := new(big.Int).Add(constants.PERCENTAGE_MULTIPLIER, big.NewInt(changeBasisPoints))
+	if factor.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+
+	shocked := new(big.Int).Mul(price, factor)
+	return shocked.Div(shocked, constants.PERCENTAGE_MULTIPLIER)
+}
+
+// RepricePosition returns a copy of position valued at prices. Tokens missing from prices keep their price.
+func RepricePosition(position Position, prices map[string]*big.Int) Position {
+	repriced := Position{
+		Collateral: make([]CollateralPosition, 0, len(position.Collateral)),
+		Debt:       position.Debt,
+	}
+
+	for _, collateral := range position.Collateral {
+		price := collateral.Price
+		if shocked, ok := prices[collateral.Token]; ok {
+			price = shocked
+		}
+		repriced.Collateral = append(repriced.Collateral, CollateralPosition{
+			Token:  collateral.Token,
+			Amount: collateral.Amount,
+			Price:  price,
+		})
+	}
+
+	return repriced
+}
+
+// StressTest values every position at the shocked prices. Debt at risk is the debt of every position
+// liquidatable after the shock, and bad debt is the debt not covered by collateral at all.
+func StressTest(positions map[string]Position, shockedPrices map[string]*big.Int) StressTestOutcome {
+	outcome := StressTestOutcome{
+		UsersEvaluated:           len(positions),
+		NewlyLiquidatable:        []StressedPosition{},
+		DebtAtRisk:               big.NewInt(0),
+		BadDebt:                  big.NewInt(0),
+		TotalDebt:                big.NewInt(0),
+		TotalCollateralUSDBefore: big.NewInt(0),
+		TotalCollateralUSDAfter:  big.NewInt(0),
+	}
+
+	for address, position := range positions {
+		debt := position.Debt
+		if debt == nil {
+			debt = big.NewInt(0)
+		}
+
+		collateralBefore := position.CollateralValueUSD()
+		shocked := RepricePosition(position, shockedPrices)
+		collateralAfter := shocked.CollateralValueUSD()
+
+		outcome.TotalDebt.Add(outcome.TotalDebt, debt)
+		outcome.TotalCollateralUSDBefore.Add(outcome.TotalCollateralUSDBefore, collateralBefore)
+		outcome.TotalCollateralUSDAfter.Add(outcome.TotalCollateralUSDAfter, collateralAfter)
+
+		if debt.Sign() == 0 {
+			continue
+		}
+
+		healthFactorBefore := ContractHealthFactor(collateralBefore, debt)
+		healthFactorAfter := ContractHealthFactor(collateralAfter, debt)
+
+		if !IsLiquidatable(healthFactorAfter) {
+			continue
+		}
+
+		outcome.LiquidatableUsers++
+		outcome.DebtAtRisk.Add(outcome.DebtAtRisk, debt)
+
+		if collateralAfter.Cmp(debt) < 0 {
+			outcome.BadDebt.Add(outcome.BadDebt, new(big.Int).Sub(debt, collateralAfter))
+		}
+
+		if !IsLiquidatable(healthFactorBefore) {
+			outcome.NewlyLiquidatable = append(outcome.NewlyLiquidatable, StressedPosition{
+				Address:            address,
+				HealthFactorBefore: healthFactorBefore,
+				HealthFactorAfter:  healthFactorAfter,
+				CollateralUSDAfter: collateralAfter,
+				Debt:               debt,
+			})
+		}
+	}
+
+	sort.Slice(outcome.NewlyLiquidatable, func(i, j int) bool {
+		a, b := outcome.NewlyLiquidatable[i], outcome.NewlyLiquidatable[j]
+		if cmp := a.HealthFactorAfter.Cmp(b.HealthFactorAfter); cmp != 0 {
+			return cmp < 0
+		}
+		return a.Address < b.Address
+	})
+
+	outcome.CollateralizationRatioBefore = CollateralizationRatio(outcome.TotalCollateralUSDBefore, outcome.TotalDebt)
+	outcome.CollateralizationRatioAfter = CollateralizationRatio(outcome.TotalCollateralUSDAfter, outcome.TotalDebt)
+
+	return outcome
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestApplyPriceShock(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    int64
+		change   int64
+		expected int64
+	}{
+		{name: "30% drop", price: 200000000000, change: -3000, expected: 140000000000},
+		{name: "10% rise", price: 200000000000, change: 1000, expected: 220000000000},
+		{name: "no change", price: 200000000000, change: 0, expected: 200000000000},
+		{name: "drop beyond 100% floors at zero", price: 200000000000, change: -12000, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ApplyPriceShock(big.NewInt(tt.price), tt.change)
+			if result.Cmp(big.NewInt(tt.expected)) != 0 {
+				t.Errorf("ApplyPriceShock() = %s, want %d", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStressTest(t *testing.T) {
+	positions := map[string]Position{
+		"0xsafe": {
+			Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(10), "2000")},
+			Debt:       usd(1000),
+		},
+		"0xnewly": {
+			Collateral: []CollateralPosition{
+				mustCollateral(t, "ETH", usd(1), "2000"),
+				mustCollateral(t, "BTC", new(big.Int).Div(usd(1), big.NewInt(100)), "40000"),
+			},
+			Debt: usd(1000),
+		},
+		"0xalready": {
+			Collateral: []CollateralPosition{mustCollateral(t, "ETH", usd(1), "2000")},
+			Debt:       usd(1500),
+		},
+		"0xnodebt": {
+			Collateral: []CollateralPosition{mustCollateral(t, "BTC", usd(1), "40000")},
+			Debt:       big.NewInt(0),
+		},
+	}
+
+	eth, _ := ParseDecimalToScaledInt("2000", big.NewInt(1e8))
+	btc, _ := ParseDecimalToScaledInt("40000", big.NewInt(1e8))
+	shocked := map[string]*big.Int{
+		"ETH": ApplyPriceShock(eth, -3000),
+		"BTC": ApplyPriceShock(btc, -2000),
+	}
+
+	outcome := StressTest(positions, shocked)
+
+	if outcome.UsersEvaluated != 4 {
+		t.Errorf("UsersEvaluated = %d, want 4", outcome.UsersEvaluated)
+	}
+	if outcome.LiquidatableUsers != 2 {
+		t.Errorf("LiquidatableUsers = %d, want 2", outcome.LiquidatableUsers)
+	}
+	if len(outcome.NewlyLiquidatable) != 1 || outcome.NewlyLiquidatable[0].Address != "0xnewly" {
+		t.Fatalf("NewlyLiquidatable = %+v, want only 0xnewly", outcome.NewlyLiquidatable)
+	}
+	if outcome.NewlyLiquidatable[0].CollateralUSDAfter.Cmp(usd(1720)) != 0 {
+		t.Errorf("CollateralUSDAfter = %s, want %s", outcome.NewlyLiquidatable[0].CollateralUSDAfter, usd(1720))
+	}
+	if outcome.DebtAtRisk.Cmp(usd(2500)) != 0 {
+		t.Errorf("DebtAtRisk = %s, want %s", outcome.DebtAtRisk, usd(2500))
+	}
+	if outcome.BadDebt.Cmp(usd(100)) != 0 {
+		t.Errorf("BadDebt = %s, want %s", outcome.BadDebt, usd(100))
+	}
+	if outcome.TotalCollateralUSDAfter.Cmp(usd(49120)) != 0 {
+		t.Errorf("TotalCollateralUSDAfter = %s, want %s", outcome.TotalCollateralUSDAfter, usd(49120))
+	}
+	if outcome.CollateralizationRatioAfter != 1403.42 {
+		t.Errorf("CollateralizationRatioAfter = %v, want 1403.42", outcome.CollateralizationRatioAfter)
+	}
+}
+
+func TestStressTestLeavesPositionsUntouched(t *testing.T) {
+	collateral := mustCollateral(t, "ETH", usd(1), "2000")
+	positions := map[string]Position{
+		"0x1": {Collateral: []CollateralPosition{collateral}, Debt: usd(500)},
+	}
+
+	StressTest(positions, map[string]*big.Int{"ETH": big.NewInt(1)})
+
+	if positions["0x1"].Collateral[0].Price.Cmp(collateral.Price) != 0 {
+		t.Errorf("StressTest mutated the position price to %s", positions["0x1"].Collateral[0].Price)
+	}
+}
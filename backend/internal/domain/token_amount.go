@@ -3,11 +3,14 @@ package domain
 import (
 	"fmt"
 	"math/big"
+	"regexp"
 	"strings"
 
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
 )
 
+var unitsPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
 func GetTokenAmountInUSD(
 	amountInWei *big.Int,
 	tokenPriceUSD string,
@@ -45,3 +48,42 @@ func ParseDecimalToScaledInt(value string, scale *big.Int) (*big.Int, bool) {
 	full := intPart + fracPart
 	return new(big.Int).SetString(full, 10)
 }
+
+// FormatUnits renders a fixed-point amount as a decimal string without trailing zeros,
+// for example 1500000000000000000 with 18 decimals as "1.5".
+func FormatUnits(amount *big.Int, decimals int) string {
+	sign := ""
+	digits := amount.String()
+	if amount.Sign() < 0 {
+		sign = "-"
+		digits = digits[1:]
+	}
+
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	intPart := digits[:len(digits)-decimals]
+	fracPart := strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fracPart == "" {
+		return sign + intPart
+	}
+	return sign + intPart + "." + fracPart
+}
+
+// ParseUnits is the inverse of FormatUnits: it scales a decimal string such as "1.5" to an integer
+// amount with the given decimals. Unlike ParseDecimalToScaledInt it rejects values with more
+// fractional digits than decimals instead of truncating them.
+func ParseUnits(value string, decimals int) (*big.Int, error) {
+	if !unitsPattern.MatchString(value) {
+		return nil, fmt.Errorf("%q is not a decimal number", value)
+	}
+
+	intPart, fracPart, _ := strings.Cut(value, ".")
+	if len(fracPart) > decimals {
+		return nil, fmt.Errorf("%q has more than %d decimals", value, decimals)
+	}
+
+	amount, _ := new(big.Int).SetString(intPart+fracPart+strings.Repeat("0", decimals-len(fracPart)), 10)
+	return amount, nil
+}
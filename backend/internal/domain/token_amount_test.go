@@ -300,3 +300,67 @@ func TestGetTokenAmountInUSDEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		decimals int
+		want     string
+	}{
+		{"whole token", "1000000000000000000", 18, "1"},
+		{"fraction", "1500000000000000000", 18, "1.5"},
+		{"below one", "1", 18, "0.000000000000000001"},
+		{"zero", "0", 18, "0"},
+		{"negative", "-250000000", 8, "-2.5"},
+		{"no decimals", "42", 0, "42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, _ := new(big.Int).SetString(tt.amount, 10)
+			if got := FormatUnits(amount, tt.decimals); got != tt.want {
+				t.Errorf("FormatUnits(%s, %d) = %q, want %q", tt.amount, tt.decimals, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		decimals    int
+		want        string
+		shouldError bool
+	}{
+		{name: "whole token", value: "1", decimals: 18, want: "1000000000000000000"},
+		{name: "fraction", value: "1.5", decimals: 18, want: "1500000000000000000"},
+		{name: "smallest unit", value: "0.000000000000000001", decimals: 18, want: "1"},
+		{name: "negative", value: "-2.5", decimals: 8, want: "-250000000"},
+		{name: "trailing zeros", value: "2.50", decimals: 2, want: "250"},
+		{name: "too many decimals", value: "0.0000000000000000001", decimals: 18, shouldError: true},
+		{name: "missing integer part", value: ".5", decimals: 18, shouldError: true},
+		{name: "missing fraction", value: "1.", decimals: 18, shouldError: true},
+		{name: "exponent", value: "1e18", decimals: 18, shouldError: true},
+		{name: "empty", value: "", decimals: 18, shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUnits(tt.value, tt.decimals)
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("ParseUnits(%q, %d) = %s, want an error", tt.value, tt.decimals, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseUnits(%q, %d) returned error: %v", tt.value, tt.decimals, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseUnits(%q, %d) = %s, want %s", tt.value, tt.decimals, got, tt.want)
+			}
+		})
+	}
+}
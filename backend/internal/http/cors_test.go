@@ -0,0 +1,22 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/middlewares"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedOrigins(t *testing.T) {
+	allowed := middlewares.ParseAllowedOrigins(" https://app.example.com/ ,http://localhost:3001")
+
+	assert.True(t, allowed.Allows("https://app.example.com"))
+	assert.True(t, allowed.Allows("HTTPS://APP.EXAMPLE.COM"))
+	assert.True(t, allowed.Allows("http://localhost:3001"))
+	assert.False(t, allowed.Allows("https://evil.example"))
+	assert.False(t, allowed.Allows("http://localhost:3002"))
+	assert.False(t, allowed.Allows(""))
+
+	assert.False(t, middlewares.ParseAllowedOrigins("").Allows("https://app.example.com"))
+	assert.True(t, middlewares.ParseAllowedOrigins("*").Allows("https://any.example"))
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
 )
 
 type IPriceFeedAPI interface {
@@ -27,6 +29,15 @@ type PriceFeedAPI struct {
     openUntil        time.Time
 
     client *http.Client
+
+	maxPriceAge time.Duration
+	lastPrices  map[string]lastPrice
+}
+
+// lastPrice is the most recent price a feed returned, kept to ride out short outages.
+type lastPrice struct {
+	value     string
+	fetchedAt time.Time
 }
 
 type PriceResult struct {
@@ -60,12 +71,21 @@ func NewPriceFeedAPI() *PriceFeedAPI {
         }
     }
 
+	maxAgeSec := 300
+	if v := os.Getenv("PRICE_FEED_MAX_AGE_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxAgeSec = n
+		}
+	}
+
     return &PriceFeedAPI{
         baseUrl:          base,
         fallbackBaseUrl:  fallback,
         failureThreshold: threshold,
         cooldown:         time.Duration(coolSec) * time.Second,
         client:           &http.Client{Timeout: 10 * time.Second},
+		maxPriceAge:      time.Duration(maxAgeSec) * time.Second,
+		lastPrices:       map[string]lastPrice{},
     }
 }
 
@@ -153,10 +173,34 @@ func (pfa *PriceFeedAPI) callWithCircuit(path string) (string, error) {
     return "", err
 }
 
+// price returns the live price for path. When every source fails it serves the last price seen while
+// it is younger than maxPriceAge, and reports model.ErrStalePrice after that. Without any price seen
+// the failure is model.ErrPriceUnavailable.
+func (pfa *PriceFeedAPI) price(path string) (string, error) {
+	val, err := pfa.callWithCircuit(path)
+
+	pfa.mu.Lock()
+	defer pfa.mu.Unlock()
+
+	if err == nil {
+		pfa.lastPrices[path] = lastPrice{value: val, fetchedAt: time.Now()}
+		return val, nil
+	}
+
+	last, ok := pfa.lastPrices[path]
+	if !ok {
+		return "", fmt.Errorf("%w: %v", model.ErrPriceUnavailable, err)
+	}
+	if age := time.Since(last.fetchedAt); age > pfa.maxPriceAge {
+		return "", fmt.Errorf("%w: last %s price is %s old: %v", model.ErrStalePrice, strings.TrimPrefix(path, "/"), age.Round(time.Second), err)
+	}
+	return last.value, nil
+}
+
 func (pfa *PriceFeedAPI) GetEthUsdPrice() (string, error) {
-    return pfa.callWithCircuit("/ETH-USD")
+    return pfa.price("/ETH-USD")
 }
 
 func (pfa *PriceFeedAPI) GetBtcUsdPrice() (string, error) {
-    return pfa.callWithCircuit("/BTC-USD")
+    return pfa.price("/BTC-USD")
 }
\ No newline at end of file
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// parseAddressParam returns the address path parameter in the checksummed form used for cache keys
+// and stored rows, so lowercase input finds the same position.
+func parseAddressParam(ctx *gin.Context, param string) (string, error) {
+	return model.ParseAddress(ctx.Param(param))
+}
+
+// normalizeAddressField validates an address from a request body or query and rewrites it in place in
+// checksummed form. field names the value in the error.
+func normalizeAddressField(field string, value *string) error {
+	address, err := model.ParseAddress(*value)
+	if err != nil {
+		return fmt.Errorf("'%s': %w", field, err)
+	}
+	*value = address
+	return nil
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAlertDeliveriesLimit = 50
+	maxAlertDeliveriesLimit     = 500
+
+	AlertOwnerSignatureHeader = "X-AnchorUSD-Owner-Signature"
+	AlertOwnerTimestampHeader = "X-AnchorUSD-Owner-Timestamp"
+	alertOwnerSignatureMaxAge = 5 * time.Minute
+)
+
+type AlertsManager interface {
+	CreateSubscription(ctx context.Context, request model.CreateAlertSubscriptionRequest) (model.CreatedAlertSubscription, error)
+	GetSubscriptions(ctx context.Context, address string) ([]model.AlertSubscription, error)
+	DeleteSubscription(ctx context.Context, address string, id uint) error
+	GetDeliveries(ctx context.Context, address string, id uint, limit int) ([]model.AlertDelivery, error)
+}
+
+func CreateAlertSubscriptionHandler(svc AlertsManager) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		var req model.CreateAlertSubscriptionRequest
+
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			logger.Warn().Err(err).Msg("Invalid request body for alert subscription")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		if err := validateAlertSubscriptionRequest(&req); err != nil {
+			logger.Warn().Err(err).Msg("Invalid alert subscription")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		if err := requireAlertOwner(ctx, req.Address); err != nil {
+			logger.Warn().Err(err).Str("address", req.Address).Msg("Alert subscription not signed by its owner")
+			respondError(ctx, err)
+			return
+		}
+
+		subscription, err := svc.CreateSubscription(ctx.Request.Context(), req)
+		if err != nil {
+			logger.Error().Err(err).Str("address", req.Address).Msg("Failed to create alert subscription")
+			respondError(ctx, err)
+			return
+		}
+
+		logger.Info().Uint("subscription_id", subscription.ID).Str("address", req.Address).Msg("Alert subscription created successfully")
+		ctx.JSON(201, subscription)
+	}
+}
+
+func GetAlertSubscriptionsHandler(svc AlertsManager) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		user, err := parseAddressParam(ctx, "user")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		if err := requireAlertOwner(ctx, user); err != nil {
+			logger.Warn().Err(err).Str("user", user).Msg("Alert request not signed by its owner")
+			respondError(ctx, err)
+			return
+		}
+
+		subscriptions, err := svc.GetSubscriptions(ctx.Request.Context(), user)
+		if err != nil {
+			logger.Error().Err(err).Str("user", user).Msg("Failed to get alert subscriptions")
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, subscriptions)
+	}
+}
+
+func DeleteAlertSubscriptionHandler(svc AlertsManager) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		user, err := parseAddressParam(ctx, "user")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		if err := requireAlertOwner(ctx, user); err != nil {
+			logger.Warn().Err(err).Str("user", user).Msg("Alert request not signed by its owner")
+			respondError(ctx, err)
+			return
+		}
+
+		id, err := parseAlertSubscriptionID(ctx)
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		err = svc.DeleteSubscription(ctx.Request.Context(), user, id)
+		if errors.Is(err, model.ErrAlertSubscriptionNotFound) {
+			respondError(ctx, err)
+			return
+		}
+		if err != nil {
+			logger.Error().Err(err).Str("user", user).Uint("subscription_id", id).Msg("Failed to delete alert subscription")
+			respondError(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", user).Uint("subscription_id", id).Msg("Alert subscription deleted successfully")
+		ctx.Status(204)
+	}
+}
+
+func GetAlertDeliveriesHandler(svc AlertsManager) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		user, err := parseAddressParam(ctx, "user")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		if err := requireAlertOwner(ctx, user); err != nil {
+			logger.Warn().Err(err).Str("user", user).Msg("Alert request not signed by its owner")
+			respondError(ctx, err)
+			return
+		}
+
+		id, err := parseAlertSubscriptionID(ctx)
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		limit := defaultAlertDeliveriesLimit
+		if value := ctx.Query("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 || parsed > maxAlertDeliveriesLimit {
+				respondErrorCode(ctx, 400, model.ErrorCodeInvalidRequest, fmt.Sprintf("invalid 'limit': expected a number between 1 and %d", maxAlertDeliveriesLimit))
+				return
+			}
+			limit = parsed
+		}
+
+		deliveries, err := svc.GetDeliveries(ctx.Request.Context(), user, id, limit)
+		if errors.Is(err, model.ErrAlertSubscriptionNotFound) {
+			respondError(ctx, err)
+			return
+		}
+		if err != nil {
+			logger.Error().Err(err).Str("user", user).Uint("subscription_id", id).Msg("Failed to get alert deliveries")
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, deliveries)
+	}
+}
+
+// requireAlertOwner checks that the request is signed by address, the owner of the subscriptions it
+// creates, reads or deletes. The signature covers domain.AlertOwnerMessage and is only accepted within
+// alertOwnerSignatureMaxAge of its timestamp.
+func requireAlertOwner(ctx *gin.Context, address string) error {
+	timestamp, err := strconv.ParseInt(ctx.GetHeader(AlertOwnerTimestampHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing or invalid %s header", model.ErrUnauthorized, AlertOwnerTimestampHeader)
+	}
+
+	age := time.Since(time.Unix(timestamp, 0))
+	if age > alertOwnerSignatureMaxAge || age < -alertOwnerSignatureMaxAge {
+		return fmt.Errorf("%w: signature timestamp is more than %s from now", model.ErrUnauthorized, alertOwnerSignatureMaxAge)
+	}
+
+	signature, err := hexutil.Decode(ctx.GetHeader(AlertOwnerSignatureHeader))
+	if err != nil {
+		return fmt.Errorf("%w: missing or invalid %s header", model.ErrUnauthorized, AlertOwnerSignatureHeader)
+	}
+
+	signer, err := domain.RecoverMessageSigner(domain.AlertOwnerMessage(address, timestamp), signature)
+	if err != nil || signer != address {
+		return fmt.Errorf("%w: request is not signed by %s", model.ErrUnauthorized, address)
+	}
+	return nil
+}
+
+func parseAlertSubscriptionID(ctx *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid subscription id %q", ctx.Param("id"))
+	}
+	return uint(id), nil
+}
+
+func validateAlertSubscriptionRequest(req *model.CreateAlertSubscriptionRequest) error {
+	if err := normalizeAddressField("address", &req.Address); err != nil {
+		return err
+	}
+
+	webhookURL, err := url.Parse(req.WebhookURL)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+		return fmt.Errorf("'webhookUrl' must be an absolute http or https URL")
+	}
+
+	_, err = domain.ParseAlertThreshold(req.Threshold)
+	return err
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDailyStatsRange = 30 * 24 * time.Hour
+	maxDailyStatsRange     = 366 * 24 * time.Hour
+)
+
+type DailyStatsReader interface {
+	GetDailyStats(ctx context.Context, query model.DailyStatsQuery) (model.DailyStats, error)
+}
+
+func GetDailyStatsHandler(svc DailyStatsReader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		logger.Info().Str("endpoint", "/stats/daily").Msg("Request received for daily stats")
+
+		query, err := parseDailyStatsQuery(ctx)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Invalid daily stats query")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		stats, err := svc.GetDailyStats(ctx.Request.Context(), query)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to get daily stats")
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, stats)
+	}
+}
+
+// parseDailyStatsQuery accepts days as YYYY-MM-DD in addition to the usual RFC3339 and unix seconds.
+func parseDailyStatsQuery(ctx *gin.Context) (model.DailyStatsQuery, error) {
+	to := time.Now()
+	if value := ctx.Query("to"); value != "" {
+		parsed, err := parseDayParam(value)
+		if err != nil {
+			return model.DailyStatsQuery{}, fmt.Errorf("invalid 'to': %w", err)
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultDailyStatsRange)
+	if value := ctx.Query("from"); value != "" {
+		parsed, err := parseDayParam(value)
+		if err != nil {
+			return model.DailyStatsQuery{}, fmt.Errorf("invalid 'from': %w", err)
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return model.DailyStatsQuery{}, fmt.Errorf("'from' must be before 'to'")
+	}
+	if to.Sub(from) > maxDailyStatsRange {
+		return model.DailyStatsQuery{}, fmt.Errorf("range between 'from' and 'to' must not exceed 366 days")
+	}
+
+	return model.DailyStatsQuery{From: from, To: to}, nil
+}
+
+func parseDayParam(value string) (time.Time, error) {
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		return day, nil
+	}
+	return parseTimeParam(value)
+}
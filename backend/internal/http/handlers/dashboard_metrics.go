@@ -20,7 +20,7 @@ func GetDashboardMetricsHandler(svc DashboardMetricsReader) gin.HandlerFunc {
 		metrics, err := svc.GetDashboardMetrics(ctx.Request.Context())
 		if err != nil {
 			logger.Error().Err(err).Msg("Failed to get dashboard metrics")
-			ctx.JSON(500, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
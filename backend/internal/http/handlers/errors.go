@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/middlewares"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type errorMapping struct {
+	target error
+	status int
+	code   model.ErrorCode
+}
+
+// errorMappings assigns the HTTP status and code of every typed error a service can return. The first
+// match wins, so more specific errors come first.
+var errorMappings = []errorMapping{
+	{model.ErrInvalidAddress, 400, model.ErrorCodeInvalidAddress},
+	{model.ErrInvalidAmount, 400, model.ErrorCodeInvalidAmount},
+	{model.ErrInvalidHistoryCursor, 400, model.ErrorCodeInvalidCursor},
+	{model.ErrUnknownToken, 400, model.ErrorCodeUnknownToken},
+	{model.ErrInsufficientCollateral, 400, model.ErrorCodeInsufficientCollateral},
+	{model.ErrWebhookNotAllowed, 400, model.ErrorCodeWebhookNotAllowed},
+	{model.ErrUnauthorized, 401, model.ErrorCodeUnauthorized},
+	{model.ErrUserNotFound, 404, model.ErrorCodeUserNotFound},
+	{model.ErrAlertSubscriptionNotFound, 404, model.ErrorCodeAlertSubscriptionNotFound},
+	{model.ErrStalePrice, 503, model.ErrorCodeStalePrice},
+	{model.ErrPriceUnavailable, 503, model.ErrorCodePriceUnavailable},
+}
+
+// respondError writes err with the status and code of its type. Untyped errors are internal failures:
+// they are logged with the request ID and answered with a generic 500 that does not leak details.
+func respondError(ctx *gin.Context, err error) {
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.target) {
+			respondMappedError(ctx, mapping, err)
+			return
+		}
+	}
+
+	logger := utils.GetLogger()
+	logger.Error().Err(err).Str("request_id", middlewares.RequestID(ctx)).Str("path", ctx.Request.URL.Path).Msg("Internal error")
+	respondErrorCode(ctx, 500, model.ErrorCodeInternal, "internal server error")
+}
+
+// respondInvalidRequest answers a request that failed validation with a 400. Typed errors such as an
+// invalid address keep their own code.
+func respondInvalidRequest(ctx *gin.Context, err error) {
+	for _, mapping := range errorMappings {
+		if mapping.status == 400 && errors.Is(err, mapping.target) {
+			respondMappedError(ctx, mapping, err)
+			return
+		}
+	}
+	respondErrorCode(ctx, 400, model.ErrorCodeInvalidRequest, err.Error())
+}
+
+// respondMappedError writes a typed error, naming the engine's custom error when err is one the
+// contract would revert with.
+func respondMappedError(ctx *gin.Context, mapping errorMapping, err error) {
+	apiError := model.APIError{Code: mapping.code, Message: err.Error()}
+
+	var engineErr *model.EngineError
+	if errors.As(err, &engineErr) {
+		apiError.Revert = engineErr.Revert
+	}
+	respondAPIError(ctx, mapping.status, apiError)
+}
+
+func respondErrorCode(ctx *gin.Context, status int, code model.ErrorCode, message string) {
+	respondAPIError(ctx, status, model.APIError{Code: code, Message: message})
+}
+
+func respondAPIError(ctx *gin.Context, status int, apiError model.APIError) {
+	apiError.RequestID = middlewares.RequestID(ctx)
+	ctx.AbortWithStatusJSON(status, model.ErrorResponse{Error: apiError})
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultEventStreamMaxConnections      = 1000
+	defaultEventStreamMaxConnectionsPerIP = 10
+	eventStreamHeartbeatInterval          = 15 * time.Second
+	eventStreamWriteWait                  = 10 * time.Second
+	eventStreamRetry                      = 3 * time.Second
+)
+
+// EventStreamHandler serves the realtime channels as Server-Sent Events for clients that cannot use
+// WebSockets. Channels are passed as a comma-separated 'channels' query parameter. Clients resume with
+// the Last-Event-ID header, which browsers send automatically on reconnect, or the 'lastEventId' query
+// parameter; a 'reset' event is sent first when the missed events are no longer buffered.
+func EventStreamHandler(hub RealtimeHub) gin.HandlerFunc {
+	limiter := newConnectionLimiter(
+		utils.IntFromEnv("SSE_MAX_CONNECTIONS", defaultEventStreamMaxConnections),
+		utils.IntFromEnv("SSE_MAX_CONNECTIONS_PER_IP", defaultEventStreamMaxConnectionsPerIP),
+	)
+
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		clientIP := ctx.ClientIP()
+
+		channels := parseEventStreamChannels(ctx.QueryArray("channels"))
+		if len(channels) == 0 {
+			respondErrorCode(ctx, 400, model.ErrorCodeInvalidRequest, "missing 'channels' query parameter")
+			return
+		}
+
+		lastEventID, resume, err := parseLastEventID(ctx)
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		if !limiter.acquire(clientIP) {
+			logger.Warn().Str("ip", clientIP).Msg("Event stream connection limit reached")
+			respondErrorCode(ctx, 429, model.ErrorCodeRateLimited, "too many event stream connections")
+			return
+		}
+		defer limiter.release(clientIP)
+
+		subscriber, err := hub.NewSubscriber()
+		if err != nil {
+			logger.Warn().Err(err).Msg("Rejecting event stream connection")
+			respondErrorCode(ctx, 503, model.ErrorCodeServiceUnavailable, err.Error())
+			return
+		}
+		defer subscriber.Close()
+
+		replay, complete := []model.RealtimeEvent{}, true
+		if resume {
+			replay, complete, err = subscriber.Resume(lastEventID, channels...)
+		} else {
+			_, err = subscriber.Subscribe(channels...)
+		}
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		header := ctx.Writer.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no")
+		ctx.Status(200)
+
+		stream := &eventStream{
+			writer:     ctx.Writer,
+			controller: http.NewResponseController(ctx.Writer),
+		}
+
+		if err := stream.writeRetry(); err != nil {
+			return
+		}
+		if !complete {
+			logger.Debug().Uint64("last_event_id", lastEventID).Msg("Event stream resume point no longer buffered")
+			reset := model.RealtimeEvent{
+				Type:      model.RealtimeEventReset,
+				Timestamp: time.Now().UTC().Format(time.RFC3339),
+			}
+			if err := stream.writeEvent(reset); err != nil {
+				return
+			}
+		}
+		for _, event := range replay {
+			if err := stream.writeEvent(event); err != nil {
+				return
+			}
+		}
+
+		logger.Info().Str("ip", clientIP).Strs("channels", channels).Int("replayed", len(replay)).Msg("Event stream client connected")
+
+		ticker := time.NewTicker(eventStreamHeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Request.Context().Done():
+				logger.Info().Str("ip", clientIP).Msg("Event stream client disconnected")
+				return
+
+			case event, ok := <-subscriber.Events():
+				if !ok {
+					logger.Info().Err(subscriber.Err()).Str("ip", clientIP).Msg("Event stream closed by server")
+					return
+				}
+				if err := stream.writeEvent(event); err != nil {
+					return
+				}
+
+			case <-ticker.C:
+				if err := stream.write(": heartbeat\n\n"); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+type eventStream struct {
+	writer     gin.ResponseWriter
+	controller *http.ResponseController
+}
+
+func (s *eventStream) writeRetry() error {
+	return s.write(fmt.Sprintf("retry: %d\n\n", eventStreamRetry.Milliseconds()))
+}
+
+// writeEvent sends the event under its type name. Events without an ID, such as resets, do not move
+// the client's Last-Event-ID.
+func (s *eventStream) writeEvent(event model.RealtimeEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var frame strings.Builder
+	if event.ID != 0 {
+		fmt.Fprintf(&frame, "id: %d\n", event.ID)
+	}
+	fmt.Fprintf(&frame, "event: %s\ndata: %s\n\n", event.Type, data)
+	return s.write(frame.String())
+}
+
+func (s *eventStream) write(frame string) error {
+	s.controller.SetWriteDeadline(time.Now().Add(eventStreamWriteWait))
+	if _, err := s.writer.WriteString(frame); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
+
+func parseEventStreamChannels(values []string) []string {
+	channels := []string{}
+	for _, value := range values {
+		for _, channel := range strings.Split(value, ",") {
+			if channel = strings.TrimSpace(channel); channel != "" {
+				channels = append(channels, channel)
+			}
+		}
+	}
+	return channels
+}
+
+func parseLastEventID(ctx *gin.Context) (uint64, bool, error) {
+	value := ctx.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = ctx.Query("lastEventId")
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid Last-Event-ID %q", value)
+	}
+	return id, true, nil
+}
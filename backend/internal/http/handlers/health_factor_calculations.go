@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"strconv"
 
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
@@ -13,8 +16,17 @@ type HealthFactorCalculator interface {
 	CalculateBurn(ctx context.Context, req model.CalculateBurnRequest) (model.HealthFactorProjection, error)
 	CalculateDeposit(ctx context.Context, req model.CalculateDepositRequest) (model.HealthFactorProjection, error)
 	CalculateRedeem(ctx context.Context, req model.CalculateRedeemRequest) (model.HealthFactorProjection, error)
+	CalculateLiquidation(ctx context.Context, req model.CalculateLiquidationRequest) (model.LiquidationProjection, error)
+	CalculateBatch(ctx context.Context, req model.CalculateBatchRequest) (model.BatchProjection, error)
+	SolveMaxMint(ctx context.Context, req model.SolveMintRequest) (model.SolverResult, error)
+	SolveMaxRedeem(ctx context.Context, req model.SolveCollateralRequest) (model.SolverResult, error)
+	SolveRequiredDeposit(ctx context.Context, req model.SolveCollateralRequest) (model.SolverResult, error)
 }
 
+const maxBatchSteps = 20
+
+var decimalPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
 func CalculateMintHandler(svc HealthFactorCalculator) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		logger := utils.GetLogger()
@@ -22,7 +34,17 @@ func CalculateMintHandler(svc HealthFactorCalculator) gin.HandlerFunc {
 
 		if err := ctx.ShouldBindJSON(&req); err != nil {
 			logger.Warn().Err(err).Msg("Invalid request body for mint calculation")
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		err := normalizeAddressField("address", &req.Address)
+		if err == nil {
+			req.Units, err = parseAmountUnits(ctx)
+		}
+		if err != nil {
+			logger.Warn().Err(err).Msg("Invalid mint calculation request")
+			respondInvalidRequest(ctx, err)
 			return
 		}
 
@@ -31,7 +53,7 @@ func CalculateMintHandler(svc HealthFactorCalculator) gin.HandlerFunc {
 		result, err := svc.CalculateMint(ctx.Request.Context(), req)
 		if err != nil {
 			logger.Error().Err(err).Str("user", req.Address).Msg("Failed to calculate mint health factor")
-			ctx.JSON(500, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
@@ -47,7 +69,17 @@ func CalculateBurnHandler(svc HealthFactorCalculator) gin.HandlerFunc {
 
 		if err := ctx.ShouldBindJSON(&req); err != nil {
 			logger.Warn().Err(err).Msg("Invalid request body for burn calculation")
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		err := normalizeAddressField("address", &req.Address)
+		if err == nil {
+			req.Units, err = parseAmountUnits(ctx)
+		}
+		if err != nil {
+			logger.Warn().Err(err).Msg("Invalid burn calculation request")
+			respondInvalidRequest(ctx, err)
 			return
 		}
 
@@ -56,7 +88,7 @@ func CalculateBurnHandler(svc HealthFactorCalculator) gin.HandlerFunc {
 		result, err := svc.CalculateBurn(ctx.Request.Context(), req)
 		if err != nil {
 			logger.Error().Err(err).Str("user", req.Address).Msg("Failed to calculate burn health factor")
-			ctx.JSON(500, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
@@ -72,7 +104,20 @@ func CalculateDepositHandler(svc HealthFactorCalculator) gin.HandlerFunc {
 
 		if err := ctx.ShouldBindJSON(&req); err != nil {
 			logger.Warn().Err(err).Msg("Invalid request body for deposit calculation")
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		err := normalizeAddressField("address", &req.Address)
+		if err == nil {
+			err = normalizeAddressField("tokenAddress", &req.TokenAddress)
+		}
+		if err == nil {
+			req.Units, err = parseAmountUnits(ctx)
+		}
+		if err != nil {
+			logger.Warn().Err(err).Msg("Invalid deposit calculation request")
+			respondInvalidRequest(ctx, err)
 			return
 		}
 
@@ -81,7 +126,7 @@ func CalculateDepositHandler(svc HealthFactorCalculator) gin.HandlerFunc {
 		result, err := svc.CalculateDeposit(ctx.Request.Context(), req)
 		if err != nil {
 			logger.Error().Err(err).Str("user", req.Address).Str("token", req.TokenAddress).Msg("Failed to calculate deposit health factor")
-			ctx.JSON(500, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
@@ -97,7 +142,20 @@ func CalculateRedeemHandler(svc HealthFactorCalculator) gin.HandlerFunc {
 
 		if err := ctx.ShouldBindJSON(&req); err != nil {
 			logger.Warn().Err(err).Msg("Invalid request body for redeem calculation")
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		err := normalizeAddressField("address", &req.Address)
+		if err == nil {
+			err = normalizeAddressField("tokenAddress", &req.TokenAddress)
+		}
+		if err == nil {
+			req.Units, err = parseAmountUnits(ctx)
+		}
+		if err != nil {
+			logger.Warn().Err(err).Msg("Invalid redeem calculation request")
+			respondInvalidRequest(ctx, err)
 			return
 		}
 
@@ -106,7 +164,7 @@ func CalculateRedeemHandler(svc HealthFactorCalculator) gin.HandlerFunc {
 		result, err := svc.CalculateRedeem(ctx.Request.Context(), req)
 		if err != nil {
 			logger.Error().Err(err).Str("user", req.Address).Str("token", req.TokenAddress).Msg("Failed to calculate redeem health factor")
-			ctx.JSON(500, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
@@ -114,3 +172,202 @@ func CalculateRedeemHandler(svc HealthFactorCalculator) gin.HandlerFunc {
 		ctx.JSON(200, result)
 	}
 }
+
+func CalculateLiquidationHandler(svc HealthFactorCalculator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		var req model.CalculateLiquidationRequest
+
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			logger.Warn().Err(err).Msg("Invalid request body for liquidation calculation")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		err := normalizeAddressField("user", &req.User)
+		if err == nil {
+			err = normalizeAddressField("collateralToken", &req.CollateralToken)
+		}
+		if err == nil {
+			req.Units, err = parseAmountUnits(ctx)
+		}
+		if err != nil {
+			logger.Warn().Err(err).Msg("Invalid liquidation calculation request")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", req.User).Str("debt_to_cover", req.DebtToCover).Str("token", req.CollateralToken).Msg("Calculating liquidation")
+
+		result, err := svc.CalculateLiquidation(ctx.Request.Context(), req)
+		if err != nil {
+			logger.Error().Err(err).Str("user", req.User).Str("token", req.CollateralToken).Msg("Failed to calculate liquidation")
+			respondError(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", req.User).Str("token", req.CollateralToken).Bool("will_revert", result.WillRevert).Str("new_health_factor", result.HealthFactorAfter).Msg("Liquidation calculated successfully")
+		ctx.JSON(200, result)
+	}
+}
+
+func CalculateBatchHandler(svc HealthFactorCalculator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		var req model.CalculateBatchRequest
+
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			logger.Warn().Err(err).Msg("Invalid request body for batch calculation")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		err := validateBatchRequest(&req)
+		if err == nil {
+			req.Units, err = parseAmountUnits(ctx)
+		}
+		if err != nil {
+			logger.Warn().Err(err).Msg("Invalid batch calculation")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", req.Address).Int("steps", len(req.Steps)).Msg("Calculating batch projection")
+
+		result, err := svc.CalculateBatch(ctx.Request.Context(), req)
+		if err != nil {
+			logger.Error().Err(err).Str("user", req.Address).Msg("Failed to calculate batch projection")
+			respondError(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", req.Address).Bool("will_revert", result.WillRevert).Str("new_health_factor", result.FinalPosition.HealthFactor).Msg("Batch projection calculated successfully")
+		ctx.JSON(200, result)
+	}
+}
+
+func validateBatchRequest(req *model.CalculateBatchRequest) error {
+	if err := normalizeAddressField("address", &req.Address); err != nil {
+		return err
+	}
+
+	if len(req.Steps) == 0 || len(req.Steps) > maxBatchSteps {
+		return fmt.Errorf("'steps' must contain between 1 and %d steps", maxBatchSteps)
+	}
+
+	for i := range req.Steps {
+		step := &req.Steps[i]
+		switch step.Type {
+		case model.TransactionTypeDeposit, model.TransactionTypeRedeem:
+			if err := normalizeAddressField(fmt.Sprintf("steps[%d].tokenAddress", i), &step.TokenAddress); err != nil {
+				return err
+			}
+		case model.TransactionTypeMint, model.TransactionTypeBurn:
+			step.TokenAddress = ""
+		default:
+			return fmt.Errorf("invalid 'steps[%d].type' %q: expected deposit, redeem, mint or burn", i, step.Type)
+		}
+	}
+
+	return nil
+}
+
+func SolveMaxMintHandler(svc HealthFactorCalculator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		var req model.SolveMintRequest
+
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			logger.Warn().Err(err).Msg("Invalid request body for max mint solver")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		err := normalizeAddressField("address", &req.Address)
+		if err == nil {
+			err = validateTargetHealthFactor(req.TargetHealthFactor)
+		}
+		if err == nil {
+			req.Units, err = parseAmountUnits(ctx)
+		}
+		if err != nil {
+			logger.Warn().Err(err).Msg("Invalid max mint solver request")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		result, err := svc.SolveMaxMint(ctx.Request.Context(), req)
+		if err != nil {
+			logger.Error().Err(err).Str("user", req.Address).Msg("Failed to solve max mint")
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, result)
+	}
+}
+
+func SolveMaxRedeemHandler(svc HealthFactorCalculator) gin.HandlerFunc {
+	return solveCollateralHandler("max redeem", svc.SolveMaxRedeem)
+}
+
+func SolveRequiredDepositHandler(svc HealthFactorCalculator) gin.HandlerFunc {
+	return solveCollateralHandler("required deposit", svc.SolveRequiredDeposit)
+}
+
+func solveCollateralHandler(name string, solve func(context.Context, model.SolveCollateralRequest) (model.SolverResult, error)) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		var req model.SolveCollateralRequest
+
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			logger.Warn().Err(err).Msgf("Invalid request body for %s solver", name)
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		err := normalizeAddressField("address", &req.Address)
+		if err == nil {
+			err = normalizeAddressField("tokenAddress", &req.TokenAddress)
+		}
+		if err == nil {
+			err = validateTargetHealthFactor(req.TargetHealthFactor)
+		}
+		if err == nil {
+			req.Units, err = parseAmountUnits(ctx)
+		}
+		if err != nil {
+			logger.Warn().Err(err).Msgf("Invalid %s solver request", name)
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		result, err := solve(ctx.Request.Context(), req)
+		if err != nil {
+			logger.Error().Err(err).Str("user", req.Address).Str("token", req.TokenAddress).Msgf("Failed to solve %s", name)
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, result)
+	}
+}
+
+func validateTargetHealthFactor(value string) error {
+	if !decimalPattern.MatchString(value) {
+		return fmt.Errorf("invalid 'targetHealthFactor' %q: expected a decimal such as 1.5", value)
+	}
+	if target, _ := strconv.ParseFloat(value, 64); target < 1 {
+		return fmt.Errorf("'targetHealthFactor' must be at least 1, the engine's minimum health factor")
+	}
+	return nil
+}
+
+// parseAmountUnits reads the amounts query parameter. Responses stay in wei unless it is decimal.
+func parseAmountUnits(ctx *gin.Context) (model.AmountUnits, error) {
+	units := model.AmountUnits(ctx.DefaultQuery("amounts", string(model.AmountUnitsWei)))
+	if units != model.AmountUnitsWei && units != model.AmountUnitsDecimal {
+		return "", fmt.Errorf("invalid 'amounts' %q: expected wei or decimal", units)
+	}
+	return units, nil
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultHealthFactorHistoryRange = 24 * time.Hour
+
+type HealthFactorHistoryReader interface {
+	GetHealthFactorHistory(ctx context.Context, user string, query model.HealthFactorHistoryQuery) (model.HealthFactorHistory, error)
+}
+
+func GetHealthFactorHistoryHandler(svc HealthFactorHistoryReader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		user, err := parseAddressParam(ctx, "user")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", user).Str("endpoint", "/user/:user/health-factor/history").Msg("Request received for health factor history")
+
+		query, err := parseHealthFactorHistoryQuery(ctx)
+		if err != nil {
+			logger.Warn().Err(err).Str("user", user).Msg("Invalid health factor history query")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		history, err := svc.GetHealthFactorHistory(ctx.Request.Context(), user, query)
+		if err != nil {
+			logger.Error().Err(err).Str("user", user).Msg("Failed to get health factor history")
+			respondError(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", user).Int("points", len(history.Points)).Msg("Health factor history retrieved successfully")
+		ctx.JSON(200, history)
+	}
+}
+
+func parseHealthFactorHistoryQuery(ctx *gin.Context) (model.HealthFactorHistoryQuery, error) {
+	to := time.Now()
+	if value := ctx.Query("to"); value != "" {
+		parsed, err := parseTimeParam(value)
+		if err != nil {
+			return model.HealthFactorHistoryQuery{}, fmt.Errorf("invalid 'to': %w", err)
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultHealthFactorHistoryRange)
+	if value := ctx.Query("from"); value != "" {
+		parsed, err := parseTimeParam(value)
+		if err != nil {
+			return model.HealthFactorHistoryQuery{}, fmt.Errorf("invalid 'from': %w", err)
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return model.HealthFactorHistoryQuery{}, fmt.Errorf("'from' must be before 'to'")
+	}
+
+	var resolution time.Duration
+	if value := ctx.Query("resolution"); value != "" && value != "raw" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return model.HealthFactorHistoryQuery{}, fmt.Errorf("invalid 'resolution': expected 'raw' or a positive duration such as 5m or 1h")
+		}
+		resolution = parsed
+	}
+
+	return model.HealthFactorHistoryQuery{
+		From:       from,
+		To:         to,
+		Resolution: resolution,
+	}, nil
+}
+
+func parseTimeParam(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
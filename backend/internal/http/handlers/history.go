@@ -2,6 +2,11 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
@@ -10,19 +15,31 @@ import (
 
 type HistoryReader interface {
 	GetUserHistory(ctx context.Context, userAddress string) (model.HistoryData, error)
+	GetUserHistoryPage(ctx context.Context, userAddress string, query model.HistoryQuery) (model.HistoryPage, error)
+	GetUserHistoryOperations(ctx context.Context, userAddress string, query model.HistoryQuery) (model.HistoryOperationsPage, error)
+	ExportUserHistory(ctx context.Context, userAddress string, filter model.HistoryFilter, cb func(model.StatementRow) error) error
 }
 
+const (
+	defaultHistoryPageLimit = 20
+	maxHistoryPageLimit     = 100
+)
+
 func GetHistoryHandler(svc HistoryReader) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		logger := utils.GetLogger()
-		user := ctx.Param("user")
+		user, err := parseAddressParam(ctx, "user")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
 
 		logger.Info().Str("user", user).Str("endpoint", "/history/:user").Msg("Request received for user history")
 
 		history, err := svc.GetUserHistory(ctx.Request.Context(), user)
 		if err != nil {
 			logger.Error().Err(err).Str("user", user).Msg("Failed to get user history")
-			ctx.JSON(500, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
@@ -30,3 +47,160 @@ func GetHistoryHandler(svc HistoryReader) gin.HandlerFunc {
 		ctx.JSON(200, history)
 	}
 }
+
+func GetHistoryPageHandler(svc HistoryReader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		user, err := parseAddressParam(ctx, "user")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", user).Str("endpoint", "/history/:user/transactions").Msg("Request received for user history page")
+
+		query, err := parseHistoryQuery(ctx)
+		if err != nil {
+			logger.Warn().Err(err).Str("user", user).Msg("Invalid history query")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		page, err := svc.GetUserHistoryPage(ctx.Request.Context(), user, query)
+		if errors.Is(err, model.ErrInvalidHistoryCursor) {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+		if err != nil {
+			logger.Error().Err(err).Str("user", user).Msg("Failed to get user history page")
+			respondError(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", user).Int("transactions", len(page.Transactions)).Msg("User history page retrieved successfully")
+		ctx.JSON(200, page)
+	}
+}
+
+// GetHistoryOperationsHandler serves the user's history grouped by transaction. Type and token filters
+// are rejected because dropping legs would break the before and after health factors.
+func GetHistoryOperationsHandler(svc HistoryReader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		user, err := parseAddressParam(ctx, "user")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", user).Str("endpoint", "/history/:user/operations").Msg("Request received for user history operations")
+
+		if ctx.Query("type") != "" || ctx.Query("token") != "" {
+			respondErrorCode(ctx, 400, model.ErrorCodeInvalidRequest, "'type' and 'token' filters are not supported for operations")
+			return
+		}
+
+		query, err := parseHistoryQuery(ctx)
+		if err != nil {
+			logger.Warn().Err(err).Str("user", user).Msg("Invalid history operations query")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		page, err := svc.GetUserHistoryOperations(ctx.Request.Context(), user, query)
+		if errors.Is(err, model.ErrInvalidHistoryCursor) {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+		if err != nil {
+			logger.Error().Err(err).Str("user", user).Msg("Failed to get user history operations")
+			respondError(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", user).Int("operations", len(page.Operations)).Msg("User history operations retrieved successfully")
+		ctx.JSON(200, page)
+	}
+}
+
+func parseHistoryQuery(ctx *gin.Context) (model.HistoryQuery, error) {
+	query := model.HistoryQuery{
+		Cursor: ctx.Query("cursor"),
+		Limit:  defaultHistoryPageLimit,
+	}
+
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxHistoryPageLimit {
+			return model.HistoryQuery{}, fmt.Errorf("invalid 'limit': expected a number between 1 and %d", maxHistoryPageLimit)
+		}
+		query.Limit = parsed
+	}
+
+	if value := ctx.Query("type"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			transactionType := model.TransactionType(strings.TrimSpace(name))
+			switch transactionType {
+			case model.TransactionTypeDeposit, model.TransactionTypeRedeem, model.TransactionTypeMint, model.TransactionTypeBurn, model.TransactionTypeLiquidation:
+				query.Types = append(query.Types, transactionType)
+			default:
+				return model.HistoryQuery{}, fmt.Errorf("invalid 'type' %q: expected deposit, redeem, mint, burn or liquidation", name)
+			}
+		}
+	}
+
+	var err error
+	if value := ctx.Query("token"); value != "" {
+		if err = normalizeAddressField("token", &value); err != nil {
+			return model.HistoryQuery{}, err
+		}
+		query.Token = value
+	}
+	if query.FromBlock, err = parseOptionalBlock(ctx, "fromBlock"); err != nil {
+		return model.HistoryQuery{}, err
+	}
+	if query.ToBlock, err = parseOptionalBlock(ctx, "toBlock"); err != nil {
+		return model.HistoryQuery{}, err
+	}
+	if query.FromBlock != nil && query.ToBlock != nil && *query.FromBlock > *query.ToBlock {
+		return model.HistoryQuery{}, fmt.Errorf("'fromBlock' must not be after 'toBlock'")
+	}
+
+	if query.From, err = parseOptionalTime(ctx, "from"); err != nil {
+		return model.HistoryQuery{}, err
+	}
+	if query.To, err = parseOptionalTime(ctx, "to"); err != nil {
+		return model.HistoryQuery{}, err
+	}
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		return model.HistoryQuery{}, fmt.Errorf("'from' must be before 'to'")
+	}
+
+	return query, nil
+}
+
+func parseOptionalBlock(ctx *gin.Context, param string) (*uint64, error) {
+	value := ctx.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+
+	block, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid '%s': expected a block number", param)
+	}
+	return &block, nil
+}
+
+func parseOptionalTime(ctx *gin.Context, param string) (*time.Time, error) {
+	value := ctx.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := parseTimeParam(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid '%s': %w", param, err)
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const statementFlushInterval = 100
+
+var statementCSVHeader = []string{"timestamp", "block_number", "tx_hash", "type", "token", "amount", "value_usd"}
+
+// ExportHistoryHandler streams the user's account statement as CSV or a JSON array. The response is
+// only committed once the first row arrives, so a failing query still gets a 500; a failure after
+// that leaves the body truncated.
+func ExportHistoryHandler(svc HistoryReader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		user, err := parseAddressParam(ctx, "user")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		logger.Info().Str("user", user).Str("endpoint", "/history/:user/export").Msg("Request received for user history export")
+
+		format := ctx.DefaultQuery("format", "csv")
+		if format != "csv" && format != "json" {
+			respondErrorCode(ctx, 400, model.ErrorCodeInvalidRequest, "invalid 'format': expected csv or json")
+			return
+		}
+
+		from, err := parseOptionalTime(ctx, "from")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+		to, err := parseOptionalTime(ctx, "to")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+		if from != nil && to != nil && from.After(*to) {
+			respondErrorCode(ctx, 400, model.ErrorCodeInvalidRequest, "'from' must be before 'to'")
+			return
+		}
+
+		writer := &statementWriter{ctx: ctx, format: format}
+		err = svc.ExportUserHistory(ctx.Request.Context(), user, model.HistoryFilter{From: from, To: to}, writer.write)
+		if err != nil {
+			logger.Error().Err(err).Str("user", user).Int("rows", writer.rows).Msg("Failed to export user history")
+			if !writer.started {
+				respondError(ctx, err)
+			}
+			return
+		}
+
+		if err := writer.finish(); err != nil {
+			logger.Warn().Err(err).Str("user", user).Msg("Failed to finish history export")
+			return
+		}
+		logger.Info().Str("user", user).Str("format", format).Int("rows", writer.rows).Msg("User history exported")
+	}
+}
+
+type statementWriter struct {
+	ctx     *gin.Context
+	format  string
+	csv     *csv.Writer
+	started bool
+	rows    int
+}
+
+func (w *statementWriter) start() error {
+	w.started = true
+
+	header := w.ctx.Writer.Header()
+	if w.format == "csv" {
+		header.Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="anchorusd-statement.%s"`, w.format))
+	w.ctx.Status(200)
+
+	if w.format == "csv" {
+		w.csv = csv.NewWriter(w.ctx.Writer)
+		return w.csv.Write(statementCSVHeader)
+	}
+	_, err := w.ctx.Writer.WriteString("[")
+	return err
+}
+
+func (w *statementWriter) write(row model.StatementRow) error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	if w.format == "csv" {
+		record := []string{row.Timestamp, strconv.FormatUint(row.BlockNumber, 10), row.TxHash, string(row.Type), row.Token, row.Amount, row.ValueUSD}
+		if err := w.csv.Write(record); err != nil {
+			return err
+		}
+	} else {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if w.rows > 0 {
+			data = append([]byte(","), data...)
+		}
+		if _, err := w.ctx.Writer.Write(data); err != nil {
+			return err
+		}
+	}
+
+	w.rows++
+	if w.rows%statementFlushInterval == 0 {
+		w.flush()
+	}
+	return nil
+}
+
+func (w *statementWriter) finish() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	if w.format == "json" {
+		if _, err := w.ctx.Writer.WriteString("]"); err != nil {
+			return err
+		}
+	}
+	w.flush()
+	if w.csv != nil {
+		return w.csv.Error()
+	}
+	return nil
+}
+
+func (w *statementWriter) flush() {
+	if w.csv != nil {
+		w.csv.Flush()
+	}
+	w.ctx.Writer.Flush()
+}
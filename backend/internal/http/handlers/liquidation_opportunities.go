@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type LiquidationOpportunitiesReader interface {
+	GetLiquidationOpportunities(ctx context.Context) (model.LiquidationOpportunities, error)
+}
+
+func GetLiquidationOpportunitiesHandler(svc LiquidationOpportunitiesReader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		logger.Info().Str("endpoint", "/liquidations/opportunities").Msg("Request received for liquidation opportunities")
+
+		opportunities, err := svc.GetLiquidationOpportunities(ctx.Request.Context())
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to get liquidation opportunities")
+			respondError(ctx, err)
+			return
+		}
+
+		logger.Info().Int("opportunities", len(opportunities.Opportunities)).Msg("Liquidation opportunities retrieved successfully")
+		ctx.JSON(200, opportunities)
+	}
+}
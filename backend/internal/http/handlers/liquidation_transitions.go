@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLiquidationTransitionsRange = 7 * 24 * time.Hour
+	defaultLiquidationTransitionsLimit = 100
+	maxLiquidationTransitionsLimit     = 1000
+)
+
+type LiquidationTransitionsReader interface {
+	GetLiquidationTransitions(ctx context.Context, query model.LiquidationTransitionsQuery) (model.LiquidationTransitions, error)
+}
+
+func GetLiquidationTransitionsHandler(svc LiquidationTransitionsReader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		logger.Info().Str("endpoint", "/liquidations/transitions").Msg("Request received for liquidation transitions")
+
+		query, err := parseLiquidationTransitionsQuery(ctx)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Invalid liquidation transitions query")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		transitions, err := svc.GetLiquidationTransitions(ctx.Request.Context(), query)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to get liquidation transitions")
+			respondError(ctx, err)
+			return
+		}
+
+		logger.Info().Int("transitions", len(transitions.Transitions)).Msg("Liquidation transitions retrieved successfully")
+		ctx.JSON(200, transitions)
+	}
+}
+
+func parseLiquidationTransitionsQuery(ctx *gin.Context) (model.LiquidationTransitionsQuery, error) {
+	to := time.Now()
+	if value := ctx.Query("to"); value != "" {
+		parsed, err := parseTimeParam(value)
+		if err != nil {
+			return model.LiquidationTransitionsQuery{}, fmt.Errorf("invalid 'to': %w", err)
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultLiquidationTransitionsRange)
+	if value := ctx.Query("from"); value != "" {
+		parsed, err := parseTimeParam(value)
+		if err != nil {
+			return model.LiquidationTransitionsQuery{}, fmt.Errorf("invalid 'from': %w", err)
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return model.LiquidationTransitionsQuery{}, fmt.Errorf("'from' must be before 'to'")
+	}
+
+	limit := defaultLiquidationTransitionsLimit
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxLiquidationTransitionsLimit {
+			return model.LiquidationTransitionsQuery{}, fmt.Errorf("invalid 'limit': expected a number between 1 and %d", maxLiquidationTransitionsLimit)
+		}
+		limit = parsed
+	}
+
+	address := ctx.Query("address")
+	if address != "" {
+		if err := normalizeAddressField("address", &address); err != nil {
+			return model.LiquidationTransitionsQuery{}, err
+		}
+	}
+
+	return model.LiquidationTransitionsQuery{
+		Address: address,
+		From:    from,
+		To:      to,
+		Limit:   limit,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLiquidatorLeaderboardWindow = "7d"
+	defaultLiquidatorLeaderboardLimit  = 10
+	maxLiquidatorLeaderboardLimit      = 100
+)
+
+type LiquidatorsReader interface {
+	GetLiquidatorSummary(ctx context.Context, address string) (model.LiquidatorSummary, error)
+	GetLiquidatorLeaderboard(ctx context.Context, query model.LiquidatorLeaderboardQuery) (model.LiquidatorLeaderboard, error)
+}
+
+func GetLiquidatorSummaryHandler(svc LiquidatorsReader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		address, err := parseAddressParam(ctx, "address")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		logger.Info().Str("liquidator", address).Str("endpoint", "/liquidators/:address").Msg("Request received for liquidator summary")
+
+		summary, err := svc.GetLiquidatorSummary(ctx.Request.Context(), address)
+		if err != nil {
+			logger.Error().Err(err).Str("liquidator", address).Msg("Failed to get liquidator summary")
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, summary)
+	}
+}
+
+// GetLiquidatorLeaderboardHandler ranks liquidators over a 'window' such as 24h, 7d or 30d ending now,
+// or 'all'.
+func GetLiquidatorLeaderboardHandler(svc LiquidatorsReader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		logger.Info().Str("endpoint", "/liquidators/leaderboard").Msg("Request received for liquidator leaderboard")
+
+		query, err := parseLiquidatorLeaderboardQuery(ctx)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Invalid liquidator leaderboard query")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		leaderboard, err := svc.GetLiquidatorLeaderboard(ctx.Request.Context(), query)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to get liquidator leaderboard")
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, leaderboard)
+	}
+}
+
+func parseLiquidatorLeaderboardQuery(ctx *gin.Context) (model.LiquidatorLeaderboardQuery, error) {
+	query := model.LiquidatorLeaderboardQuery{
+		Window: ctx.DefaultQuery("window", defaultLiquidatorLeaderboardWindow),
+		Limit:  defaultLiquidatorLeaderboardLimit,
+	}
+
+	if query.Window != "all" {
+		window, err := parseWindow(query.Window)
+		if err != nil {
+			return model.LiquidatorLeaderboardQuery{}, fmt.Errorf("invalid 'window': expected 'all' or a positive duration such as 24h, 7d or 30d")
+		}
+		since := time.Now().Add(-window)
+		query.Since = &since
+	}
+
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxLiquidatorLeaderboardLimit {
+			return model.LiquidatorLeaderboardQuery{}, fmt.Errorf("invalid 'limit': expected a number between 1 and %d", maxLiquidatorLeaderboardLimit)
+		}
+		query.Limit = parsed
+	}
+
+	return query, nil
+}
+
+// parseWindow accepts Go durations plus a day suffix, which time.ParseDuration lacks.
+func parseWindow(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("invalid window %q", value)
+	}
+	return window, nil
+}
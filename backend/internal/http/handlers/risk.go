@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type RiskAnalyzer interface {
+	StressTest(ctx context.Context, req model.StressTestRequest) (model.StressTestResult, error)
+}
+
+func StressTestHandler(svc RiskAnalyzer) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		var req model.StressTestRequest
+
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			logger.Warn().Err(err).Msg("Invalid request body for stress test")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		if err := validateStressTestRequest(req); err != nil {
+			logger.Warn().Err(err).Msg("Invalid stress test scenario")
+			respondInvalidRequest(ctx, err)
+			return
+		}
+
+		logger.Info().Interface("price_changes", req.PriceChanges).Interface("prices", req.Prices).Msg("Running stress test")
+
+		result, err := svc.StressTest(ctx.Request.Context(), req)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to run stress test")
+			respondError(ctx, err)
+			return
+		}
+
+		logger.Info().Int("newly_liquidatable", len(result.NewlyLiquidatable)).Str("bad_debt", result.BadDebt).Msg("Stress test completed successfully")
+		ctx.JSON(200, result)
+	}
+}
+
+func validateStressTestRequest(req model.StressTestRequest) error {
+	if len(req.PriceChanges) == 0 && len(req.Prices) == 0 {
+		return fmt.Errorf("at least one of 'priceChanges' or 'prices' is required")
+	}
+
+	for token, change := range req.PriceChanges {
+		if _, ok := constants.CollateralTokens[token]; !ok {
+			return fmt.Errorf("unknown collateral token %q", token)
+		}
+		if change < -100 {
+			return fmt.Errorf("price change for %s must be greater than or equal to -100", token)
+		}
+		if _, ok := req.Prices[token]; ok {
+			return fmt.Errorf("token %s has both a price change and an absolute price", token)
+		}
+	}
+
+	for token := range req.Prices {
+		if _, ok := constants.CollateralTokens[token]; !ok {
+			return fmt.Errorf("unknown collateral token %q", token)
+		}
+	}
+
+	return nil
+}
@@ -15,14 +15,18 @@ type UserReader interface {
 func GetUserDataHandler(svc UserReader) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		logger := utils.GetLogger()
-		user := ctx.Param("user")
+		user, err := parseAddressParam(ctx, "user")
+		if err != nil {
+			respondInvalidRequest(ctx, err)
+			return
+		}
 
 		logger.Info().Str("user", user).Str("endpoint", "/user/:user").Msg("Request received for user data")
 
 		metrics, err := svc.GetUserData(ctx.Request.Context(), user)
 		if err != nil {
 			logger.Error().Err(err).Str("user", user).Msg("Failed to get user data")
-			ctx.JSON(500, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
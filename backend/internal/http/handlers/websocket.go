@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/middlewares"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/realtime"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultWebSocketMaxConnections      = 1000
+	defaultWebSocketMaxConnectionsPerIP = 10
+	webSocketWriteWait                  = 10 * time.Second
+	webSocketPongWait                   = 60 * time.Second
+	webSocketPingInterval               = 30 * time.Second
+	webSocketMaxMessageSize             = 4096
+	webSocketRepliesBufferSize          = 16
+)
+
+type RealtimeHub interface {
+	NewSubscriber() (*realtime.Subscriber, error)
+}
+
+func WebSocketHandler(hub RealtimeHub) gin.HandlerFunc {
+	limiter := newConnectionLimiter(
+		utils.IntFromEnv("WS_MAX_CONNECTIONS", defaultWebSocketMaxConnections),
+		utils.IntFromEnv("WS_MAX_CONNECTIONS_PER_IP", defaultWebSocketMaxConnectionsPerIP),
+	)
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     checkWebSocketOrigin(middlewares.AllowedOriginsFromEnv()),
+	}
+
+	return func(ctx *gin.Context) {
+		logger := utils.GetLogger()
+		clientIP := ctx.ClientIP()
+
+		if !limiter.acquire(clientIP) {
+			logger.Warn().Str("ip", clientIP).Msg("WebSocket connection limit reached")
+			respondErrorCode(ctx, 429, model.ErrorCodeRateLimited, "too many WebSocket connections")
+			return
+		}
+		defer limiter.release(clientIP)
+
+		subscriber, err := hub.NewSubscriber()
+		if err != nil {
+			logger.Warn().Err(err).Msg("Rejecting WebSocket connection")
+			respondErrorCode(ctx, 503, model.ErrorCodeServiceUnavailable, err.Error())
+			return
+		}
+		defer subscriber.Close()
+
+		conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+		if err != nil {
+			logger.Warn().Err(err).Str("ip", clientIP).Msg("Failed to upgrade WebSocket connection")
+			return
+		}
+		defer conn.Close()
+
+		logger.Info().Str("ip", clientIP).Msg("WebSocket client connected")
+
+		client := &webSocketClient{
+			conn:       conn,
+			subscriber: subscriber,
+			replies:    make(chan model.RealtimeServerMessage, webSocketRepliesBufferSize),
+			done:       make(chan struct{}),
+		}
+		go client.readLoop()
+		client.writeLoop()
+
+		logger.Info().Str("ip", clientIP).Msg("WebSocket client disconnected")
+	}
+}
+
+// checkWebSocketOrigin accepts handshakes without an Origin header, which only non-browser clients
+// omit, same-origin handshakes and origins on the CORS allow-list, so other sites cannot open
+// connections from a visitor's browser.
+func checkWebSocketOrigin(allowed middlewares.AllowedOrigins) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if parsed, err := url.Parse(origin); err == nil && strings.EqualFold(parsed.Host, r.Host) {
+			return true
+		}
+		return allowed.Allows(origin)
+	}
+}
+
+type webSocketClient struct {
+	conn       *websocket.Conn
+	subscriber *realtime.Subscriber
+	replies    chan model.RealtimeServerMessage
+	done       chan struct{}
+}
+
+// readLoop handles subscription messages from the client. It is the only reader of the connection.
+func (c *webSocketClient) readLoop() {
+	defer close(c.done)
+
+	c.conn.SetReadLimit(webSocketMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(webSocketPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(webSocketPongWait))
+	})
+
+	for {
+		var message model.RealtimeClientMessage
+		if err := c.conn.ReadJSON(&message); err != nil {
+			if !isJSONError(err) {
+				return
+			}
+			c.reply(model.RealtimeServerMessage{Type: "error", Error: "invalid message: " + err.Error()})
+			continue
+		}
+
+		c.handle(message)
+	}
+}
+
+// isJSONError distinguishes a malformed client message, which keeps the connection open,
+// from connection errors such as a close frame or a missed heartbeat.
+func isJSONError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func (c *webSocketClient) handle(message model.RealtimeClientMessage) {
+	switch message.Action {
+	case model.RealtimeActionSubscribe:
+		channels, err := c.subscriber.Subscribe(message.Channels...)
+		if err != nil {
+			c.reply(model.RealtimeServerMessage{Type: "error", Error: err.Error()})
+			return
+		}
+		c.reply(model.RealtimeServerMessage{Type: "subscribed", Channels: channels})
+
+	case model.RealtimeActionUnsubscribe:
+		channels := c.subscriber.Unsubscribe(message.Channels...)
+		c.reply(model.RealtimeServerMessage{Type: "unsubscribed", Channels: channels})
+
+	case model.RealtimeActionPing:
+		c.reply(model.RealtimeServerMessage{Type: "pong"})
+
+	default:
+		c.reply(model.RealtimeServerMessage{Type: "error", Error: "unknown action " + strconv.Quote(string(message.Action))})
+	}
+}
+
+func (c *webSocketClient) reply(message model.RealtimeServerMessage) {
+	select {
+	case c.replies <- message:
+	default:
+	}
+}
+
+// writeLoop pushes events, replies and heartbeats. It is the only writer of the connection.
+func (c *webSocketClient) writeLoop() {
+	ticker := time.NewTicker(webSocketPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+
+		case event, ok := <-c.subscriber.Events():
+			if !ok {
+				c.close(c.subscriber.Err())
+				return
+			}
+			if err := c.write(event); err != nil {
+				return
+			}
+
+		case reply := <-c.replies:
+			if err := c.write(reply); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(webSocketWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *webSocketClient) write(message any) error {
+	c.conn.SetWriteDeadline(time.Now().Add(webSocketWriteWait))
+	return c.conn.WriteJSON(message)
+}
+
+func (c *webSocketClient) close(reason error) {
+	code, text := websocket.CloseNormalClosure, ""
+	switch {
+	case errors.Is(reason, realtime.ErrHubClosed):
+		code, text = websocket.CloseGoingAway, "server shutting down"
+	case errors.Is(reason, realtime.ErrSlowSubscriber):
+		code, text = websocket.CloseTryAgainLater, "client too slow"
+	}
+
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(webSocketWriteWait))
+}
+
+type connectionLimiter struct {
+	mu       sync.Mutex
+	max      int
+	maxPerIP int
+	total    int
+	perIP    map[string]int
+}
+
+func newConnectionLimiter(max, maxPerIP int) *connectionLimiter {
+	return &connectionLimiter{
+		max:      max,
+		maxPerIP: maxPerIP,
+		perIP:    make(map[string]int),
+	}
+}
+
+func (l *connectionLimiter) acquire(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.total >= l.max || l.perIP[ip] >= l.maxPerIP {
+		return false
+	}
+	l.total++
+	l.perIP[ip]++
+	return true
+}
+
+func (l *connectionLimiter) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.total--
+	l.perIP[ip]--
+	if l.perIP[ip] <= 0 {
+		delete(l.perIP, ip)
+	}
+}
@@ -1,13 +1,82 @@
 package middlewares
 
-import "github.com/gin-gonic/gin"
+import (
+	"os"
+	"strings"
+	"sync"
 
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AllowedOrigins is the cross-origin allow-list shared by CORS and the WebSocket handshake.
+type AllowedOrigins struct {
+	any     bool
+	origins map[string]bool
+}
+
+// AllowedOriginsFromEnv reads the comma-separated CORS_ALLOWED_ORIGINS, such as
+// https://app.example.com,http://localhost:3001. "*" allows every origin; when the variable is unset
+// no cross-origin browser request is allowed.
+func AllowedOriginsFromEnv() AllowedOrigins {
+	return ParseAllowedOrigins(os.Getenv("CORS_ALLOWED_ORIGINS"))
+}
+
+// ParseAllowedOrigins parses a comma-separated origin list. Origins are compared without a trailing
+// slash and case-insensitively.
+func ParseAllowedOrigins(value string) AllowedOrigins {
+	allowed := AllowedOrigins{origins: map[string]bool{}}
+	for _, origin := range strings.Split(value, ",") {
+		origin = normalizeOrigin(origin)
+		switch origin {
+		case "":
+		case "*":
+			allowed.any = true
+		default:
+			allowed.origins[origin] = true
+		}
+	}
+	return allowed
+}
+
+// Allows reports whether a browser on origin may call the API.
+func (a AllowedOrigins) Allows(origin string) bool {
+	origin = normalizeOrigin(origin)
+	if origin == "" {
+		return false
+	}
+	return a.any || a.origins[origin]
+}
+
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+}
+
+var (
+	corsOriginsOnce sync.Once
+	corsOrigins     AllowedOrigins
+)
+
+// CORSMiddleware answers allowed origins with their own origin, so credentials can be sent. The
+// allow-list is read on the first request, once the environment is loaded.
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT")
+		corsOriginsOnce.Do(func() {
+			corsOrigins = AllowedOriginsFromEnv()
+			if len(corsOrigins.origins) == 0 && !corsOrigins.any {
+				logger := utils.GetLogger()
+				logger.Warn().Msg("CORS_ALLOWED_ORIGINS is not set, cross-origin requests are refused")
+			}
+		})
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if origin := c.GetHeader("Origin"); corsOrigins.Allows(origin) {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, Last-Event-ID, X-Request-ID, X-API-Key, X-AnchorUSD-Owner-Signature, X-AnchorUSD-Owner-Timestamp")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -16,4 +85,4 @@ func CORSMiddleware() gin.HandlerFunc {
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
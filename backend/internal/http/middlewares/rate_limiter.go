@@ -1,19 +1,122 @@
 package middlewares
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 )
 
-func RateLimitMiddleware(limiter *rate.Limiter) gin.HandlerFunc {
+const (
+	APIKeyHeader = "X-API-Key"
+
+	defaultRateLimitWindow         = time.Minute
+	defaultRateLimitIPRequests     = 120
+	defaultRateLimitAPIKeyRequests = 1200
+)
+
+// RateLimitStore counts requests in fixed windows shared by every replica.
+type RateLimitStore interface {
+	IncrementWindow(key string, window time.Duration) (int64, time.Duration, error)
+}
+
+// RateLimit allows Requests per Window to each client.
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// RateLimitPolicy holds the limit of anonymous clients, counted per IP, and of clients sending a known
+// API key, counted per key.
+type RateLimitPolicy struct {
+	IP     RateLimit
+	APIKey RateLimit
+}
+
+type RateLimiter struct {
+	store  RateLimitStore
+	policy RateLimitPolicy
+	// apiKeys maps each configured API key to the hash it is counted under, so keys are not
+	// written to Redis.
+	apiKeys map[string]string
+}
+
+func NewRateLimiter(store RateLimitStore, policy RateLimitPolicy, apiKeys []string) *RateLimiter {
+	limiter := &RateLimiter{store: store, policy: policy, apiKeys: map[string]string{}}
+	for _, key := range apiKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			hash := sha256.Sum256([]byte(key))
+			limiter.apiKeys[key] = hex.EncodeToString(hash[:8])
+		}
+	}
+	return limiter
+}
+
+// RateLimitPolicyFromEnv reads the default policy from RATE_LIMIT_WINDOW_SECONDS,
+// RATE_LIMIT_IP_REQUESTS and RATE_LIMIT_API_KEY_REQUESTS.
+func RateLimitPolicyFromEnv() RateLimitPolicy {
+	window := time.Duration(utils.IntFromEnv("RATE_LIMIT_WINDOW_SECONDS", int(defaultRateLimitWindow.Seconds()))) * time.Second
+	return RateLimitPolicy{
+		IP:     RateLimit{Requests: utils.IntFromEnv("RATE_LIMIT_IP_REQUESTS", defaultRateLimitIPRequests), Window: window},
+		APIKey: RateLimit{Requests: utils.IntFromEnv("RATE_LIMIT_API_KEY_REQUESTS", defaultRateLimitAPIKeyRequests), Window: window},
+	}
+}
+
+// APIKeysFromEnv reads the comma-separated API_KEYS.
+func APIKeysFromEnv() []string {
+	return strings.Split(os.Getenv("API_KEYS"), ",")
+}
+
+// Middleware limits a route. Routes without an override share each client's default window; an
+// override counts in a window of its own, named by route. Requests are let through when the store
+// fails, so a Redis outage does not take the API down with it.
+func (l *RateLimiter) Middleware(route string, override *RateLimitPolicy) gin.HandlerFunc {
+	policy, bucket := l.policy, "default"
+	if override != nil {
+		policy, bucket = *override, route
+	}
+
 	return func(c *gin.Context) {
-            if !limiter.Allow() {
-                c.JSON(http.StatusTooManyRequests, map[string]string{
-                    "error": "Too Many Requests",
-                })
-            }
-            c.Next()
-        }
-}
\ No newline at end of file
+		limit, client := policy.IP, "ip:"+c.ClientIP()
+		if id, ok := l.apiKeys[c.GetHeader(APIKeyHeader)]; ok {
+			limit, client = policy.APIKey, "key:"+id
+		}
+
+		count, resetIn, err := l.store.IncrementWindow(fmt.Sprintf("ratelimit:%s:%s", bucket, client), limit.Window)
+		if err != nil {
+			logger := utils.GetLogger()
+			logger.Warn().Err(err).Str("client", client).Msg("Rate limit store unavailable, allowing request")
+			c.Next()
+			return
+		}
+
+		remaining := max(int64(limit.Requests)-count, 0)
+		reset := strconv.Itoa(int(math.Ceil(resetIn.Seconds())))
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		c.Header("RateLimit-Reset", reset)
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Window.Seconds())))
+
+		if count > int64(limit.Requests) {
+			c.Header("Retry-After", reset)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, model.ErrorResponse{
+				Error: model.APIError{
+					Code:      model.ErrorCodeRateLimited,
+					Message:   "too many requests",
+					RequestID: RequestID(c),
+				},
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+)
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware tags every request with an ID, echoed in the X-Request-ID response header and
+// in error bodies. A well-formed ID sent by the client or a proxy is kept so logs can be correlated
+// across hops; anything else is replaced with a fresh UUID.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(requestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// RequestID returns the ID assigned by RequestIDMiddleware, or an empty string outside of it.
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
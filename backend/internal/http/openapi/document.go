@@ -0,0 +1,205 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	ContentTypeJSON        = "application/json"
+	ContentTypeCSV         = "text/csv"
+	ContentTypeEventStream = "text/event-stream"
+)
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathItem holds the operations of one path, keyed by lowercase HTTP method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Param documents a path, query or header parameter. Path parameters are always required.
+type Param struct {
+	Name        string
+	Description string
+	Type        string
+	Required    bool
+}
+
+// Endpoint describes a route in terms of Go values: Request and Response are zero values of the
+// types bound from and written to the body, so the document is generated from the same types the
+// handlers use.
+type Endpoint struct {
+	Method       string
+	Path         string
+	OperationID  string
+	Summary      string
+	Description  string
+	Tag          string
+	PathParams   []Param
+	QueryParams  []Param
+	HeaderParams []Param
+	Request      any
+	// Response is the JSON body of a successful call, or nil when there is none.
+	Response any
+	// Status is the success status, 200 when zero.
+	Status int
+	// Content overrides the success response content when it is not only JSON.
+	Content map[string]any
+}
+
+var ginParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+// Build generates the document for endpoints served under basePath. Every operation documents
+// errorResponse as its default response, and extra adds models that are not part of any route, such
+// as WebSocket messages and webhook payloads.
+func Build(info Info, basePath string, endpoints []Endpoint, errorResponse any, extra ...any) *Document {
+	g := newGenerator()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Servers: []Server{{URL: basePath}},
+		Paths:   map[string]*PathItem{},
+	}
+
+	errorSchema := g.schemaOf(reflect.TypeOf(errorResponse))
+	for _, endpoint := range endpoints {
+		path := SpecPath(endpoint.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(endpoint.Method)] = g.operation(endpoint, errorSchema)
+	}
+
+	for _, model := range extra {
+		g.schemaOf(reflect.TypeOf(model))
+	}
+
+	doc.Components.Schemas = g.schemas
+	return doc
+}
+
+// SpecPath converts a gin route path such as /user/:user to the OpenAPI form /user/{user}.
+func SpecPath(path string) string {
+	return ginParam.ReplaceAllString(path, "{$1}")
+}
+
+func (g *generator) operation(endpoint Endpoint, errorSchema *Schema) *Operation {
+	op := &Operation{
+		OperationID: endpoint.OperationID,
+		Summary:     endpoint.Summary,
+		Description: endpoint.Description,
+		Responses:   map[string]*Response{},
+	}
+	if endpoint.Tag != "" {
+		op.Tags = []string{endpoint.Tag}
+	}
+
+	for _, param := range endpoint.PathParams {
+		op.Parameters = append(op.Parameters, parameter(param, "path", true))
+	}
+	for _, param := range endpoint.QueryParams {
+		op.Parameters = append(op.Parameters, parameter(param, "query", param.Required))
+	}
+	for _, param := range endpoint.HeaderParams {
+		op.Parameters = append(op.Parameters, parameter(param, "header", param.Required))
+	}
+
+	if endpoint.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{ContentTypeJSON: {Schema: g.schemaOf(reflect.TypeOf(endpoint.Request))}},
+		}
+	}
+
+	status := endpoint.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status)}
+	switch {
+	case endpoint.Content != nil:
+		success.Content = map[string]*MediaType{}
+		for contentType, model := range endpoint.Content {
+			success.Content[contentType] = &MediaType{Schema: g.schemaOf(reflect.TypeOf(model))}
+		}
+	case endpoint.Response != nil:
+		success.Content = map[string]*MediaType{ContentTypeJSON: {Schema: g.schemaOf(reflect.TypeOf(endpoint.Response))}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	op.Responses["default"] = &Response{
+		Description: "Error",
+		Content:     map[string]*MediaType{ContentTypeJSON: {Schema: errorSchema}},
+	}
+	return op
+}
+
+func parameter(param Param, in string, required bool) Parameter {
+	paramType := param.Type
+	if paramType == "" {
+		paramType = "string"
+	}
+	return Parameter{
+		Name:        param.Name,
+		In:          in,
+		Description: param.Description,
+		Required:    required,
+		Schema:      &Schema{Type: paramType},
+	}
+}
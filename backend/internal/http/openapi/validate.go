@@ -0,0 +1,168 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateResponse checks a response body against the schema documented for the operation at the
+// given method, spec path and status, falling back to the default response.
+func (d *Document) ValidateResponse(method string, path string, status int, contentType string, body []byte) error {
+	item, ok := d.Paths[path]
+	if !ok {
+		return fmt.Errorf("path %s is not documented", path)
+	}
+	op, ok := (*item)[strings.ToLower(method)]
+	if !ok {
+		return fmt.Errorf("%s %s is not documented", method, path)
+	}
+
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		if response, ok = op.Responses["default"]; !ok || status < 400 {
+			return fmt.Errorf("%s %s does not document status %d", method, path, status)
+		}
+	}
+
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("%s %s %d documents no body but returned %d bytes", method, path, status, len(body))
+		}
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%s %s %d: invalid content type %q", method, path, status, contentType)
+	}
+	media, ok := response.Content[mediaType]
+	if !ok {
+		return fmt.Errorf("%s %s %d does not document content type %s", method, path, status, mediaType)
+	}
+	if mediaType != ContentTypeJSON {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("%s %s %d: invalid JSON body: %w", method, path, status, err)
+	}
+	return d.Validate(media.Schema, value)
+}
+
+// Validate checks a JSON value decoded with json.Decoder.UseNumber against schema.
+func (d *Document) Validate(schema *Schema, value any) error {
+	return d.validate(schema, value, "$")
+}
+
+func (d *Document) validate(schema *Schema, value any, at string) error {
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, schemaRefPrefix)
+		resolved, ok := d.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("%s: unresolved reference %s", at, schema.Ref)
+		}
+		return d.validate(resolved, value, at)
+	}
+
+	if value == nil {
+		if schema.Nullable || (schema.Type == "" && len(schema.AllOf) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%s: null is not allowed", at)
+	}
+
+	for _, sub := range schema.AllOf {
+		if err := d.validate(sub, value, at); err != nil {
+			return err
+		}
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected a string, got %T", at, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected a boolean, got %T", at, value)
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: expected an integer, got %T", at, value)
+		}
+		if _, err := strconv.ParseInt(number.String(), 10, 64); err != nil {
+			if _, err := strconv.ParseUint(number.String(), 10, 64); err != nil {
+				return fmt.Errorf("%s: expected an integer, got %s", at, number)
+			}
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("%s: expected a number, got %T", at, value)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %T", at, value)
+		}
+		for i, item := range items {
+			if err := d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %T", at, value)
+		}
+		return d.validateObject(schema, object, at)
+	default:
+		return fmt.Errorf("%s: unsupported schema type %q", at, schema.Type)
+	}
+	return nil
+}
+
+func (d *Document) validateObject(schema *Schema, object map[string]any, at string) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", at, name)
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertySchema, ok := schema.Properties[name]
+		if !ok {
+			switch additional := schema.AdditionalProperties.(type) {
+			case *Schema:
+				propertySchema = additional
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s: undocumented property %q", at, name)
+				}
+				continue
+			default:
+				continue
+			}
+		}
+		if err := d.validate(propertySchema, object[name], at+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
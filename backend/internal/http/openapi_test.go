@@ -0,0 +1,339 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/handlers"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/middlewares"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/openapi"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAddress = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	// testOwnerKey signs alert requests for the address it derives, testOwnerAddress.
+	testOwnerKey     = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testOwnerAddress = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+)
+
+// signAlertOwner adds the headers that prove the request comes from testOwnerAddress.
+func signAlertOwner(t *testing.T, request *http.Request) {
+	key, err := crypto.HexToECDSA(testOwnerKey)
+	require.NoError(t, err)
+
+	timestamp := time.Now().Unix()
+	message := domain.AlertOwnerMessage(testOwnerAddress, timestamp)
+	signature, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	require.NoError(t, err)
+
+	request.Header.Set(handlers.AlertOwnerTimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(handlers.AlertOwnerSignatureHeader, hexutil.Encode(signature))
+}
+
+// filledServices implements every service interface used by the routes and returns values with all
+// fields set, so each documented property shows up in the response bodies under test.
+type filledServices struct{}
+
+func filled[T any]() T {
+	var value T
+	fill(reflect.ValueOf(&value).Elem())
+	return value
+}
+
+func fill(v reflect.Value) {
+	if v.Type() == reflect.TypeOf(json.RawMessage{}) {
+		v.Set(reflect.ValueOf(json.RawMessage(`{}`)))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString("1")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem())
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0))
+	case reflect.Map:
+		key := reflect.New(v.Type().Key()).Elem()
+		fill(key)
+		value := reflect.New(v.Type().Elem()).Elem()
+		fill(value)
+		v.Set(reflect.MakeMap(v.Type()))
+		v.SetMapIndex(key, value)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				fill(v.Field(i))
+			}
+		}
+	}
+}
+
+func (filledServices) GetUserData(context.Context, string) (model.UserData, error) {
+	return filled[model.UserData](), nil
+}
+
+func (filledServices) CalculateMint(context.Context, model.CalculateMintRequest) (model.HealthFactorProjection, error) {
+	return filled[model.HealthFactorProjection](), nil
+}
+
+func (filledServices) CalculateBurn(context.Context, model.CalculateBurnRequest) (model.HealthFactorProjection, error) {
+	return filled[model.HealthFactorProjection](), nil
+}
+
+func (filledServices) CalculateDeposit(context.Context, model.CalculateDepositRequest) (model.HealthFactorProjection, error) {
+	return filled[model.HealthFactorProjection](), nil
+}
+
+func (filledServices) CalculateRedeem(context.Context, model.CalculateRedeemRequest) (model.HealthFactorProjection, error) {
+	return filled[model.HealthFactorProjection](), nil
+}
+
+func (filledServices) CalculateLiquidation(context.Context, model.CalculateLiquidationRequest) (model.LiquidationProjection, error) {
+	return filled[model.LiquidationProjection](), nil
+}
+
+func (filledServices) CalculateBatch(context.Context, model.CalculateBatchRequest) (model.BatchProjection, error) {
+	return filled[model.BatchProjection](), nil
+}
+
+func (filledServices) SolveMaxMint(context.Context, model.SolveMintRequest) (model.SolverResult, error) {
+	return filled[model.SolverResult](), nil
+}
+
+func (filledServices) SolveMaxRedeem(context.Context, model.SolveCollateralRequest) (model.SolverResult, error) {
+	return filled[model.SolverResult](), nil
+}
+
+func (filledServices) SolveRequiredDeposit(context.Context, model.SolveCollateralRequest) (model.SolverResult, error) {
+	return filled[model.SolverResult](), nil
+}
+
+func (filledServices) GetDashboardMetrics(context.Context) (model.DashboardMetrics, error) {
+	return filled[model.DashboardMetrics](), nil
+}
+
+func (filledServices) GetUserHistory(context.Context, string) (model.HistoryData, error) {
+	return filled[model.HistoryData](), nil
+}
+
+func (filledServices) GetUserHistoryPage(context.Context, string, model.HistoryQuery) (model.HistoryPage, error) {
+	return filled[model.HistoryPage](), nil
+}
+
+func (filledServices) GetUserHistoryOperations(context.Context, string, model.HistoryQuery) (model.HistoryOperationsPage, error) {
+	return filled[model.HistoryOperationsPage](), nil
+}
+
+func (filledServices) ExportUserHistory(_ context.Context, _ string, _ model.HistoryFilter, cb func(model.StatementRow) error) error {
+	return cb(filled[model.StatementRow]())
+}
+
+func (filledServices) GetHealthFactorHistory(context.Context, string, model.HealthFactorHistoryQuery) (model.HealthFactorHistory, error) {
+	return filled[model.HealthFactorHistory](), nil
+}
+
+func (filledServices) GetLiquidationTransitions(context.Context, model.LiquidationTransitionsQuery) (model.LiquidationTransitions, error) {
+	return filled[model.LiquidationTransitions](), nil
+}
+
+func (filledServices) GetLiquidationOpportunities(context.Context) (model.LiquidationOpportunities, error) {
+	return filled[model.LiquidationOpportunities](), nil
+}
+
+func (filledServices) GetLiquidatorSummary(context.Context, string) (model.LiquidatorSummary, error) {
+	return filled[model.LiquidatorSummary](), nil
+}
+
+func (filledServices) GetLiquidatorLeaderboard(context.Context, model.LiquidatorLeaderboardQuery) (model.LiquidatorLeaderboard, error) {
+	return filled[model.LiquidatorLeaderboard](), nil
+}
+
+func (filledServices) GetDailyStats(context.Context, model.DailyStatsQuery) (model.DailyStats, error) {
+	return filled[model.DailyStats](), nil
+}
+
+func (filledServices) StressTest(context.Context, model.StressTestRequest) (model.StressTestResult, error) {
+	return filled[model.StressTestResult](), nil
+}
+
+func (filledServices) CreateSubscription(context.Context, model.CreateAlertSubscriptionRequest) (model.CreatedAlertSubscription, error) {
+	return filled[model.CreatedAlertSubscription](), nil
+}
+
+func (filledServices) GetSubscriptions(context.Context, string) ([]model.AlertSubscription, error) {
+	return filled[[]model.AlertSubscription](), nil
+}
+
+func (filledServices) DeleteSubscription(context.Context, string, uint) error {
+	return nil
+}
+
+func (filledServices) GetDeliveries(context.Context, string, uint, int) ([]model.AlertDelivery, error) {
+	return filled[[]model.AlertDelivery](), nil
+}
+
+func newTestAPI() (*gin.Engine, []route) {
+	gin.SetMode(gin.TestMode)
+	svc := filledServices{}
+	// The realtime routes hold long-lived connections and are not called here, so they get no hub.
+	routes := apiRoutes(svc, svc, svc, svc, svc, svc, svc, svc, svc, svc, svc, nil)
+
+	engine := gin.New()
+	registerAPI(engine.Group("/api"), routes, middlewares.NewRateLimiter(newFakeRateLimitStore(), middlewares.RateLimitPolicyFromEnv(), nil))
+	return engine, routes
+}
+
+// TestResponsesMatchOpenAPIDocument calls every request/response endpoint with valid input and checks
+// the body against the served document, so a model change that is not reflected in the spec fails.
+func TestResponsesMatchOpenAPIDocument(t *testing.T) {
+	constants.CollateralTokens["WETH"] = testAddress
+	t.Cleanup(func() { delete(constants.CollateralTokens, "WETH") })
+
+	engine, routes := newTestAPI()
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var document openapi.Document
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+
+	tests := map[string]struct {
+		target string
+		body   string
+		owner  bool
+	}{
+		"GET /metrics/dashboard":                   {target: "/metrics/dashboard"},
+		"GET /user/:user":                          {target: "/user/" + testAddress},
+		"GET /user/:user/health-factor/history":    {target: "/user/" + testAddress + "/health-factor/history"},
+		"GET /history/:user":                       {target: "/history/" + testAddress},
+		"GET /history/:user/transactions":          {target: "/history/" + testAddress + "/transactions"},
+		"GET /history/:user/operations":            {target: "/history/" + testAddress + "/operations"},
+		"GET /history/:user/export":                {target: "/history/" + testAddress + "/export?format=json"},
+		"GET /liquidations/transitions":            {target: "/liquidations/transitions"},
+		"GET /liquidations/opportunities":          {target: "/liquidations/opportunities"},
+		"GET /liquidators/leaderboard":             {target: "/liquidators/leaderboard"},
+		"GET /liquidators/:address":                {target: "/liquidators/" + testAddress},
+		"GET /stats/daily":                         {target: "/stats/daily"},
+		"POST /risk/stress-test":                   {target: "/risk/stress-test", body: `{"priceChanges":{"WETH":-30}}`},
+		"POST /alerts":                             {target: "/alerts", body: `{"address":"` + testOwnerAddress + `","webhookUrl":"https://example.com/hook","threshold":"1.3"}`, owner: true},
+		"GET /alerts/:user":                        {target: "/alerts/" + testOwnerAddress, owner: true},
+		"DELETE /alerts/:user/:id":                 {target: "/alerts/" + testOwnerAddress + "/1", owner: true},
+		"GET /alerts/:user/:id/deliveries":         {target: "/alerts/" + testOwnerAddress + "/1/deliveries", owner: true},
+		"POST /ausd-engine/calculate-mint":         {target: "/ausd-engine/calculate-mint?amounts=decimal", body: `{"address":"` + testAddress + `","mintAmount":"1.5"}`},
+		"POST /ausd-engine/calculate-burn":         {target: "/ausd-engine/calculate-burn", body: `{"address":"` + testAddress + `","burnAmount":"1"}`},
+		"POST /ausd-engine/calculate-deposit":      {target: "/ausd-engine/calculate-deposit", body: `{"address":"` + testAddress + `","tokenAddress":"` + testAddress + `","depositAmount":"1"}`},
+		"POST /ausd-engine/calculate-redeem":       {target: "/ausd-engine/calculate-redeem", body: `{"address":"` + testAddress + `","tokenAddress":"` + testAddress + `","redeemAmount":"1"}`},
+		"POST /ausd-engine/calculate-batch":        {target: "/ausd-engine/calculate-batch?amounts=decimal", body: `{"address":"` + testAddress + `","steps":[{"type":"deposit","tokenAddress":"` + testAddress + `","amount":"0.5"},{"type":"mint","amount":"1"}]}`},
+		"POST /ausd-engine/solve/max-mint":         {target: "/ausd-engine/solve/max-mint", body: `{"address":"` + testAddress + `","targetHealthFactor":"1.25"}`},
+		"POST /ausd-engine/solve/max-redeem":       {target: "/ausd-engine/solve/max-redeem", body: `{"address":"` + testAddress + `","tokenAddress":"` + testAddress + `","targetHealthFactor":"1.5"}`},
+		"POST /ausd-engine/solve/required-deposit": {target: "/ausd-engine/solve/required-deposit", body: `{"address":"` + testAddress + `","tokenAddress":"` + testAddress + `","targetHealthFactor":"2"}`},
+		"POST /ausd-engine/calculate-liquidation":  {target: "/ausd-engine/calculate-liquidation", body: `{"user":"` + testAddress + `","collateralToken":"` + testAddress + `","debtToCover":"1"}`},
+	}
+
+	for _, r := range routes {
+		name := r.Method + " " + r.Path
+		if r.Path == "/ws" || r.Path == "/events/stream" {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			tt, ok := tests[name]
+			require.True(t, ok, "route has no drift test case")
+
+			status := r.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			request := httptest.NewRequest(r.Method, apiBasePath+tt.target, strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			if tt.owner {
+				signAlertOwner(t, request)
+			}
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, request)
+
+			require.Equal(t, status, recorder.Code, recorder.Body.String())
+			assert.NoError(t, document.ValidateResponse(r.Method, openapi.SpecPath(r.Path), recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.Bytes()))
+		})
+	}
+}
+
+func TestErrorResponsesMatchOpenAPIDocument(t *testing.T) {
+	engine, routes := newTestAPI()
+	document := apiDocument(routes)
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, apiBasePath+"/user/not-an-address", nil))
+
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.NoError(t, document.ValidateResponse(http.MethodGet, "/user/{user}", recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.Bytes()))
+}
+
+func TestAlertRoutesRequireOwnerSignature(t *testing.T) {
+	engine, _ := newTestAPI()
+
+	tests := map[string]func(request *http.Request){
+		"unsigned": func(request *http.Request) {},
+		"signed by another address": func(request *http.Request) {
+			signAlertOwner(t, request)
+			request.URL.Path = apiBasePath + "/alerts/" + testAddress
+		},
+		"expired": func(request *http.Request) {
+			signAlertOwner(t, request)
+			request.Header.Set(handlers.AlertOwnerTimestampHeader, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+		},
+	}
+
+	for name, prepare := range tests {
+		t.Run(name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, apiBasePath+"/alerts/"+testOwnerAddress, nil)
+			prepare(request)
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, request)
+
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), string(model.ErrorCodeUnauthorized))
+		})
+	}
+}
+
+func TestOpenAPIDocumentCoversModels(t *testing.T) {
+	_, routes := newTestAPI()
+	document := apiDocument(routes)
+
+	for _, name := range []string{"ErrorResponse", "RealtimeEvent", "RealtimeClientMessage", "AlertPayload", "StatementRow"} {
+		assert.Contains(t, document.Components.Schemas, name)
+	}
+	for _, r := range routes {
+		assert.Contains(t, document.Paths, openapi.SpecPath(r.Path))
+	}
+}
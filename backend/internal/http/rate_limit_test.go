@@ -0,0 +1,135 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRateLimitStore counts windows in memory. Windows never expire, which is enough for a single test.
+type fakeRateLimitStore struct {
+	mu     sync.Mutex
+	counts map[string]int64
+	err    error
+}
+
+func newFakeRateLimitStore() *fakeRateLimitStore {
+	return &fakeRateLimitStore{counts: map[string]int64{}}
+}
+
+func (s *fakeRateLimitStore) IncrementWindow(key string, window time.Duration) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return 0, 0, s.err
+	}
+	s.counts[key]++
+	return s.counts[key], window, nil
+}
+
+var testRateLimitPolicy = middlewares.RateLimitPolicy{
+	IP:     middlewares.RateLimit{Requests: 2, Window: time.Minute},
+	APIKey: middlewares.RateLimit{Requests: 4, Window: time.Minute},
+}
+
+// newRateLimitedEngine serves /default with the default policy and /expensive with a one-request override,
+// counting how many requests reach the handlers.
+func newRateLimitedEngine(store middlewares.RateLimitStore) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	limiter := middlewares.NewRateLimiter(store, testRateLimitPolicy, []string{"secret"})
+	expensive := &middlewares.RateLimitPolicy{
+		IP:     middlewares.RateLimit{Requests: 1, Window: time.Minute},
+		APIKey: middlewares.RateLimit{Requests: 1, Window: time.Minute},
+	}
+
+	handled := 0
+	handler := func(c *gin.Context) {
+		handled++
+		c.Status(http.StatusNoContent)
+	}
+
+	engine := gin.New()
+	engine.Use(middlewares.RequestIDMiddleware())
+	engine.GET("/default", limiter.Middleware("default", nil), handler)
+	engine.GET("/expensive", limiter.Middleware("expensive", expensive), handler)
+	return engine, &handled
+}
+
+func serveRateLimited(engine *gin.Engine, target string, apiKey string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	if apiKey != "" {
+		request.Header.Set(middlewares.APIKeyHeader, apiKey)
+	}
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRateLimitAbortsOverLimit(t *testing.T) {
+	engine, handled := newRateLimitedEngine(newFakeRateLimitStore())
+
+	first := serveRateLimited(engine, "/default", "")
+	assert.Equal(t, http.StatusNoContent, first.Code)
+	assert.Equal(t, "2", first.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", first.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", first.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=60", first.Header().Get("RateLimit-Policy"))
+	assert.Empty(t, first.Header().Get("Retry-After"))
+
+	serveRateLimited(engine, "/default", "")
+	limited := serveRateLimited(engine, "/default", "")
+
+	require.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "0", limited.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", limited.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":{"code":"RATE_LIMITED","message":"too many requests","requestId":"`+limited.Header().Get(middlewares.RequestIDHeader)+`"}}`, limited.Body.String())
+	assert.Equal(t, 2, *handled)
+}
+
+func TestRateLimitCountsAPIKeysSeparately(t *testing.T) {
+	engine, _ := newRateLimitedEngine(newFakeRateLimitStore())
+
+	serveRateLimited(engine, "/default", "")
+	serveRateLimited(engine, "/default", "")
+	require.Equal(t, http.StatusTooManyRequests, serveRateLimited(engine, "/default", "").Code)
+
+	keyed := serveRateLimited(engine, "/default", "secret")
+	assert.Equal(t, http.StatusNoContent, keyed.Code)
+	assert.Equal(t, "4", keyed.Header().Get("RateLimit-Limit"))
+
+	// An unknown key is not a credential, so it shares the IP's window.
+	assert.Equal(t, http.StatusTooManyRequests, serveRateLimited(engine, "/default", "guess").Code)
+}
+
+func TestRateLimitOverrideCountsInOwnWindow(t *testing.T) {
+	engine, _ := newRateLimitedEngine(newFakeRateLimitStore())
+
+	assert.Equal(t, http.StatusNoContent, serveRateLimited(engine, "/expensive", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serveRateLimited(engine, "/expensive", "").Code)
+
+	fallback := serveRateLimited(engine, "/default", "")
+	assert.Equal(t, http.StatusNoContent, fallback.Code)
+	assert.Equal(t, "1", fallback.Header().Get("RateLimit-Remaining"))
+}
+
+func TestRateLimitAllowsRequestsWhenStoreFails(t *testing.T) {
+	store := newFakeRateLimitStore()
+	store.err = errors.New("connection refused")
+	engine, handled := newRateLimitedEngine(store)
+
+	for range 3 {
+		recorder := serveRateLimited(engine, "/default", "")
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		assert.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+	}
+	assert.Equal(t, 3, *handled)
+}
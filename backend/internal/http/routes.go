@@ -1,20 +1,453 @@
 package http
 
 import (
+	"os"
+	"strings"
+	"time"
+
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/handlers"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/middlewares"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/openapi"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	apiVersion  = "1.0.0"
+	apiBasePath = "/api/v1"
+)
+
+// route pairs a handler with its OpenAPI description, so the served document cannot list an endpoint
+// that is not registered or miss one that is. rateLimit replaces the default limits for the route.
+type route struct {
+	openapi.Endpoint
+	handler   gin.HandlerFunc
+	rateLimit *middlewares.RateLimitPolicy
+}
+
+var (
+	userParam    = openapi.Param{Name: "user", Description: "User address"}
+	addressParam = openapi.Param{Name: "address", Description: "Liquidator address"}
+	idParam      = openapi.Param{Name: "id", Description: "Alert subscription ID", Type: "integer"}
+
+	fromParam  = openapi.Param{Name: "from", Description: "Start time, RFC 3339 or Unix seconds"}
+	toParam    = openapi.Param{Name: "to", Description: "End time, RFC 3339 or Unix seconds"}
+	limitParam = openapi.Param{Name: "limit", Description: "Maximum number of items", Type: "integer"}
+
+	// alertOwnerParams prove the caller owns the address whose alert subscriptions it manages.
+	alertOwnerParams = []openapi.Param{
+		{Name: handlers.AlertOwnerSignatureHeader, Description: "personal_sign signature of \"AnchorUSD alerts\\nAddress: <checksummed address>\\nTimestamp: <timestamp>\"", Required: true},
+		{Name: handlers.AlertOwnerTimestampHeader, Description: "Unix seconds, at most 5 minutes from now", Type: "integer", Required: true},
+	}
+
+	// expensiveRateLimit guards routes that scan every position or stream a user's whole history.
+	expensiveRateLimit = &middlewares.RateLimitPolicy{
+		IP:     middlewares.RateLimit{Requests: 10, Window: time.Minute},
+		APIKey: middlewares.RateLimit{Requests: 60, Window: time.Minute},
+	}
+
+	amountsParam = openapi.Param{Name: "amounts", Description: "wei (default), or decimal to also write every amount under formatted with its decimals and symbol"}
+
+	historyQueryParams = []openapi.Param{
+		{Name: "cursor", Description: "Opaque cursor returned as nextCursor by the previous page"},
+		limitParam,
+		{Name: "type", Description: "Comma-separated transaction types: deposit, redeem, mint, burn, liquidation"},
+		{Name: "token", Description: "Collateral token address"},
+		{Name: "fromBlock", Description: "First block, inclusive", Type: "integer"},
+		{Name: "toBlock", Description: "Last block, inclusive", Type: "integer"},
+		fromParam,
+		toParam,
+	}
+)
+
+// realtimeModels are documented alongside the routes although no endpoint returns them as a body:
+// they are WebSocket messages, event payloads and webhook bodies.
+var realtimeModels = []any{
+	model.RealtimeClientMessage{},
+	model.RealtimeServerMessage{},
+	model.HealthFactorUpdate{},
+	model.PricesUpdate{},
+	model.DashboardUpdate{},
+	model.ProtocolEventUpdate{},
+	model.LiquidationTransition{},
+	model.AlertPayload{},
+}
+
+func apiRoutes(
+	userDataSvc handlers.UserReader,
+	hfCalcSvc handlers.HealthFactorCalculator,
+	dashboardMetricsSvc handlers.DashboardMetricsReader,
+	historySvc handlers.HistoryReader,
+	hfHistorySvc handlers.HealthFactorHistoryReader,
+	liquidationTransitionsSvc handlers.LiquidationTransitionsReader,
+	liquidationOpportunitiesSvc handlers.LiquidationOpportunitiesReader,
+	liquidatorsSvc handlers.LiquidatorsReader,
+	dailyStatsSvc handlers.DailyStatsReader,
+	riskSvc handlers.RiskAnalyzer,
+	alertsSvc handlers.AlertsManager,
+	realtimeHub handlers.RealtimeHub,
+) []route {
+	return []route{
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/ws", OperationID: "connectWebSocket", Tag: "realtime",
+				Summary:     "Open a realtime WebSocket",
+				Description: "Upgrades to a WebSocket. Clients send RealtimeClientMessage frames and receive RealtimeServerMessage and RealtimeEvent frames.",
+				Status:      101,
+			},
+			handler: handlers.WebSocketHandler(realtimeHub),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/events/stream", OperationID: "streamEvents", Tag: "realtime",
+				Summary: "Stream realtime events over Server-Sent Events",
+				QueryParams: []openapi.Param{
+					{Name: "channels", Description: "Comma-separated channels to subscribe to", Required: true},
+					{Name: "lastEventId", Description: "Resume after this event ID when the Last-Event-ID header is not sent", Type: "integer"},
+				},
+				Content: map[string]any{openapi.ContentTypeEventStream: model.RealtimeEvent{}},
+			},
+			handler: handlers.EventStreamHandler(realtimeHub),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/metrics/dashboard", OperationID: "getDashboardMetrics", Tag: "metrics",
+				Summary:  "Get protocol-wide dashboard metrics",
+				Response: model.DashboardMetrics{},
+			},
+			handler: handlers.GetDashboardMetricsHandler(dashboardMetricsSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/user/:user", OperationID: "getUserData", Tag: "users",
+				Summary:    "Get a user's position",
+				PathParams: []openapi.Param{userParam},
+				Response:   model.UserData{},
+			},
+			handler: handlers.GetUserDataHandler(userDataSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/user/:user/health-factor/history", OperationID: "getHealthFactorHistory", Tag: "users",
+				Summary:    "Get a user's health factor history",
+				PathParams: []openapi.Param{userParam},
+				QueryParams: []openapi.Param{
+					fromParam,
+					toParam,
+					{Name: "resolution", Description: "'raw' or a bucket duration such as 5m or 1h"},
+				},
+				Response: model.HealthFactorHistory{},
+			},
+			handler: handlers.GetHealthFactorHistoryHandler(hfHistorySvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/history/:user", OperationID: "getHistory", Tag: "history",
+				Summary:    "Get a user's full transaction history grouped by kind",
+				PathParams: []openapi.Param{userParam},
+				Response:   model.HistoryData{},
+			},
+			handler: handlers.GetHistoryHandler(historySvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/history/:user/transactions", OperationID: "getHistoryTransactions", Tag: "history",
+				Summary:     "Page through a user's transactions",
+				PathParams:  []openapi.Param{userParam},
+				QueryParams: historyQueryParams,
+				Response:    model.HistoryPage{},
+			},
+			handler: handlers.GetHistoryPageHandler(historySvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/history/:user/operations", OperationID: "getHistoryOperations", Tag: "history",
+				Summary:     "Page through a user's transactions grouped by transaction hash",
+				PathParams:  []openapi.Param{userParam},
+				QueryParams: historyQueryParams,
+				Response:    model.HistoryOperationsPage{},
+			},
+			handler: handlers.GetHistoryOperationsHandler(historySvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/history/:user/export", OperationID: "exportHistory", Tag: "history",
+				Summary:    "Export a user's account statement with USD values",
+				PathParams: []openapi.Param{userParam},
+				QueryParams: []openapi.Param{
+					{Name: "format", Description: "csv (default) or json"},
+					fromParam,
+					toParam,
+				},
+				Content: map[string]any{
+					openapi.ContentTypeCSV:  "",
+					openapi.ContentTypeJSON: []model.StatementRow{},
+				},
+			},
+			handler:   handlers.ExportHistoryHandler(historySvc),
+			rateLimit: expensiveRateLimit,
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/liquidations/transitions", OperationID: "getLiquidationTransitions", Tag: "liquidations",
+				Summary: "List users crossing the liquidation threshold",
+				QueryParams: []openapi.Param{
+					fromParam,
+					toParam,
+					limitParam,
+					{Name: "address", Description: "Only transitions of this user"},
+				},
+				Response: model.LiquidationTransitions{},
+			},
+			handler: handlers.GetLiquidationTransitionsHandler(liquidationTransitionsSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/liquidations/opportunities", OperationID: "getLiquidationOpportunities", Tag: "liquidations",
+				Summary:  "List currently liquidatable positions",
+				Response: model.LiquidationOpportunities{},
+			},
+			handler:   handlers.GetLiquidationOpportunitiesHandler(liquidationOpportunitiesSvc),
+			rateLimit: expensiveRateLimit,
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/liquidators/leaderboard", OperationID: "getLiquidatorLeaderboard", Tag: "liquidators",
+				Summary: "Rank liquidators by value liquidated",
+				QueryParams: []openapi.Param{
+					{Name: "window", Description: "'all' or a duration such as 24h, 7d or 30d"},
+					limitParam,
+				},
+				Response: model.LiquidatorLeaderboard{},
+			},
+			handler: handlers.GetLiquidatorLeaderboardHandler(liquidatorsSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/liquidators/:address", OperationID: "getLiquidatorSummary", Tag: "liquidators",
+				Summary:    "Get a liquidator's totals and recent liquidations",
+				PathParams: []openapi.Param{addressParam},
+				Response:   model.LiquidatorSummary{},
+			},
+			handler: handlers.GetLiquidatorSummaryHandler(liquidatorsSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/stats/daily", OperationID: "getDailyStats", Tag: "stats",
+				Summary:     "Get daily protocol stats",
+				QueryParams: []openapi.Param{fromParam, toParam},
+				Response:    model.DailyStats{},
+			},
+			handler: handlers.GetDailyStatsHandler(dailyStatsSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "POST", Path: "/risk/stress-test", OperationID: "runStressTest", Tag: "risk",
+				Summary:  "Project positions under collateral price shocks",
+				Request:  model.StressTestRequest{},
+				Response: model.StressTestResult{},
+			},
+			handler:   handlers.StressTestHandler(riskSvc),
+			rateLimit: expensiveRateLimit,
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "POST", Path: "/alerts", OperationID: "createAlertSubscription", Tag: "alerts",
+				Summary:      "Subscribe a webhook to health factor alerts",
+				Request:      model.CreateAlertSubscriptionRequest{},
+				HeaderParams: alertOwnerParams,
+				Response:     model.CreatedAlertSubscription{},
+				Status:       201,
+			},
+			handler: handlers.CreateAlertSubscriptionHandler(alertsSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/alerts/:user", OperationID: "getAlertSubscriptions", Tag: "alerts",
+				Summary:      "List a user's alert subscriptions",
+				PathParams:   []openapi.Param{userParam},
+				HeaderParams: alertOwnerParams,
+				Response:     []model.AlertSubscription{},
+			},
+			handler: handlers.GetAlertSubscriptionsHandler(alertsSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "DELETE", Path: "/alerts/:user/:id", OperationID: "deleteAlertSubscription", Tag: "alerts",
+				Summary:      "Delete an alert subscription",
+				PathParams:   []openapi.Param{userParam, idParam},
+				HeaderParams: alertOwnerParams,
+				Status:       204,
+			},
+			handler: handlers.DeleteAlertSubscriptionHandler(alertsSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "GET", Path: "/alerts/:user/:id/deliveries", OperationID: "getAlertDeliveries", Tag: "alerts",
+				Summary:      "List recent webhook deliveries of an alert subscription",
+				PathParams:   []openapi.Param{userParam, idParam},
+				QueryParams:  []openapi.Param{limitParam},
+				HeaderParams: alertOwnerParams,
+				Response:     []model.AlertDelivery{},
+			},
+			handler: handlers.GetAlertDeliveriesHandler(alertsSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "POST", Path: "/ausd-engine/calculate-mint", OperationID: "calculateMint", Tag: "ausd-engine",
+				Summary:     "Project the health factor after minting",
+				QueryParams: []openapi.Param{amountsParam},
+				Request:     model.CalculateMintRequest{},
+				Response:    model.HealthFactorProjection{},
+			},
+			handler: handlers.CalculateMintHandler(hfCalcSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "POST", Path: "/ausd-engine/calculate-burn", OperationID: "calculateBurn", Tag: "ausd-engine",
+				Summary:     "Project the health factor after burning",
+				QueryParams: []openapi.Param{amountsParam},
+				Request:     model.CalculateBurnRequest{},
+				Response:    model.HealthFactorProjection{},
+			},
+			handler: handlers.CalculateBurnHandler(hfCalcSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "POST", Path: "/ausd-engine/calculate-deposit", OperationID: "calculateDeposit", Tag: "ausd-engine",
+				Summary:     "Project the health factor after depositing collateral",
+				QueryParams: []openapi.Param{amountsParam},
+				Request:     model.CalculateDepositRequest{},
+				Response:    model.HealthFactorProjection{},
+			},
+			handler: handlers.CalculateDepositHandler(hfCalcSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "POST", Path: "/ausd-engine/calculate-redeem", OperationID: "calculateRedeem", Tag: "ausd-engine",
+				Summary:     "Project the health factor after redeeming collateral",
+				QueryParams: []openapi.Param{amountsParam},
+				Request:     model.CalculateRedeemRequest{},
+				Response:    model.HealthFactorProjection{},
+			},
+			handler: handlers.CalculateRedeemHandler(hfCalcSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "POST", Path: "/ausd-engine/calculate-liquidation", OperationID: "calculateLiquidation", Tag: "ausd-engine",
+				Summary:     "Project the outcome of liquidating a user",
+				QueryParams: []openapi.Param{amountsParam},
+				Request:     model.CalculateLiquidationRequest{},
+				Response:    model.LiquidationProjection{},
+			},
+			handler: handlers.CalculateLiquidationHandler(hfCalcSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "POST", Path: "/ausd-engine/calculate-batch", OperationID: "calculateBatch", Tag: "ausd-engine",
+				Summary:     "Project an ordered list of deposits, redeems, mints and burns",
+				Description: "Returns the health factor after each step and stops at the first step the engine would revert on.",
+				QueryParams: []openapi.Param{amountsParam},
+				Request:     model.CalculateBatchRequest{},
+				Response:    model.BatchProjection{},
+			},
+			handler: handlers.CalculateBatchHandler(hfCalcSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "POST", Path: "/ausd-engine/solve/max-mint", OperationID: "solveMaxMint", Tag: "ausd-engine",
+				Summary:     "Find the most AUSD that can be minted while keeping a target health factor",
+				QueryParams: []openapi.Param{amountsParam},
+				Request:     model.SolveMintRequest{},
+				Response:    model.SolverResult{},
+			},
+			handler: handlers.SolveMaxMintHandler(hfCalcSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "POST", Path: "/ausd-engine/solve/max-redeem", OperationID: "solveMaxRedeem", Tag: "ausd-engine",
+				Summary:     "Find the most collateral that can be redeemed while keeping a target health factor",
+				QueryParams: []openapi.Param{amountsParam},
+				Request:     model.SolveCollateralRequest{},
+				Response:    model.SolverResult{},
+			},
+			handler: handlers.SolveMaxRedeemHandler(hfCalcSvc),
+		},
+		{
+			Endpoint: openapi.Endpoint{
+				Method: "POST", Path: "/ausd-engine/solve/required-deposit", OperationID: "solveRequiredDeposit", Tag: "ausd-engine",
+				Summary:     "Find the least collateral to deposit to reach a target health factor",
+				QueryParams: []openapi.Param{amountsParam},
+				Request:     model.SolveCollateralRequest{},
+				Response:    model.SolverResult{},
+			},
+			handler: handlers.SolveRequiredDepositHandler(hfCalcSvc),
+		},
+	}
+}
+
+func apiDocument(routes []route) *openapi.Document {
+	endpoints := make([]openapi.Endpoint, 0, len(routes))
+	for _, r := range routes {
+		endpoints = append(endpoints, r.Endpoint)
+	}
+
+	info := openapi.Info{
+		Title:       "AnchorUSD API",
+		Version:     apiVersion,
+		Description: "Read models and projections over the AnchorUSD protocol. Errors use the ErrorResponse envelope. Requests are rate limited per IP, or per key when a known API key is sent in X-API-Key; limits are reported in RateLimit-* headers.",
+	}
+	return openapi.Build(info, apiBasePath, endpoints, model.ErrorResponse{}, realtimeModels...)
+}
+
+func registerAPI(api *gin.RouterGroup, routes []route, limiter *middlewares.RateLimiter) {
+	logger := utils.GetLogger()
+
+	v1 := api.Group("/v1")
+	for _, r := range routes {
+		v1.Handle(r.Method, r.Path, limiter.Middleware(r.OperationID, r.rateLimit), r.handler)
+		logger.Debug().Str("method", r.Method).Msgf("Registered %s%s route", apiBasePath, r.Path)
+	}
+
+	document := apiDocument(routes)
+	api.GET("/openapi.json", limiter.Middleware("getOpenAPIDocument", nil), func(c *gin.Context) {
+		c.JSON(200, document)
+	})
+	logger.Debug().Msg("Registered /api/openapi.json route")
+}
+
 func RegisterRoutes(
 	userDataSvc handlers.UserReader,
 	hfCalcSvc handlers.HealthFactorCalculator,
 	dashboardMetricsSvc handlers.DashboardMetricsReader,
 	historySvc handlers.HistoryReader,
+	hfHistorySvc handlers.HealthFactorHistoryReader,
+	liquidationTransitionsSvc handlers.LiquidationTransitionsReader,
+	liquidationOpportunitiesSvc handlers.LiquidationOpportunitiesReader,
+	liquidatorsSvc handlers.LiquidatorsReader,
+	dailyStatsSvc handlers.DailyStatsReader,
+	riskSvc handlers.RiskAnalyzer,
+	alertsSvc handlers.AlertsManager,
+	realtimeHub handlers.RealtimeHub,
+	rateLimitStore middlewares.RateLimitStore,
 ) {
 	logger := utils.GetLogger()
 	logger.Info().Msg("Registering HTTP routes")
 
+	// Rate limits are counted per client IP, so behind a load balancer only its X-Forwarded-For
+	// should be trusted. Without TRUSTED_PROXIES the header is ignored and the peer address is used,
+	// so clients cannot pick their own rate limit bucket.
+	var trustedProxies []string
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		trustedProxies = strings.Split(proxies, ",")
+	}
+	if err := server.SetTrustedProxies(trustedProxies); err != nil {
+		logger.Fatal().Err(err).Strs("proxies", trustedProxies).Msg("Invalid TRUSTED_PROXIES")
+	}
+
+	limiter := middlewares.NewRateLimiter(rateLimitStore, middlewares.RateLimitPolicyFromEnv(), middlewares.APIKeysFromEnv())
+
 	api := server.Group("/api")
 
 	api.GET("/status", func(c *gin.Context) {
@@ -23,26 +456,20 @@ func RegisterRoutes(
 	})
 	logger.Debug().Msg("Registered /api/status route")
 
-	metrics := api.Group("/metrics")
-	{
-		metrics.GET("/dashboard", handlers.GetDashboardMetricsHandler(dashboardMetricsSvc))
-	}
-	logger.Debug().Msg("Registered /api/metrics routes")
-
-	api.GET("/user/:user", handlers.GetUserDataHandler(userDataSvc))
-	logger.Debug().Msg("Registered /api/user/:user route")
-
-	api.GET("/history/:user", handlers.GetHistoryHandler(historySvc))
-	logger.Debug().Msg("Registered /api/history/:user route")
-
-	ausdEngine := api.Group("/ausd-engine")
-	{
-		ausdEngine.POST("/calculate-mint", handlers.CalculateMintHandler(hfCalcSvc))
-		ausdEngine.POST("/calculate-burn", handlers.CalculateBurnHandler(hfCalcSvc))
-		ausdEngine.POST("/calculate-deposit", handlers.CalculateDepositHandler(hfCalcSvc))
-		ausdEngine.POST("/calculate-redeem", handlers.CalculateRedeemHandler(hfCalcSvc))
-	}
-	logger.Debug().Msg("Registered /api/ausd-engine routes")
+	registerAPI(api, apiRoutes(
+		userDataSvc,
+		hfCalcSvc,
+		dashboardMetricsSvc,
+		historySvc,
+		hfHistorySvc,
+		liquidationTransitionsSvc,
+		liquidationOpportunitiesSvc,
+		liquidatorsSvc,
+		dailyStatsSvc,
+		riskSvc,
+		alertsSvc,
+		realtimeHub,
+	), limiter)
 
 	logger.Info().Msg("All HTTP routes registered successfully")
 }
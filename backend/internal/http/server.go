@@ -1,35 +1,80 @@
 package http
 
 import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/middlewares"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"golang.org/x/time/rate"
 )
 
+const shutdownTimeout = 10 * time.Second
+
 var server *gin.Engine
 
 func init() {
 	logger := utils.GetLogger()
 	logger.Info().Msg("Initializing Gin HTTP server")
 	server = gin.Default()
+	server.Use(middlewares.RequestIDMiddleware())
 	server.Use(middlewares.CORSMiddleware())
 	server.Use(middlewares.PrometheusMiddleware())
 
-	limiter := rate.NewLimiter(2, 10)
-	server.Use(middlewares.RateLimitMiddleware(limiter))
-
 	server.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	logger.Info().Msg("Prometheus metrics endpoint registered at /metrics")
 }
 
-func Run(addr string) error {
+// Run serves HTTP until SIGINT or SIGTERM. The shutdown hooks run before in-flight requests are drained,
+// which lets long-lived connections such as WebSockets close cleanly instead of being cut.
+func Run(addr string, shutdownHooks ...func()) error {
 	logger := utils.GetLogger()
 	logger.Info().Str("address", addr).Msg("Starting HTTP server")
-	err := server.Run(addr)
-	if err != nil {
+
+	httpServer := &nethttp.Server{
+		Addr:    addr,
+		Handler: server,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	select {
+	case err := <-serveErr:
 		logger.Fatal().Err(err).Str("address", addr).Msg("Failed to start HTTP server")
+		return err
+	case sig := <-stop:
+		logger.Info().Str("signal", sig.String()).Msg("Shutting down HTTP server")
 	}
-	return err
+
+	for _, hook := range shutdownHooks {
+		hook()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		logger.Error().Err(err).Msg("HTTP server did not shut down cleanly")
+		return err
+	}
+
+	if err := <-serveErr; err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+		return err
+	}
+
+	logger.Info().Msg("HTTP server stopped")
+	return nil
 }
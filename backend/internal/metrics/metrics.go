@@ -110,6 +110,13 @@ var (
 		},
 	)
 
+	BadDebtUSD = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "ausd_bad_debt_usd",
+			Help: "Debt in USD not covered by the collateral of insolvent users",
+		},
+	)
+
 	LiquidationsTotal = promauto.NewCounter(
 		prometheus.CounterOpts{
 			Name: "ausd_liquidations_total",
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrInvalidAddress = errors.New("invalid address")
+
+// ParseAddress validates a hex address and returns its checksummed form, which is how the indexer
+// writes addresses into cache fields and database rows. Any casing is accepted.
+func ParseAddress(value string) (string, error) {
+	if !common.IsHexAddress(value) {
+		return "", fmt.Errorf("%w %q", ErrInvalidAddress, value)
+	}
+	return common.HexToAddress(value).Hex(), nil
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAlertSubscriptionNotFound = errors.New("alert subscription not found")
+	ErrWebhookNotAllowed         = errors.New("webhook host not allowed")
+)
+
+type AlertDeliveryStatus string
+
+const (
+	AlertDeliveryPending   AlertDeliveryStatus = "pending"
+	AlertDeliveryDelivered AlertDeliveryStatus = "delivered"
+	AlertDeliveryFailed    AlertDeliveryStatus = "failed"
+)
+
+type AlertSubscription struct {
+	ID              uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Address         string     `json:"address" gorm:"index;size:42;not null"`
+	WebhookURL      string     `json:"webhookUrl" gorm:"not null"`
+	Threshold       BigInt     `json:"threshold" gorm:"type:numeric(78,0);not null"`
+	Secret          string     `json:"-" gorm:"not null"`
+	Triggered       bool       `json:"triggered" gorm:"not null;default:false"`
+	LastTriggeredAt *time.Time `json:"lastTriggeredAt"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+type AlertDelivery struct {
+	ID             uint                `json:"id" gorm:"primaryKey;autoIncrement"`
+	SubscriptionID uint                `json:"subscriptionId" gorm:"index;not null"`
+	Address        string              `json:"address" gorm:"size:42;not null"`
+	HealthFactor   string              `json:"healthFactor" gorm:"type:numeric(78,0);not null"`
+	Payload        string              `json:"payload" gorm:"type:text;not null"`
+	Status         AlertDeliveryStatus `json:"status" gorm:"size:16;not null"`
+	Attempts       int                 `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int                 `json:"responseStatus"`
+	Error          string              `json:"error"`
+	CreatedAt      time.Time           `json:"createdAt"`
+	UpdatedAt      time.Time           `json:"updatedAt" gorm:"index"`
+	DeliveredAt    *time.Time          `json:"deliveredAt"`
+}
+
+type CreateAlertSubscriptionRequest struct {
+	Address    string `json:"address" binding:"required"`
+	WebhookURL string `json:"webhookUrl" binding:"required"`
+	Threshold  string `json:"threshold" binding:"required"`
+}
+
+// CreatedAlertSubscription is returned once on creation and is the only response that carries the signing secret.
+type CreatedAlertSubscription struct {
+	AlertSubscription
+	Secret string `json:"secret"`
+}
+
+// AlertPayload is the JSON body POSTed to the subscriber's webhook.
+type AlertPayload struct {
+	SubscriptionID uint   `json:"subscriptionId"`
+	Address        string `json:"address"`
+	HealthFactor   string `json:"healthFactor"`
+	Threshold      string `json:"threshold"`
+	CollateralUsd  string `json:"collateralUsd"`
+	Debt           string `json:"debt"`
+	Timestamp      string `json:"timestamp"`
+}
+
+// HealthFactorUpdate is published on the health factor channel every time a user's health factor is recomputed.
+type HealthFactorUpdate struct {
+	Address       string             `json:"address"`
+	HealthFactor  string             `json:"healthFactor"`
+	CollateralUsd string             `json:"collateralUsd"`
+	Debt          string             `json:"debt"`
+	Source        HealthFactorSource `json:"source"`
+	Timestamp     string             `json:"timestamp"`
+}
@@ -0,0 +1,11 @@
+package constants
+
+const LIQUIDATIONS_CHANNEL = "liquidations"
+
+const HEALTH_FACTORS_CHANNEL = "health_factors"
+
+const PRICES_CHANNEL = "prices"
+
+const DASHBOARD_CHANNEL = "dashboard"
+
+const PROTOCOL_EVENTS_CHANNEL = "protocol_events"
@@ -3,10 +3,15 @@ package constants
 import (
 	"os"
 	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 var CollateralTokens = map[string]string{}
 
+// LoadCollateralTokens reads the collateral names and addresses from the environment. Addresses are
+// stored checksummed, the form the indexer uses for event addresses, so lookups compare equal
+// whatever casing the configuration uses.
 func LoadCollateralTokens() {
 	collaterals := os.Getenv("COLLATERAL_TOKEN_ADDRESSES")
 	collateralsNames := os.Getenv("COLLATERAL_TOKEN_NAMES")
@@ -14,6 +19,10 @@ func LoadCollateralTokens() {
 	addresses := strings.Split(collaterals, ",")
 
 	for i, name := range strings.Split(collateralsNames, ",") {
-		CollateralTokens[name] = addresses[i]
+		address := strings.TrimSpace(addresses[i])
+		if common.IsHexAddress(address) {
+			address = common.HexToAddress(address).Hex()
+		}
+		CollateralTokens[name] = address
 	}
 }
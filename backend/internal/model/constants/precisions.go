@@ -13,3 +13,5 @@ var LIQUIDATION_DIVISOR = big.NewInt(2)
 var PERCENTAGE_MULTIPLIER = big.NewInt(10000)
 var PERCENTAGE_BASE_DIVISOR = 100.0
 var RISK_THRESHOLD = new(big.Int).Mul(big.NewInt(15), big.NewInt(1e17))
+var LIQUIDATION_BONUS = big.NewInt(10)
+var MAX_UINT256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
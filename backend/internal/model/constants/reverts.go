@@ -0,0 +1,12 @@
+package constants
+
+// AUSDEngine custom errors that the backend predicts when simulating engine calls.
+const (
+	REVERT_MUST_BE_MORE_THAN_ZERO     = "AUSDEngine__MustBeMoreThanZero"
+	REVERT_TOKEN_NOT_ALLOWED          = "AUSDEngine__TokenNotAllowed"
+	REVERT_HEALTH_FACTOR_OK           = "AUSDEngine__HealthFactorOk"
+	REVERT_HEALTH_FACTOR_BROKEN       = "AUSDEngine__HealthFactorBroken"
+	REVERT_INSUFFICIENT_COLLATERAL    = "AUSDEngine__InsufficientCollateral"
+	REVERT_BURN_AMOUNT_EXCEEDS_DEBT   = "AUSDEngine__BurnAmountExceedsDebt"
+	REVERT_HEALTH_FACTOR_NOT_IMPROVED = "AUSDEngine__HealthFactorNotImproved"
+)
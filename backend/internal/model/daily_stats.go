@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// DailyProtocolStats is the rollup of one UTC day. Volumes are in wei and TVL is 8 decimal USD.
+// LastBlockNumber is the latest indexed block the day was rolled up with.
+type DailyProtocolStats struct {
+	Day              time.Time `gorm:"primaryKey;type:date"`
+	MintVolume       BigInt    `gorm:"type:numeric(78,0);not null"`
+	BurnVolume       BigInt    `gorm:"type:numeric(78,0);not null"`
+	LiquidationCount int64     `gorm:"not null"`
+	NewUsers         int64     `gorm:"not null"`
+	TVLUSD           BigInt    `gorm:"column:tvl_usd;type:numeric(78,0);not null"`
+	LastBlockNumber  uint64    `gorm:"not null"`
+}
+
+// DailyCollateralStats holds the per collateral part of a day's rollup. Balance is the collateral
+// locked in the protocol at the end of the day.
+type DailyCollateralStats struct {
+	Day               time.Time `gorm:"primaryKey;type:date"`
+	CollateralAddress string    `gorm:"primaryKey;size:42"`
+	DepositVolume     BigInt    `gorm:"type:numeric(78,0);not null"`
+	RedeemVolume      BigInt    `gorm:"type:numeric(78,0);not null"`
+	LiquidatedAmount  BigInt    `gorm:"type:numeric(78,0);not null"`
+	Balance           BigInt    `gorm:"type:numeric(78,0);not null"`
+	TVLUSD            BigInt    `gorm:"column:tvl_usd;type:numeric(78,0);not null"`
+}
+
+// DailyActivity is what the indexed tables recorded during one day, before it is rolled up.
+type DailyActivity struct {
+	MintVolume       BigInt
+	BurnVolume       BigInt
+	LiquidationCount int64
+	NewUsers         int64
+	LastBlockNumber  uint64
+	Collateral       []DailyCollateralFlow `gorm:"-"`
+}
+
+type DailyCollateralFlow struct {
+	CollateralAddress string
+	DepositVolume     BigInt
+	RedeemVolume      BigInt
+	LiquidatedAmount  BigInt
+}
+
+type DailyStatsQuery struct {
+	From time.Time
+	To   time.Time
+}
+
+type DailyCollateralStat struct {
+	Asset            string `json:"asset"`
+	Token            string `json:"token"`
+	DepositVolume    string `json:"depositVolume"`
+	RedeemVolume     string `json:"redeemVolume"`
+	LiquidatedAmount string `json:"liquidatedAmount"`
+	Balance          string `json:"balance"`
+	TVLUSD           string `json:"tvlUsd"`
+}
+
+type DailyStat struct {
+	Day              string                `json:"day"`
+	MintVolume       string                `json:"mintVolume"`
+	BurnVolume       string                `json:"burnVolume"`
+	LiquidationCount int64                 `json:"liquidationCount"`
+	NewUsers         int64                 `json:"newUsers"`
+	TVLUSD           string                `json:"tvlUsd"`
+	Collateral       []DailyCollateralStat `json:"collateral"`
+}
+
+type DailyStats struct {
+	From string      `json:"from"`
+	To   string      `json:"to"`
+	Days []DailyStat `json:"days"`
+}
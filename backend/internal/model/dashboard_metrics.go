@@ -8,6 +8,14 @@ type LiquidatableUser struct {
 	LiquidationAmount string `json:"liquidationAmount"`
 }
 
+type InsolventPosition struct {
+	Address       string `json:"address"`
+	HealthFactor  string `json:"healthFactor"`
+	CollateralUsd string `json:"collateralUsd"`
+	DebtUsd       string `json:"debtUsd"`
+	BadDebt       string `json:"badDebt"`
+}
+
 type CollateralBreakdown struct {
 	Asset      string  `json:"asset"`
 	Amount     string  `json:"amount"`
@@ -34,8 +42,10 @@ type ProtocolHealth struct {
 }
 
 type DashboardMetrics struct {
-	LiquidatableUsers []LiquidatableUser `json:"liquidatableUsers"`
-	TotalCollateral   TotalCollateral    `json:"totalCollateral"`
-	StableSupply      StableSupply       `json:"stableSupply"`
-	ProtocolHealth    ProtocolHealth     `json:"protocolHealth"`
+	LiquidatableUsers  []LiquidatableUser  `json:"liquidatableUsers"`
+	InsolventPositions []InsolventPosition `json:"insolventPositions"`
+	BadDebt            string              `json:"badDebt"`
+	TotalCollateral    TotalCollateral     `json:"totalCollateral"`
+	StableSupply       StableSupply        `json:"stableSupply"`
+	ProtocolHealth     ProtocolHealth      `json:"protocolHealth"`
 }
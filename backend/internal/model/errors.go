@@ -0,0 +1,68 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnknownToken           = errors.New("unknown collateral token")
+	ErrInvalidAmount          = errors.New("invalid amount")
+	ErrInsufficientCollateral = errors.New("insufficient collateral")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrPriceUnavailable       = errors.New("price unavailable")
+	ErrStalePrice             = errors.New("stale price")
+	ErrUnauthorized           = errors.New("unauthorized")
+)
+
+// EngineError rejects a request AUSDEngine would revert on. Revert is the contract's custom error,
+// such as AUSDEngine__TokenNotAllowed, and Err the sentinel that classifies it for the API.
+type EngineError struct {
+	Revert string
+	Err    error
+	Detail string
+}
+
+func (e *EngineError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Revert, e.Detail)
+}
+
+func (e *EngineError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode identifies an API error independently of its message, so clients can branch on it.
+// Codes are part of the API contract and must not be renamed.
+type ErrorCode string
+
+const (
+	ErrorCodeInvalidRequest            ErrorCode = "INVALID_REQUEST"
+	ErrorCodeInvalidAddress            ErrorCode = "INVALID_ADDRESS"
+	ErrorCodeInvalidAmount             ErrorCode = "INVALID_AMOUNT"
+	ErrorCodeInvalidCursor             ErrorCode = "INVALID_CURSOR"
+	ErrorCodeUnknownToken              ErrorCode = "UNKNOWN_TOKEN"
+	ErrorCodeInsufficientCollateral    ErrorCode = "INSUFFICIENT_COLLATERAL"
+	ErrorCodeUserNotFound              ErrorCode = "USER_NOT_FOUND"
+	ErrorCodeAlertSubscriptionNotFound ErrorCode = "ALERT_SUBSCRIPTION_NOT_FOUND"
+	ErrorCodeWebhookNotAllowed         ErrorCode = "WEBHOOK_NOT_ALLOWED"
+	ErrorCodeUnauthorized              ErrorCode = "UNAUTHORIZED"
+	ErrorCodeRateLimited               ErrorCode = "RATE_LIMITED"
+	ErrorCodePriceUnavailable          ErrorCode = "PRICE_UNAVAILABLE"
+	ErrorCodeStalePrice                ErrorCode = "STALE_PRICE"
+	ErrorCodeServiceUnavailable        ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrorCodeInternal                  ErrorCode = "INTERNAL_ERROR"
+)
+
+// APIError describes a failed request. Revert is set when the request is one AUSDEngine would revert
+// on, and names the contract's custom error.
+type APIError struct {
+	Code      ErrorCode `json:"code"`
+	Message   string    `json:"message"`
+	Revert    string    `json:"revert,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+}
+
+// ErrorResponse is the body of every non-2xx API response.
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
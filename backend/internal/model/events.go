@@ -1,5 +1,7 @@
 package model
 
+// Events is an indexed contract log. CreatedAt is the block timestamp when the node reports it
+// with the log, and the indexing time otherwise.
 type Events struct {
 	ID          uint   `json:"id" gorm:"primaryKey"`
 	BlockNumber uint64 `json:"block_number" gorm:"block_number"`
@@ -1,29 +1,146 @@
 package model
 
+// AmountUnits selects how a projection writes its amounts. Amounts are always written in wei; with
+// AmountUnitsDecimal they are also repeated under formatted as decimals with their symbol.
+type AmountUnits string
+
+const (
+	AmountUnitsWei     AmountUnits = "wei"
+	AmountUnitsDecimal AmountUnits = "decimal"
+)
+
+// FormattedAmount is an amount written with its token's decimals, such as "1.5" ETH. USD values
+// have the symbol USD.
+type FormattedAmount struct {
+	Value  string `json:"value"`
+	Symbol string `json:"symbol"`
+}
+
+// CalculateMintRequest and the other calculate requests take amounts in wei, or in whole tokens when
+// they contain a decimal point, so "1500000000000000000" and "1.5" are the same amount. Units is read
+// from the amounts query parameter rather than the body.
 type CalculateMintRequest struct {
-	Address    string `json:"address" binding:"required"`
-	MintAmount string `json:"mintAmount" binding:"required"`
+	Address    string      `json:"address" binding:"required"`
+	MintAmount string      `json:"mintAmount" binding:"required"`
+	Units      AmountUnits `json:"-"`
 }
 
 type CalculateBurnRequest struct {
-	Address    string `json:"address" binding:"required"`
-	BurnAmount string `json:"burnAmount" binding:"required"`
+	Address    string      `json:"address" binding:"required"`
+	BurnAmount string      `json:"burnAmount" binding:"required"`
+	Units      AmountUnits `json:"-"`
 }
 
 type CalculateDepositRequest struct {
-	Address       string `json:"address" binding:"required"`
-	TokenAddress  string `json:"tokenAddress" binding:"required"`
-	DepositAmount string `json:"depositAmount" binding:"required"`
+	Address       string      `json:"address" binding:"required"`
+	TokenAddress  string      `json:"tokenAddress" binding:"required"`
+	DepositAmount string      `json:"depositAmount" binding:"required"`
+	Units         AmountUnits `json:"-"`
 }
 
 type CalculateRedeemRequest struct {
-	Address       string `json:"address" binding:"required"`
-	TokenAddress  string `json:"tokenAddress" binding:"required"`
-	RedeemAmount string `json:"redeemAmount" binding:"required"`
+	Address      string      `json:"address" binding:"required"`
+	TokenAddress string      `json:"tokenAddress" binding:"required"`
+	RedeemAmount string      `json:"redeemAmount" binding:"required"`
+	Units        AmountUnits `json:"-"`
+}
+
+type CalculateLiquidationRequest struct {
+	User            string      `json:"user" binding:"required"`
+	CollateralToken string      `json:"collateralToken" binding:"required"`
+	DebtToCover     string      `json:"debtToCover" binding:"required"`
+	Units           AmountUnits `json:"-"`
+}
+
+type LiquidationProjection struct {
+	WillRevert            bool                       `json:"willRevert"`
+	RevertReason          string                     `json:"revertReason,omitempty"`
+	HealthFactorBefore    string                     `json:"healthFactorBefore"`
+	HealthFactorAfter     string                     `json:"healthFactorAfter"`
+	CollateralSeized      string                     `json:"collateralSeized"`
+	BonusCollateral       string                     `json:"bonusCollateral"`
+	CollateralSeizedUsd   string                     `json:"collateralSeizedUsd"`
+	LiquidatorProfitUsd   string                     `json:"liquidatorProfitUsd"`
+	NewDebt               string                     `json:"newDebt"`
+	NewCollateralValueUsd string                     `json:"newCollateralValueUsd"`
+	Formatted             map[string]FormattedAmount `json:"formatted,omitempty"`
 }
 
 type HealthFactorProjection struct {
-	HealthFactorAfter  string `json:"healthFactorAfter"`
-	NewDebt            string `json:"newDebt"`
-	NewCollateralValue string `json:"newCollateralValue"`
+	HealthFactorAfter  string                     `json:"healthFactorAfter"`
+	NewDebt            string                     `json:"newDebt"`
+	NewCollateralValue string                     `json:"newCollateralValue"`
+	Formatted          map[string]FormattedAmount `json:"formatted,omitempty"`
+}
+
+// BatchStep is one engine call in a batch projection. TokenAddress is required for deposits and
+// redeems and ignored for mints and burns.
+type BatchStep struct {
+	Type         TransactionType `json:"type" binding:"required"`
+	TokenAddress string          `json:"tokenAddress,omitempty"`
+	Amount       string          `json:"amount" binding:"required"`
+}
+
+type CalculateBatchRequest struct {
+	Address string      `json:"address" binding:"required"`
+	Steps   []BatchStep `json:"steps" binding:"required"`
+	Units   AmountUnits `json:"-"`
+}
+
+type BatchStepProjection struct {
+	Type               TransactionType            `json:"type"`
+	Token              string                     `json:"token,omitempty"`
+	Amount             string                     `json:"amount"`
+	WillRevert         bool                       `json:"willRevert"`
+	RevertReason       string                     `json:"revertReason,omitempty"`
+	HealthFactorBefore string                     `json:"healthFactorBefore"`
+	HealthFactorAfter  string                     `json:"healthFactorAfter"`
+	DebtAfter          string                     `json:"debtAfter"`
+	CollateralUsdAfter string                     `json:"collateralUsdAfter"`
+	Formatted          map[string]FormattedAmount `json:"formatted,omitempty"`
+}
+
+type BatchPosition struct {
+	Collateral    []CollateralDeposited      `json:"collateral"`
+	CollateralUsd string                     `json:"collateralUsd"`
+	Debt          string                     `json:"debt"`
+	HealthFactor  string                     `json:"healthFactor"`
+	Formatted     map[string]FormattedAmount `json:"formatted,omitempty"`
+}
+
+// BatchProjection lists the steps up to and including the first one that would revert. RevertStep
+// is that step's index, and FinalPosition is the position after the last step that succeeds.
+type BatchProjection struct {
+	Steps         []BatchStepProjection `json:"steps"`
+	WillRevert    bool                  `json:"willRevert"`
+	RevertStep    *int                  `json:"revertStep"`
+	FinalPosition BatchPosition         `json:"finalPosition"`
+}
+
+// SolveMintRequest asks how much AUSD can be minted while keeping TargetHealthFactor, a decimal such as "1.25".
+type SolveMintRequest struct {
+	Address            string      `json:"address" binding:"required"`
+	TargetHealthFactor string      `json:"targetHealthFactor" binding:"required"`
+	Units              AmountUnits `json:"-"`
+}
+
+// SolveCollateralRequest asks how much of one collateral token can be redeemed, or must be deposited,
+// for the position to end at TargetHealthFactor.
+type SolveCollateralRequest struct {
+	Address            string      `json:"address" binding:"required"`
+	TokenAddress       string      `json:"tokenAddress" binding:"required"`
+	TargetHealthFactor string      `json:"targetHealthFactor" binding:"required"`
+	Units              AmountUnits `json:"-"`
+}
+
+// SolverResult is the amount, in wei of AUSD or of Token, found by a solver. Reachable is false when
+// no amount meets the target, such as redeeming from a position already below it.
+type SolverResult struct {
+	Amount             string                     `json:"amount"`
+	Token              string                     `json:"token,omitempty"`
+	TargetHealthFactor string                     `json:"targetHealthFactor"`
+	Reachable          bool                       `json:"reachable"`
+	HealthFactorBefore string                     `json:"healthFactorBefore"`
+	HealthFactorAfter  string                     `json:"healthFactorAfter"`
+	Formatted          map[string]FormattedAmount `json:"formatted,omitempty"`
 }
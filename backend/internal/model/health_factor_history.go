@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+type HealthFactorSource string
+
+const (
+	HealthFactorSourceCollateral      HealthFactorSource = "collateral"
+	HealthFactorSourceCoin            HealthFactorSource = "coin"
+	HealthFactorSourceLiquidationScan HealthFactorSource = "liquidation_scan"
+)
+
+type HealthFactorSnapshot struct {
+	UserAddress   string
+	HealthFactor  string
+	CollateralUSD string
+	Debt          string
+	Source        HealthFactorSource
+	Timestamp     time.Time
+	BlockNumber   uint64
+	Prices        map[string]string
+}
+
+type HealthFactorHistoryQuery struct {
+	From       time.Time
+	To         time.Time
+	Resolution time.Duration
+}
+
+type HealthFactorPoint struct {
+	Timestamp       string `json:"timestamp"`
+	HealthFactor    string `json:"healthFactor"`
+	MinHealthFactor string `json:"minHealthFactor"`
+	MaxHealthFactor string `json:"maxHealthFactor"`
+	CollateralUsd   string `json:"collateralUsd"`
+	Debt            string `json:"debt"`
+	Samples         int    `json:"samples"`
+}
+
+type HealthFactorHistory struct {
+	Address    string              `json:"address"`
+	From       string              `json:"from"`
+	To         string              `json:"to"`
+	Resolution string              `json:"resolution"`
+	Points     []HealthFactorPoint `json:"points"`
+}
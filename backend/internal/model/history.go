@@ -1,5 +1,12 @@
 package model
 
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidHistoryCursor = errors.New("invalid history cursor")
+
 type TransactionType string
 
 const (
@@ -19,13 +26,14 @@ const (
 )
 
 type Transaction struct {
-	ID        string            `json:"id"`
-	Type      TransactionType   `json:"type"`
-	Amount    string            `json:"amount"`
-	Asset     string            `json:"asset,omitempty"`
-	Timestamp string            `json:"timestamp"`
-	TxHash    string            `json:"txHash"`
-	Status    TransactionStatus `json:"status"`
+	ID          string            `json:"id"`
+	Type        TransactionType   `json:"type"`
+	Amount      string            `json:"amount"`
+	Asset       string            `json:"asset,omitempty"`
+	Timestamp   string            `json:"timestamp"`
+	TxHash      string            `json:"txHash"`
+	Status      TransactionStatus `json:"status"`
+	BlockNumber uint64            `json:"blockNumber,omitempty"`
 }
 
 type HistoryData struct {
@@ -33,3 +41,104 @@ type HistoryData struct {
 	MintBurn     []Transaction `json:"mintBurn"`
 	Liquidations []Transaction `json:"liquidations"`
 }
+
+// HistoryFilter narrows a user's transaction history. Zero values leave a dimension unfiltered.
+// Mints and burns have no collateral token, so they are excluded when Token is set.
+type HistoryFilter struct {
+	Types     []TransactionType
+	Token     string
+	FromBlock *uint64
+	ToBlock   *uint64
+	From      *time.Time
+	To        *time.Time
+}
+
+type HistoryQuery struct {
+	HistoryFilter
+	Cursor string
+	Limit  int
+}
+
+// HistoryCursor is the position of the last transaction of a page. Rows whose event is missing all
+// sit at position zero, so Key, the row key of a transaction or the group key of an operation, breaks
+// ties between them.
+type HistoryCursor struct {
+	BlockNumber uint64
+	LogIndex    uint
+	Key         string
+}
+
+// HistoryEntry is a transaction row joined with the event it was indexed from. Amount is the debt
+// covered for liquidations, which also carry the seized collateral in CollateralAmount. GroupKey is
+// the transaction hash, or a key of the row's own when its event is missing. RowKey, the type and ID,
+// is unique across every type.
+type HistoryEntry struct {
+	ID               string
+	Type             TransactionType
+	Amount           BigInt
+	CollateralAmount BigInt
+	Asset            string
+	TxHash           string
+	GroupKey         string
+	BlockNumber      uint64
+	LogIndex         uint
+	CreatedAt        int64
+	RowKey           string
+}
+
+// HistoryOperationKey identifies an operation and the position of its last leg.
+type HistoryOperationKey struct {
+	GroupKey    string
+	BlockNumber uint64
+	LogIndex    uint
+	TotalCount  int64
+}
+
+type CollateralChange struct {
+	Asset  string `json:"asset"`
+	Amount string `json:"amount"`
+}
+
+// HistoryOperation groups the events emitted by one transaction, such as "deposit + mint".
+// Changes are signed, and the health factors are computed with the prices at the operation's block.
+type HistoryOperation struct {
+	TxHash             string             `json:"txHash"`
+	Kind               string             `json:"kind"`
+	BlockNumber        uint64             `json:"blockNumber,omitempty"`
+	Timestamp          string             `json:"timestamp"`
+	Legs               []Transaction      `json:"legs"`
+	CollateralChanges  []CollateralChange `json:"collateralChanges"`
+	DebtChange         string             `json:"debtChange"`
+	HealthFactorBefore string             `json:"healthFactorBefore,omitempty"`
+	HealthFactorAfter  string             `json:"healthFactorAfter,omitempty"`
+}
+
+type HistoryOperationsPage struct {
+	Operations []HistoryOperation `json:"operations"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+	TotalCount int64              `json:"totalCount"`
+}
+
+type HistoryPage struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"nextCursor,omitempty"`
+	TotalCount   int64         `json:"totalCount"`
+}
+
+// StatementEntry is a history row with the price of its collateral at the row's block, if any.
+type StatementEntry struct {
+	HistoryEntry
+	PriceInUSD *string
+}
+
+// StatementRow is one line of an exported account statement. Amounts are in whole tokens, and
+// ValueUSD is empty when no price was indexed at or before the row's block.
+type StatementRow struct {
+	Timestamp   string          `json:"timestamp"`
+	BlockNumber uint64          `json:"blockNumber"`
+	TxHash      string          `json:"txHash"`
+	Type        TransactionType `json:"type"`
+	Token       string          `json:"token"`
+	Amount      string          `json:"amount"`
+	ValueUSD    string          `json:"valueUsd"`
+}
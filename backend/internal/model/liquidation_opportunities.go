@@ -0,0 +1,21 @@
+package model
+
+type LiquidationOpportunity struct {
+	Address               string `json:"address"`
+	HealthFactor          string `json:"healthFactor"`
+	CollateralUsd         string `json:"collateralUsd"`
+	Debt                  string `json:"debt"`
+	Collateral            string `json:"collateral"`
+	CollateralAddress     string `json:"collateralAddress"`
+	DebtToCover           string `json:"debtToCover"`
+	CollateralReceived    string `json:"collateralReceived"`
+	BonusCollateral       string `json:"bonusCollateral"`
+	CollateralReceivedUsd string `json:"collateralReceivedUsd"`
+	ProfitUsd             string `json:"profitUsd"`
+	HealthFactorAfter     string `json:"healthFactorAfter"`
+}
+
+type LiquidationOpportunities struct {
+	Prices        map[string]string        `json:"prices"`
+	Opportunities []LiquidationOpportunity `json:"opportunities"`
+}
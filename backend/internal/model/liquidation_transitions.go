@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+type LiquidationTransitionDirection string
+
+const (
+	LiquidationTransitionEnter LiquidationTransitionDirection = "enter"
+	LiquidationTransitionExit  LiquidationTransitionDirection = "exit"
+)
+
+type LiquidationTransition struct {
+	ID                   string                         `json:"id,omitempty"`
+	Address              string                         `json:"address"`
+	Direction            LiquidationTransitionDirection `json:"direction"`
+	HealthFactor         string                         `json:"healthFactor"`
+	PreviousHealthFactor string                         `json:"previousHealthFactor"`
+	CollateralUsd        string                         `json:"collateralUsd"`
+	Debt                 string                         `json:"debt"`
+	BlockNumber          uint64                         `json:"blockNumber"`
+	Prices               map[string]string              `json:"prices"`
+	UnderwaterSeconds    int64                          `json:"underwaterSeconds,omitempty"`
+	Source               HealthFactorSource             `json:"source"`
+	Timestamp            string                         `json:"timestamp"`
+}
+
+type LiquidationTransitionsQuery struct {
+	Address string
+	From    time.Time
+	To      time.Time
+	Limit   int
+}
+
+type LiquidationTransitions struct {
+	From        string                  `json:"from"`
+	To          string                  `json:"to"`
+	Transitions []LiquidationTransition `json:"transitions"`
+}
@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+// LiquidationRecord is a liquidation joined with the event it was indexed from.
+type LiquidationRecord struct {
+	Liquidations
+	TxHash      string
+	BlockNumber uint64
+	CreatedAt   int64
+}
+
+// LiquidatorTotals aggregates the liquidations performed by one liquidator.
+type LiquidatorTotals struct {
+	LiquidatorAddress string
+	LiquidationCount  int64
+	DebtCovered       BigInt
+}
+
+type LiquidatorCollateralTotal struct {
+	CollateralAddress string
+	CollateralAmount  BigInt
+}
+
+type LiquidatorCollateral struct {
+	Asset  string `json:"asset"`
+	Amount string `json:"amount"`
+}
+
+type LiquidatorLiquidation struct {
+	ID               string `json:"id"`
+	LiquidatedUser   string `json:"liquidatedUser"`
+	Collateral       string `json:"collateral"`
+	CollateralAmount string `json:"collateralAmount"`
+	DebtCovered      string `json:"debtCovered"`
+	TxHash           string `json:"txHash"`
+	BlockNumber      uint64 `json:"blockNumber,omitempty"`
+	Timestamp        string `json:"timestamp"`
+}
+
+// LiquidatorSummary describes a liquidator's activity. Amounts are in wei, and the bonus is an
+// 18 decimal USD estimate valued at the prices each liquidation was executed with.
+type LiquidatorSummary struct {
+	Address            string                  `json:"address"`
+	LiquidationCount   int64                   `json:"liquidationCount"`
+	DebtCovered        string                  `json:"debtCovered"`
+	CollateralSeized   []LiquidatorCollateral  `json:"collateralSeized"`
+	EstimatedBonusUSD  string                  `json:"estimatedBonusUsd"`
+	RecentLiquidations []LiquidatorLiquidation `json:"recentLiquidations"`
+}
+
+// LiquidatorLeaderboardQuery ranks liquidators over the window ending now. Since is nil for the
+// all-time window.
+type LiquidatorLeaderboardQuery struct {
+	Window string
+	Since  *time.Time
+	Limit  int
+}
+
+type LiquidatorLeaderboardEntry struct {
+	Rank              int    `json:"rank"`
+	Address           string `json:"address"`
+	LiquidationCount  int64  `json:"liquidationCount"`
+	DebtCovered       string `json:"debtCovered"`
+	EstimatedBonusUSD string `json:"estimatedBonusUsd"`
+}
+
+type LiquidatorLeaderboard struct {
+	Window      string                       `json:"window"`
+	From        string                       `json:"from,omitempty"`
+	Liquidators []LiquidatorLeaderboardEntry `json:"liquidators"`
+}
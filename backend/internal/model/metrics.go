@@ -24,4 +24,5 @@ type Metrics struct {
 	Operation              Operation
 	CollateralTokenAddress common.Address
 	BlockNumber            uint64
+	Event                  *Events
 }
@@ -0,0 +1,10 @@
+package model
+
+type PositionStatus string
+
+const (
+	PositionStatusHealthy      PositionStatus = "healthy"
+	PositionStatusAtRisk       PositionStatus = "at_risk"
+	PositionStatusLiquidatable PositionStatus = "liquidatable"
+	PositionStatusInsolvent    PositionStatus = "insolvent"
+)
@@ -0,0 +1,68 @@
+package model
+
+import "encoding/json"
+
+type RealtimeEventType string
+
+const (
+	RealtimeEventHealthFactor          RealtimeEventType = "health_factor"
+	RealtimeEventLiquidationTransition RealtimeEventType = "liquidation_transition"
+	RealtimeEventPrices                RealtimeEventType = "prices"
+	RealtimeEventDashboard             RealtimeEventType = "dashboard"
+	RealtimeEventProtocolEvent         RealtimeEventType = "protocol_event"
+	// RealtimeEventReset tells a resuming client that events were missed and it should refetch state.
+	RealtimeEventReset RealtimeEventType = "reset"
+)
+
+// RealtimeEvent is a push delivered to live clients subscribed to Channel.
+type RealtimeEvent struct {
+	ID        uint64            `json:"id,omitempty"`
+	Channel   string            `json:"channel"`
+	Type      RealtimeEventType `json:"type"`
+	Data      json.RawMessage   `json:"data"`
+	Timestamp string            `json:"timestamp"`
+}
+
+// PricesUpdate is published on the prices channel every time collateral prices are refreshed.
+type PricesUpdate struct {
+	Prices    map[string]string `json:"prices"`
+	Timestamp string            `json:"timestamp"`
+}
+
+// DashboardUpdate signals that the protocol-wide state behind the dashboard metrics changed.
+type DashboardUpdate struct {
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	Timestamp   string `json:"timestamp"`
+}
+
+// ProtocolEventUpdate is published on the protocol events channel once an indexed contract event
+// has been applied to the cached positions.
+type ProtocolEventUpdate struct {
+	Name        string `json:"name"`
+	Address     string `json:"address"`
+	TxHash      string `json:"txHash"`
+	BlockNumber uint64 `json:"blockNumber"`
+	LogIndex    uint   `json:"logIndex"`
+	Timestamp   string `json:"timestamp"`
+}
+
+type RealtimeClientAction string
+
+const (
+	RealtimeActionSubscribe   RealtimeClientAction = "subscribe"
+	RealtimeActionUnsubscribe RealtimeClientAction = "unsubscribe"
+	RealtimeActionPing        RealtimeClientAction = "ping"
+)
+
+// RealtimeClientMessage is sent by WebSocket clients to manage their subscriptions.
+type RealtimeClientMessage struct {
+	Action   RealtimeClientAction `json:"action"`
+	Channels []string             `json:"channels"`
+}
+
+// RealtimeServerMessage acknowledges client actions or reports errors.
+type RealtimeServerMessage struct {
+	Type     string   `json:"type"`
+	Channels []string `json:"channels,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
@@ -0,0 +1,34 @@
+package model
+
+type StressTestRequest struct {
+	PriceChanges map[string]float64 `json:"priceChanges"`
+	Prices       map[string]string  `json:"prices"`
+}
+
+type StressTestPrice struct {
+	Asset        string `json:"asset"`
+	CurrentPrice string `json:"currentPrice"`
+	ShockedPrice string `json:"shockedPrice"`
+}
+
+type StressedUser struct {
+	Address            string `json:"address"`
+	HealthFactorBefore string `json:"healthFactorBefore"`
+	HealthFactorAfter  string `json:"healthFactorAfter"`
+	CollateralUsdAfter string `json:"collateralUsdAfter"`
+	Debt               string `json:"debt"`
+}
+
+type StressTestResult struct {
+	Prices                       []StressTestPrice `json:"prices"`
+	UsersEvaluated               int               `json:"usersEvaluated"`
+	NewlyLiquidatable            []StressedUser    `json:"newlyLiquidatable"`
+	LiquidatableUsers            int               `json:"liquidatableUsers"`
+	DebtAtRisk                   string            `json:"debtAtRisk"`
+	BadDebt                      string            `json:"badDebt"`
+	TotalDebt                    string            `json:"totalDebt"`
+	TotalCollateralUsdBefore     string            `json:"totalCollateralUsdBefore"`
+	TotalCollateralUsdAfter      string            `json:"totalCollateralUsdAfter"`
+	CollateralizationRatioBefore float64           `json:"collateralizationRatioBefore"`
+	CollateralizationRatioAfter  float64           `json:"collateralizationRatioAfter"`
+}
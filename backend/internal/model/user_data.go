@@ -1,15 +1,26 @@
 package model
 
+// CollateralDeposited is one collateral balance. Formatted is only set by projections asked for
+// decimal amounts.
 type CollateralDeposited struct {
-	Asset    string `json:"asset"`
-	Amount   string `json:"amount"`
-	ValueUsd string `json:"valueUsd"`
+	Asset     string                     `json:"asset"`
+	Amount    string                     `json:"amount"`
+	ValueUsd  string                     `json:"valueUsd"`
+	Formatted map[string]FormattedAmount `json:"formatted,omitempty"`
+}
+
+type CollateralLiquidationPrice struct {
+	Asset            string `json:"asset"`
+	CurrentPrice     string `json:"currentPrice"`
+	LiquidationPrice string `json:"liquidationPrice"`
 }
 
 type UserData struct {
-	TotalDebt           string                `json:"total_debt"`
-	CollateralValueUSD  string                `json:"collateral_value_usd"`
-	MaxMintable         string                `json:"max_mintable"`
-	CurrentHealthFactor string                `json:"current_health_factor"`
-	CollateralDeposited []CollateralDeposited `json:"collateral_deposited"`
-}
\ No newline at end of file
+	TotalDebt                 string                       `json:"total_debt"`
+	CollateralValueUSD        string                       `json:"collateral_value_usd"`
+	MaxMintable               string                       `json:"max_mintable"`
+	CurrentHealthFactor       string                       `json:"current_health_factor"`
+	CollateralDeposited       []CollateralDeposited        `json:"collateral_deposited"`
+	LiquidationPrices         []CollateralLiquidationPrice `json:"liquidation_prices"`
+	LiquidationDropPercentage *float64                     `json:"liquidation_drop_percentage"`
+}
@@ -0,0 +1,422 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+const (
+	defaultDashboardInterval = time.Second
+	defaultMaxSubscriptions  = 20
+	defaultReplayBufferSize  = 1000
+	subscriberBufferSize     = 64
+	maxFirstEventID          = 1 << 52
+	dashboardFetchTimeout    = 5 * time.Second
+)
+
+var (
+	ErrHubClosed        = errors.New("realtime hub closed")
+	ErrSlowSubscriber   = errors.New("subscriber could not keep up with updates")
+	ErrTooManyChannels  = errors.New("too many channel subscriptions")
+	ErrSubscriberClosed = errors.New("subscriber closed")
+)
+
+type DashboardReader interface {
+	GetDashboardMetrics(ctx context.Context) (model.DashboardMetrics, error)
+}
+
+// Hub fans out the updates the workers publish on Redis to live subscribers, keyed by channel.
+// Dashboard pushes are coalesced so a burst of indexed events produces a single snapshot.
+// The most recent events are kept in a bounded replay buffer so clients can resume after a reconnect
+// to the same replica.
+type Hub struct {
+	store             storage.ICacheStore
+	dashboard         DashboardReader
+	dashboardInterval time.Duration
+	maxSubscriptions  int
+
+	mu          sync.RWMutex
+	channels    map[string]map[*Subscriber]struct{}
+	subscribers map[*Subscriber]struct{}
+	closed      bool
+
+	// Event IDs start at a random offset below 2^52, so IDs issued by another replica or before a
+	// restart are not mistaken for this hub's, and stay exact in JavaScript clients.
+	lastID      uint64
+	evictedID   uint64
+	replay      []model.RealtimeEvent
+	replayStart int
+	replaySize  int
+
+	dashboardDirty atomic.Bool
+	redisSub       storage.Subscription
+	done           chan struct{}
+	closeOnce      sync.Once
+}
+
+func NewHub(store storage.ICacheStore, dashboard DashboardReader) *Hub {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Initializing realtime hub")
+
+	firstID := 1 + rand.Uint64N(maxFirstEventID)
+	replaySize := utils.IntFromEnv("REALTIME_REPLAY_BUFFER_SIZE", defaultReplayBufferSize)
+
+	return &Hub{
+		store:             store,
+		dashboard:         dashboard,
+		dashboardInterval: utils.DurationFromEnv("REALTIME_DASHBOARD_INTERVAL", defaultDashboardInterval),
+		maxSubscriptions:  utils.IntFromEnv("REALTIME_MAX_SUBSCRIPTIONS", defaultMaxSubscriptions),
+		channels:          make(map[string]map[*Subscriber]struct{}),
+		subscribers:       make(map[*Subscriber]struct{}),
+		lastID:            firstID - 1,
+		replay:            make([]model.RealtimeEvent, 0, replaySize),
+		replaySize:        replaySize,
+		done:              make(chan struct{}),
+	}
+}
+
+// Run subscribes to the worker channels on Redis and starts dispatching updates.
+func (h *Hub) Run() {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Starting realtime hub")
+
+	h.redisSub = h.store.Subscribe(
+		constants.HEALTH_FACTORS_CHANNEL,
+		constants.LIQUIDATIONS_CHANNEL,
+		constants.PRICES_CHANNEL,
+		constants.DASHBOARD_CHANNEL,
+		constants.PROTOCOL_EVENTS_CHANNEL,
+	)
+
+	go func() {
+		for message := range h.redisSub.Channel() {
+			h.dispatch(message.Channel, []byte(message.Payload))
+		}
+		logger.Debug().Msg("Realtime hub Redis subscription closed")
+	}()
+
+	go func() {
+		ticker := time.NewTicker(h.dashboardInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-h.done:
+				return
+			case <-ticker.C:
+				if h.dashboardDirty.Swap(false) && h.hasSubscribers(domain.RealtimeDashboardChannel) {
+					h.pushDashboard()
+				}
+			}
+		}
+	}()
+
+	logger.Info().Msg("Realtime hub started successfully")
+}
+
+// Close disconnects every subscriber and stops listening on Redis. It is safe to call more than once.
+func (h *Hub) Close() {
+	h.closeOnce.Do(func() {
+		logger := utils.GetLogger()
+		logger.Info().Msg("Closing realtime hub")
+
+		close(h.done)
+		if h.redisSub != nil {
+			if err := h.redisSub.Close(); err != nil {
+				logger.Warn().Err(err).Msg("Failed to close realtime hub Redis subscription")
+			}
+		}
+
+		h.mu.Lock()
+		h.closed = true
+		for subscriber := range h.subscribers {
+			h.removeLocked(subscriber, ErrHubClosed)
+		}
+		h.mu.Unlock()
+
+		logger.Info().Msg("Realtime hub closed")
+	})
+}
+
+// NewSubscriber registers a subscriber with no channels. It returns ErrHubClosed once the hub is shutting down.
+func (h *Hub) NewSubscriber() (*Subscriber, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrHubClosed
+	}
+
+	subscriber := &Subscriber{
+		hub:      h,
+		events:   make(chan model.RealtimeEvent, subscriberBufferSize),
+		channels: make(map[string]struct{}),
+	}
+	h.subscribers[subscriber] = struct{}{}
+	return subscriber, nil
+}
+
+// Broadcast records an event in the replay buffer and delivers it to every subscriber of the channel.
+// Subscribers whose buffer is full are disconnected rather than allowed to hold up everyone else.
+func (h *Hub) Broadcast(channel string, eventType model.RealtimeEventType, data []byte) {
+	logger := utils.GetLogger()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	event := model.RealtimeEvent{
+		ID:        h.lastID,
+		Channel:   channel,
+		Type:      eventType,
+		Data:      json.RawMessage(data),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	h.bufferLocked(event)
+
+	for subscriber := range h.channels[channel] {
+		select {
+		case subscriber.events <- event:
+		default:
+			logger.Warn().Str("channel", channel).Msg("Disconnecting slow realtime subscriber")
+			h.removeLocked(subscriber, ErrSlowSubscriber)
+		}
+	}
+}
+
+func (h *Hub) bufferLocked(event model.RealtimeEvent) {
+	if len(h.replay) < h.replaySize {
+		h.replay = append(h.replay, event)
+		return
+	}
+
+	h.evictedID = h.replay[h.replayStart].ID
+	h.replay[h.replayStart] = event
+	h.replayStart = (h.replayStart + 1) % h.replaySize
+}
+
+// bufferedLocked returns the replay buffer ordered from oldest to newest.
+func (h *Hub) bufferedLocked() []model.RealtimeEvent {
+	buffered := make([]model.RealtimeEvent, 0, len(h.replay))
+	buffered = append(buffered, h.replay[h.replayStart:]...)
+	return append(buffered, h.replay[:h.replayStart]...)
+}
+
+func (h *Hub) dispatch(redisChannel string, payload []byte) {
+	logger := utils.GetLogger()
+
+	switch redisChannel {
+	case constants.HEALTH_FACTORS_CHANNEL:
+		var update model.HealthFactorUpdate
+		if err := json.Unmarshal(payload, &update); err != nil {
+			logger.Warn().Err(err).Msg("Failed to decode health factor update")
+			return
+		}
+		h.Broadcast(domain.RealtimeUserChannel(update.Address), model.RealtimeEventHealthFactor, payload)
+
+	case constants.LIQUIDATIONS_CHANNEL:
+		var transition model.LiquidationTransition
+		if err := json.Unmarshal(payload, &transition); err != nil {
+			logger.Warn().Err(err).Msg("Failed to decode liquidation transition")
+			return
+		}
+		h.Broadcast(domain.RealtimeLiquidationsChannel, model.RealtimeEventLiquidationTransition, payload)
+		h.Broadcast(domain.RealtimeUserChannel(transition.Address), model.RealtimeEventLiquidationTransition, payload)
+
+	case constants.PRICES_CHANNEL:
+		h.Broadcast(domain.RealtimePricesChannel, model.RealtimeEventPrices, payload)
+
+	case constants.PROTOCOL_EVENTS_CHANNEL:
+		var update model.ProtocolEventUpdate
+		if err := json.Unmarshal(payload, &update); err != nil {
+			logger.Warn().Err(err).Msg("Failed to decode protocol event update")
+			return
+		}
+		h.Broadcast(domain.RealtimeEventsChannel, model.RealtimeEventProtocolEvent, payload)
+		h.Broadcast(domain.RealtimeUserChannel(update.Address), model.RealtimeEventProtocolEvent, payload)
+
+	case constants.DASHBOARD_CHANNEL:
+		h.dashboardDirty.Store(true)
+	}
+}
+
+func (h *Hub) pushDashboard() {
+	logger := utils.GetLogger()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dashboardFetchTimeout)
+	defer cancel()
+
+	metrics, err := h.dashboard.GetDashboardMetrics(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get dashboard metrics for realtime push")
+		return
+	}
+
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to encode dashboard metrics for realtime push")
+		return
+	}
+
+	h.Broadcast(domain.RealtimeDashboardChannel, model.RealtimeEventDashboard, data)
+}
+
+func (h *Hub) hasSubscribers(channel string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.channels[channel]) > 0
+}
+
+func (h *Hub) removeLocked(subscriber *Subscriber, reason error) {
+	if _, ok := h.subscribers[subscriber]; !ok {
+		return
+	}
+
+	for channel := range subscriber.channels {
+		delete(h.channels[channel], subscriber)
+		if len(h.channels[channel]) == 0 {
+			delete(h.channels, channel)
+		}
+	}
+	delete(h.subscribers, subscriber)
+
+	subscriber.err = reason
+	close(subscriber.events)
+}
+
+// Subscriber receives the events of the channels it subscribed to until it is closed.
+type Subscriber struct {
+	hub      *Hub
+	events   chan model.RealtimeEvent
+	channels map[string]struct{}
+	err      error
+}
+
+// Events is closed when the subscriber is removed from the hub; Err then reports why.
+func (s *Subscriber) Events() <-chan model.RealtimeEvent {
+	return s.events
+}
+
+func (s *Subscriber) Err() error {
+	s.hub.mu.RLock()
+	defer s.hub.mu.RUnlock()
+	return s.err
+}
+
+// Subscribe adds the given channels and returns their canonical names.
+func (s *Subscriber) Subscribe(channels ...string) ([]string, error) {
+	normalized, err := normalizeChannels(channels)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if err := s.subscribeLocked(normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// Resume subscribes to the given channels and returns the buffered events they received after
+// lastEventID. Events published afterwards are delivered on Events, so nothing is missed or repeated
+// in between. The boolean is false when lastEventID is not in this hub's buffer, including IDs issued
+// by another replica or before a restart.
+func (s *Subscriber) Resume(lastEventID uint64, channels ...string) ([]model.RealtimeEvent, bool, error) {
+	normalized, err := normalizeChannels(channels)
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if err := s.subscribeLocked(normalized); err != nil {
+		return nil, false, err
+	}
+
+	replay, complete := domain.ReplayRealtimeEvents(s.hub.bufferedLocked(), s.hub.evictedID, lastEventID, normalized)
+	return replay, complete, nil
+}
+
+func (s *Subscriber) subscribeLocked(normalized []string) error {
+	if _, ok := s.hub.subscribers[s]; !ok {
+		return ErrSubscriberClosed
+	}
+
+	added := 0
+	for _, channel := range normalized {
+		if _, ok := s.channels[channel]; !ok {
+			added++
+		}
+	}
+	if len(s.channels)+added > s.hub.maxSubscriptions {
+		return fmt.Errorf("%w: at most %d channels per connection", ErrTooManyChannels, s.hub.maxSubscriptions)
+	}
+
+	for _, channel := range normalized {
+		s.channels[channel] = struct{}{}
+		if s.hub.channels[channel] == nil {
+			s.hub.channels[channel] = make(map[*Subscriber]struct{})
+		}
+		s.hub.channels[channel][s] = struct{}{}
+	}
+	return nil
+}
+
+// Unsubscribe removes the given channels and returns the canonical names that were removed.
+func (s *Subscriber) Unsubscribe(channels ...string) []string {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	removed := []string{}
+	for _, channel := range channels {
+		name, err := domain.NormalizeRealtimeChannel(channel)
+		if err != nil {
+			continue
+		}
+		if _, ok := s.channels[name]; !ok {
+			continue
+		}
+
+		delete(s.channels, name)
+		delete(s.hub.channels[name], s)
+		if len(s.hub.channels[name]) == 0 {
+			delete(s.hub.channels, name)
+		}
+		removed = append(removed, name)
+	}
+	return removed
+}
+
+// Close removes the subscriber from the hub.
+func (s *Subscriber) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.removeLocked(s, ErrSubscriberClosed)
+}
+
+func normalizeChannels(channels []string) ([]string, error) {
+	normalized := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		name, err := domain.NormalizeRealtimeChannel(channel)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, name)
+	}
+	return normalized, nil
+}
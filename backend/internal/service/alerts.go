@@ -0,0 +1,469 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+const (
+	defaultAlertCooldown       = time.Hour
+	defaultAlertMaxAttempts    = 5
+	defaultAlertRetryBaseDelay = 2 * time.Second
+	defaultAlertRetryMaxDelay  = 5 * time.Minute
+	defaultAlertWebhookTimeout = 10 * time.Second
+	defaultAlertDeliveryStale  = 15 * time.Minute
+	maxAlertWebhookRedirects   = 5
+	alertResumeBatchSize       = 100
+
+	AlertSignatureHeader = "X-AnchorUSD-Signature"
+	AlertTimestampHeader = "X-AnchorUSD-Timestamp"
+	AlertDeliveryHeader  = "X-AnchorUSD-Delivery"
+)
+
+type AlertStore interface {
+	CreateSubscription(ctx context.Context, subscription *model.AlertSubscription) error
+	GetSubscriptionsByAddress(ctx context.Context, address string) ([]model.AlertSubscription, error)
+	GetSubscription(ctx context.Context, address string, id uint) (*model.AlertSubscription, error)
+	DeleteSubscription(ctx context.Context, address string, id uint) (bool, error)
+	TriggerSubscription(ctx context.Context, delivery *model.AlertDelivery, triggeredAt time.Time) (bool, error)
+	RearmSubscription(ctx context.Context, id uint) error
+	UpdateDelivery(ctx context.Context, delivery *model.AlertDelivery) error
+	ClaimStalePendingDeliveries(ctx context.Context, staleBefore time.Time, limit int) ([]model.AlertDelivery, error)
+	GetDeliveries(ctx context.Context, subscriptionID uint, limit int) ([]model.AlertDelivery, error)
+}
+
+// WebhookResolver looks up the addresses of a webhook host. *net.Resolver implements it.
+type WebhookResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// AlertDispatch is a delivery that was logged as pending and still has to be POSTed to the subscriber.
+type AlertDispatch struct {
+	Subscription model.AlertSubscription
+	Delivery     *model.AlertDelivery
+}
+
+type alertsService struct {
+	Store                AlertStore
+	Client               *http.Client
+	Resolver             WebhookResolver
+	AllowPrivateWebhooks bool
+	Cooldown             time.Duration
+	MaxAttempts          int
+	RetryBaseDelay       time.Duration
+	RetryMaxDelay        time.Duration
+	// DeliveryStaleAfter is how long a pending delivery can go without an update before it is
+	// considered abandoned and resumed. It must exceed RetryMaxDelay plus the webhook timeout.
+	DeliveryStaleAfter time.Duration
+}
+
+func NewAlertsService(store AlertStore) *alertsService {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Initializing alerts service")
+
+	allowPrivateWebhooks := os.Getenv("ALERTS_ALLOW_PRIVATE_WEBHOOKS") == "true"
+	if allowPrivateWebhooks {
+		logger.Warn().Msg("Alert webhooks may target private and loopback addresses")
+	}
+
+	return &alertsService{
+		Store:                store,
+		Client:               newWebhookClient(utils.DurationFromEnv("ALERTS_WEBHOOK_TIMEOUT", defaultAlertWebhookTimeout), allowPrivateWebhooks),
+		Resolver:             net.DefaultResolver,
+		AllowPrivateWebhooks: allowPrivateWebhooks,
+		Cooldown:             utils.DurationFromEnv("ALERTS_COOLDOWN", defaultAlertCooldown),
+		MaxAttempts:          utils.IntFromEnv("ALERTS_MAX_ATTEMPTS", defaultAlertMaxAttempts),
+		RetryBaseDelay:       utils.DurationFromEnv("ALERTS_RETRY_BASE_DELAY", defaultAlertRetryBaseDelay),
+		RetryMaxDelay:        utils.DurationFromEnv("ALERTS_RETRY_MAX_DELAY", defaultAlertRetryMaxDelay),
+		DeliveryStaleAfter:   utils.DurationFromEnv("ALERTS_DELIVERY_STALE_AFTER", defaultAlertDeliveryStale),
+	}
+}
+
+func (s *alertsService) CreateSubscription(ctx context.Context, request model.CreateAlertSubscriptionRequest) (model.CreatedAlertSubscription, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("address", request.Address).Str("threshold", request.Threshold).Msg("Creating alert subscription")
+
+	threshold, err := domain.ParseAlertThreshold(request.Threshold)
+	if err != nil {
+		return model.CreatedAlertSubscription{}, err
+	}
+
+	if err := s.checkWebhookURL(ctx, request.WebhookURL); err != nil {
+		logger.Warn().Err(err).Str("address", request.Address).Msg("Rejected alert webhook")
+		return model.CreatedAlertSubscription{}, err
+	}
+
+	secret, err := generateAlertSecret()
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to generate alert secret")
+		return model.CreatedAlertSubscription{}, err
+	}
+
+	subscription := model.AlertSubscription{
+		Address:    request.Address,
+		WebhookURL: request.WebhookURL,
+		Threshold:  model.NewBigInt(threshold),
+		Secret:     secret,
+	}
+
+	if err := s.Store.CreateSubscription(ctx, &subscription); err != nil {
+		return model.CreatedAlertSubscription{}, err
+	}
+
+	return model.CreatedAlertSubscription{
+		AlertSubscription: subscription,
+		Secret:            secret,
+	}, nil
+}
+
+func (s *alertsService) GetSubscriptions(ctx context.Context, address string) ([]model.AlertSubscription, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("address", address).Msg("Getting alert subscriptions")
+
+	subscriptions, err := s.Store.GetSubscriptionsByAddress(ctx, address)
+	if err != nil {
+		logger.Error().Err(err).Str("address", address).Msg("Failed to get alert subscriptions")
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (s *alertsService) DeleteSubscription(ctx context.Context, address string, id uint) error {
+	logger := utils.GetLogger()
+	logger.Info().Str("address", address).Uint("subscription_id", id).Msg("Deleting alert subscription")
+
+	deleted, err := s.Store.DeleteSubscription(ctx, address, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return model.ErrAlertSubscriptionNotFound
+	}
+	return nil
+}
+
+func (s *alertsService) GetDeliveries(ctx context.Context, address string, id uint, limit int) ([]model.AlertDelivery, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("address", address).Uint("subscription_id", id).Int("limit", limit).Msg("Getting alert deliveries")
+
+	subscription, err := s.Store.GetSubscription(ctx, address, id)
+	if err != nil {
+		logger.Error().Err(err).Uint("subscription_id", id).Msg("Failed to get alert subscription")
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, model.ErrAlertSubscriptionNotFound
+	}
+
+	return s.Store.GetDeliveries(ctx, id, limit)
+}
+
+// EvaluateHealthFactorUpdate re-arms subscriptions whose position recovered and logs a pending delivery for
+// every subscription the new health factor fires. Subscriptions are marked as triggered before delivery so
+// repeated recalculations below the threshold, or other replicas evaluating the same update, do not
+// produce duplicate alerts.
+func (s *alertsService) EvaluateHealthFactorUpdate(ctx context.Context, update model.HealthFactorUpdate) ([]AlertDispatch, error) {
+	logger := utils.GetLogger()
+
+	healthFactor, ok := new(big.Int).SetString(update.HealthFactor, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid health factor %q for user %s", update.HealthFactor, update.Address)
+	}
+
+	subscriptions, err := s.Store.GetSubscriptionsByAddress(ctx, update.Address)
+	if err != nil {
+		logger.Error().Err(err).Str("address", update.Address).Msg("Failed to get alert subscriptions")
+		return nil, err
+	}
+
+	now := time.Now()
+	dispatches := []AlertDispatch{}
+
+	for _, subscription := range subscriptions {
+		state := domain.AlertState{
+			Threshold:       subscription.Threshold.Int,
+			Triggered:       subscription.Triggered,
+			LastTriggeredAt: subscription.LastTriggeredAt,
+		}
+
+		if domain.ShouldRearmAlert(state, healthFactor) {
+			if err := s.Store.RearmSubscription(ctx, subscription.ID); err != nil {
+				logger.Error().Err(err).Uint("subscription_id", subscription.ID).Msg("Failed to re-arm alert subscription")
+			}
+			continue
+		}
+
+		if !domain.ShouldTriggerAlert(state, healthFactor, now, s.Cooldown) {
+			continue
+		}
+
+		payload, err := json.Marshal(model.AlertPayload{
+			SubscriptionID: subscription.ID,
+			Address:        update.Address,
+			HealthFactor:   update.HealthFactor,
+			Threshold:      subscription.Threshold.Int.String(),
+			CollateralUsd:  update.CollateralUsd,
+			Debt:           update.Debt,
+			Timestamp:      now.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			logger.Error().Err(err).Uint("subscription_id", subscription.ID).Msg("Failed to encode alert payload")
+			continue
+		}
+
+		delivery := &model.AlertDelivery{
+			SubscriptionID: subscription.ID,
+			Address:        update.Address,
+			HealthFactor:   update.HealthFactor,
+			Payload:        string(payload),
+			Status:         model.AlertDeliveryPending,
+		}
+		triggered, err := s.Store.TriggerSubscription(ctx, delivery, now)
+		if err != nil {
+			logger.Error().Err(err).Uint("subscription_id", subscription.ID).Msg("Failed to trigger alert subscription")
+			continue
+		}
+		if !triggered {
+			logger.Debug().Uint("subscription_id", subscription.ID).Msg("Alert subscription already triggered elsewhere")
+			continue
+		}
+
+		logger.Info().Uint("subscription_id", subscription.ID).Str("address", update.Address).Str("health_factor", update.HealthFactor).Msg("Health factor alert triggered")
+		dispatches = append(dispatches, AlertDispatch{Subscription: subscription, Delivery: delivery})
+	}
+
+	return dispatches, nil
+}
+
+// ResumeStaleDeliveries claims the pending deliveries whose process stopped before finishing them, so
+// alerts survive restarts. Deliveries of subscriptions deleted in the meantime are marked as failed.
+func (s *alertsService) ResumeStaleDeliveries(ctx context.Context) ([]AlertDispatch, error) {
+	logger := utils.GetLogger()
+
+	deliveries, err := s.Store.ClaimStalePendingDeliveries(ctx, time.Now().Add(-s.DeliveryStaleAfter), alertResumeBatchSize)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to claim stale alert deliveries")
+		return nil, err
+	}
+
+	dispatches := []AlertDispatch{}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		subscription, err := s.Store.GetSubscription(ctx, delivery.Address, delivery.SubscriptionID)
+		if err != nil {
+			logger.Error().Err(err).Uint("delivery_id", delivery.ID).Msg("Failed to get alert subscription")
+			continue
+		}
+		if subscription == nil {
+			delivery.Status = model.AlertDeliveryFailed
+			delivery.Error = model.ErrAlertSubscriptionNotFound.Error()
+			if err := s.Store.UpdateDelivery(ctx, delivery); err != nil {
+				logger.Error().Err(err).Uint("delivery_id", delivery.ID).Msg("Failed to update alert delivery")
+			}
+			continue
+		}
+
+		logger.Info().Uint("delivery_id", delivery.ID).Int("attempts", delivery.Attempts).Msg("Resuming alert delivery")
+		dispatches = append(dispatches, AlertDispatch{Subscription: *subscription, Delivery: delivery})
+	}
+
+	return dispatches, nil
+}
+
+// DeliverAlert POSTs the signed payload to the subscriber's webhook, retrying with exponential backoff.
+// Every attempt is written to the delivery log, and a resumed delivery continues from its last attempt.
+func (s *alertsService) DeliverAlert(ctx context.Context, dispatch AlertDispatch) error {
+	logger := utils.GetLogger()
+	delivery := dispatch.Delivery
+
+	lastErr := errors.New("no delivery attempts left")
+	for attempt := delivery.Attempts + 1; attempt <= s.MaxAttempts; attempt++ {
+		statusCode, err := s.postWebhook(ctx, dispatch)
+		if ctx.Err() != nil {
+			// Shutting down: the delivery stays pending and is resumed once it goes stale.
+			logger.Warn().Uint("delivery_id", delivery.ID).Int("attempts", delivery.Attempts).Msg("Alert delivery interrupted")
+			return ctx.Err()
+		}
+
+		delivery.Attempts = attempt
+		delivery.ResponseStatus = statusCode
+		delivery.Error = ""
+		if err == nil {
+			deliveredAt := time.Now()
+			delivery.Status = model.AlertDeliveryDelivered
+			delivery.DeliveredAt = &deliveredAt
+			if err := s.Store.UpdateDelivery(ctx, delivery); err != nil {
+				logger.Error().Err(err).Uint("delivery_id", delivery.ID).Msg("Failed to update alert delivery")
+			}
+			logger.Info().Uint("delivery_id", delivery.ID).Int("attempts", attempt).Msg("Alert delivered successfully")
+			return nil
+		}
+
+		lastErr = err
+		delivery.Error = err.Error()
+
+		retryable := isRetryableWebhookStatus(statusCode) && !errors.Is(err, model.ErrWebhookNotAllowed)
+		if attempt == s.MaxAttempts || !retryable {
+			break
+		}
+
+		if err := s.Store.UpdateDelivery(ctx, delivery); err != nil {
+			logger.Error().Err(err).Uint("delivery_id", delivery.ID).Msg("Failed to update alert delivery")
+		}
+
+		backoff := domain.RetryBackoff(attempt, s.RetryBaseDelay, s.RetryMaxDelay)
+		logger.Warn().Err(err).Uint("delivery_id", delivery.ID).Int("attempt", attempt).Str("retry_in", backoff.String()).Msg("Alert delivery failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			logger.Warn().Uint("delivery_id", delivery.ID).Int("attempts", attempt).Msg("Alert delivery interrupted")
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+
+	delivery.Status = model.AlertDeliveryFailed
+	if err := s.Store.UpdateDelivery(ctx, delivery); err != nil {
+		logger.Error().Err(err).Uint("delivery_id", delivery.ID).Msg("Failed to update alert delivery")
+	}
+
+	logger.Error().Err(lastErr).Uint("delivery_id", delivery.ID).Int("attempts", delivery.Attempts).Msg("Alert delivery failed")
+	return lastErr
+}
+
+func (s *alertsService) postWebhook(ctx context.Context, dispatch AlertDispatch) (int, error) {
+	body := []byte(dispatch.Delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, dispatch.Subscription.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(AlertTimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(AlertSignatureHeader, "sha256="+domain.SignWebhookPayload(dispatch.Subscription.Secret, timestamp, body))
+	request.Header.Set(AlertDeliveryHeader, strconv.FormatUint(uint64(dispatch.Delivery.ID), 10))
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// isRetryableWebhookStatus treats network errors, timeouts, rate limiting and server errors as transient.
+func isRetryableWebhookStatus(statusCode int) bool {
+	if statusCode == 0 || statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return statusCode >= 500
+}
+
+// checkWebhookURL resolves the webhook host and rejects it when any of its addresses is not public.
+func (s *alertsService) checkWebhookURL(ctx context.Context, rawURL string) error {
+	if s.AllowPrivateWebhooks {
+		return nil
+	}
+
+	webhookURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", model.ErrWebhookNotAllowed, err)
+	}
+	return checkWebhookHost(ctx, s.Resolver, webhookURL.Hostname())
+}
+
+func checkWebhookHost(ctx context.Context, resolver WebhookResolver, host string) error {
+	addresses := []net.IPAddr{}
+	if ip := net.ParseIP(host); ip != nil {
+		addresses = append(addresses, net.IPAddr{IP: ip})
+	} else {
+		resolved, err := resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return fmt.Errorf("%w: cannot resolve %s: %v", model.ErrWebhookNotAllowed, host, err)
+		}
+		addresses = resolved
+	}
+
+	if len(addresses) == 0 {
+		return fmt.Errorf("%w: %s has no addresses", model.ErrWebhookNotAllowed, host)
+	}
+	for _, address := range addresses {
+		if !domain.IsPublicWebhookIP(address.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", model.ErrWebhookNotAllowed, host, address.IP)
+		}
+	}
+	return nil
+}
+
+// newWebhookClient returns the client deliveries are POSTed with. Unless private webhooks are allowed,
+// every connection, including the ones redirects open, is checked against the address it actually
+// dials, so a host that resolves to a public address at subscribe time cannot be rebound to an
+// internal one later. Proxies are not used, since they would dial on the client's behalf.
+func newWebhookClient(timeout time.Duration, allowPrivateWebhooks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivateWebhooks {
+		dialer.Control = guardWebhookDial
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) >= maxAlertWebhookRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxAlertWebhookRedirects)
+			}
+			if request.URL.Scheme != "http" && request.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirect to %s URL", model.ErrWebhookNotAllowed, request.URL.Scheme)
+			}
+			if allowPrivateWebhooks {
+				return nil
+			}
+			return checkWebhookHost(request.Context(), net.DefaultResolver, request.URL.Hostname())
+		},
+	}
+}
+
+func guardWebhookDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !domain.IsPublicWebhookIP(net.ParseIP(host)) {
+		return fmt.Errorf("%w: %s", model.ErrWebhookNotAllowed, host)
+	}
+	return nil
+}
+
+func generateAlertSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,537 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAlertStore struct {
+	mock.Mock
+}
+
+func (m *MockAlertStore) CreateSubscription(ctx context.Context, subscription *model.AlertSubscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockAlertStore) GetSubscriptionsByAddress(ctx context.Context, address string) ([]model.AlertSubscription, error) {
+	args := m.Called(ctx, address)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AlertSubscription), args.Error(1)
+}
+
+func (m *MockAlertStore) GetSubscription(ctx context.Context, address string, id uint) (*model.AlertSubscription, error) {
+	args := m.Called(ctx, address, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AlertSubscription), args.Error(1)
+}
+
+func (m *MockAlertStore) DeleteSubscription(ctx context.Context, address string, id uint) (bool, error) {
+	args := m.Called(ctx, address, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAlertStore) TriggerSubscription(ctx context.Context, delivery *model.AlertDelivery, triggeredAt time.Time) (bool, error) {
+	args := m.Called(ctx, delivery, triggeredAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAlertStore) RearmSubscription(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAlertStore) UpdateDelivery(ctx context.Context, delivery *model.AlertDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockAlertStore) ClaimStalePendingDeliveries(ctx context.Context, staleBefore time.Time, limit int) ([]model.AlertDelivery, error) {
+	args := m.Called(ctx, staleBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AlertDelivery), args.Error(1)
+}
+
+func (m *MockAlertStore) GetDeliveries(ctx context.Context, subscriptionID uint, limit int) ([]model.AlertDelivery, error) {
+	args := m.Called(ctx, subscriptionID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AlertDelivery), args.Error(1)
+}
+
+type fakeWebhookResolver map[string][]string
+
+func (r fakeWebhookResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	addresses := make([]net.IPAddr, 0, len(ips))
+	for _, ip := range ips {
+		addresses = append(addresses, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addresses, nil
+}
+
+func newTestAlertsService(store AlertStore) *alertsService {
+	return &alertsService{
+		Store:  store,
+		Client: &http.Client{Timeout: time.Second},
+		Resolver: fakeWebhookResolver{
+			"example.com":      {"93.184.215.14"},
+			"internal.example": {"93.184.215.14", "10.0.0.5"},
+		},
+		Cooldown:           time.Hour,
+		MaxAttempts:        3,
+		RetryBaseDelay:     time.Millisecond,
+		RetryMaxDelay:      5 * time.Millisecond,
+		DeliveryStaleAfter: time.Minute,
+	}
+}
+
+func alertSubscription(id uint, threshold int64, triggered bool) model.AlertSubscription {
+	return model.AlertSubscription{
+		ID:         id,
+		Address:    "0x123",
+		WebhookURL: "http://example.invalid/hook",
+		Threshold:  model.NewBigInt(new(big.Int).Mul(big.NewInt(threshold), big.NewInt(1e17))),
+		Secret:     "secret",
+		Triggered:  triggered,
+	}
+}
+
+func TestCreateAlertSubscription(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	mockStore.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(subscription *model.AlertSubscription) bool {
+		return subscription.Address == "0x123" &&
+			subscription.Threshold.Int.String() == "1300000000000000000" &&
+			len(subscription.Secret) == 64
+	})).Return(nil)
+
+	created, err := service.CreateSubscription(context.Background(), model.CreateAlertSubscriptionRequest{
+		Address:    "0x123",
+		WebhookURL: "https://example.com/hook",
+		Threshold:  "1.3",
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, created.Secret, 64)
+	assert.Equal(t, created.Secret, created.AlertSubscription.Secret)
+	mockStore.AssertExpectations(t)
+}
+
+func TestCreateAlertSubscription_InvalidThreshold(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	_, err := service.CreateSubscription(context.Background(), model.CreateAlertSubscriptionRequest{
+		Address:    "0x123",
+		WebhookURL: "https://example.com/hook",
+		Threshold:  "0",
+	})
+
+	assert.ErrorIs(t, err, model.ErrInvalidAmount)
+	mockStore.AssertNotCalled(t, "CreateSubscription", mock.Anything, mock.Anything)
+}
+
+func TestCreateAlertSubscription_RejectsNonPublicWebhooks(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	for _, webhookURL := range []string{
+		"http://169.254.169.254/latest/meta-data",
+		"http://127.0.0.1:8080/hook",
+		"http://[::1]/hook",
+		"https://internal.example/hook",
+		"https://unknown.example/hook",
+	} {
+		_, err := service.CreateSubscription(context.Background(), model.CreateAlertSubscriptionRequest{
+			Address:    "0x123",
+			WebhookURL: webhookURL,
+			Threshold:  "1.3",
+		})
+
+		assert.ErrorIs(t, err, model.ErrWebhookNotAllowed, webhookURL)
+	}
+	mockStore.AssertNotCalled(t, "CreateSubscription", mock.Anything, mock.Anything)
+}
+
+func TestDeleteAlertSubscription_NotFound(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	mockStore.On("DeleteSubscription", mock.Anything, "0x123", uint(7)).Return(false, nil)
+
+	err := service.DeleteSubscription(context.Background(), "0x123", 7)
+
+	assert.ErrorIs(t, err, model.ErrAlertSubscriptionNotFound)
+}
+
+func TestGetAlertDeliveries_NotFound(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	mockStore.On("GetSubscription", mock.Anything, "0x123", uint(7)).Return(nil, nil)
+
+	_, err := service.GetDeliveries(context.Background(), "0x123", 7, 50)
+
+	assert.ErrorIs(t, err, model.ErrAlertSubscriptionNotFound)
+	mockStore.AssertNotCalled(t, "GetDeliveries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEvaluateHealthFactorUpdate_TriggersOncePerCrossing(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	mockStore.On("GetSubscriptionsByAddress", mock.Anything, "0x123").Return([]model.AlertSubscription{
+		alertSubscription(1, 13, false),
+		alertSubscription(2, 13, true),
+		alertSubscription(3, 11, false),
+	}, nil)
+	mockStore.On("TriggerSubscription", mock.Anything, mock.MatchedBy(func(delivery *model.AlertDelivery) bool {
+		var payload model.AlertPayload
+		if err := json.Unmarshal([]byte(delivery.Payload), &payload); err != nil {
+			return false
+		}
+		return delivery.SubscriptionID == 1 &&
+			delivery.Status == model.AlertDeliveryPending &&
+			payload.HealthFactor == "1200000000000000000" &&
+			payload.Threshold == "1300000000000000000"
+	}), mock.Anything).Return(true, nil)
+
+	dispatches, err := service.EvaluateHealthFactorUpdate(context.Background(), model.HealthFactorUpdate{
+		Address:      "0x123",
+		HealthFactor: "1200000000000000000",
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, dispatches, 1)
+	assert.Equal(t, uint(1), dispatches[0].Subscription.ID)
+	mockStore.AssertExpectations(t)
+	mockStore.AssertNumberOfCalls(t, "TriggerSubscription", 1)
+}
+
+func TestEvaluateHealthFactorUpdate_SkipsSubscriptionsTriggeredElsewhere(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	mockStore.On("GetSubscriptionsByAddress", mock.Anything, "0x123").Return([]model.AlertSubscription{
+		alertSubscription(1, 13, false),
+	}, nil)
+	mockStore.On("TriggerSubscription", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+	dispatches, err := service.EvaluateHealthFactorUpdate(context.Background(), model.HealthFactorUpdate{
+		Address:      "0x123",
+		HealthFactor: "1200000000000000000",
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, dispatches)
+	mockStore.AssertExpectations(t)
+}
+
+func TestEvaluateHealthFactorUpdate_SkipsSubscriptionsThatFailToTrigger(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	mockStore.On("GetSubscriptionsByAddress", mock.Anything, "0x123").Return([]model.AlertSubscription{
+		alertSubscription(1, 13, false),
+	}, nil)
+	mockStore.On("TriggerSubscription", mock.Anything, mock.Anything, mock.Anything).Return(false, assert.AnError)
+
+	dispatches, err := service.EvaluateHealthFactorUpdate(context.Background(), model.HealthFactorUpdate{
+		Address:      "0x123",
+		HealthFactor: "1200000000000000000",
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, dispatches)
+	mockStore.AssertExpectations(t)
+}
+
+func TestEvaluateHealthFactorUpdate_RearmsRecoveredSubscriptions(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	mockStore.On("GetSubscriptionsByAddress", mock.Anything, "0x123").Return([]model.AlertSubscription{
+		alertSubscription(1, 13, true),
+	}, nil)
+	mockStore.On("RearmSubscription", mock.Anything, uint(1)).Return(nil)
+
+	dispatches, err := service.EvaluateHealthFactorUpdate(context.Background(), model.HealthFactorUpdate{
+		Address:      "0x123",
+		HealthFactor: "1500000000000000000",
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, dispatches)
+	mockStore.AssertExpectations(t)
+	mockStore.AssertNotCalled(t, "TriggerSubscription", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEvaluateHealthFactorUpdate_FractionalHealthFactor(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	// $3800 of collateral against 1000 AUSD is a 1.9 health factor, above the 1.3 threshold.
+	collateralUSD := new(big.Int).Mul(big.NewInt(3800), big.NewInt(1e8))
+	debt := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	healthFactor := domain.CalculateHealthFactor(collateralUSD, debt)
+
+	mockStore.On("GetSubscriptionsByAddress", mock.Anything, "0x123").Return([]model.AlertSubscription{
+		alertSubscription(1, 13, false),
+	}, nil)
+
+	dispatches, err := service.EvaluateHealthFactorUpdate(context.Background(), model.HealthFactorUpdate{
+		Address:      "0x123",
+		HealthFactor: healthFactor.String(),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1900000000000000000", healthFactor.String())
+	assert.Empty(t, dispatches)
+	mockStore.AssertNotCalled(t, "TriggerSubscription", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEvaluateHealthFactorUpdate_InvalidHealthFactor(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	_, err := service.EvaluateHealthFactorUpdate(context.Background(), model.HealthFactorUpdate{
+		Address:      "0x123",
+		HealthFactor: "invalid",
+	})
+
+	assert.Error(t, err)
+	mockStore.AssertNotCalled(t, "GetSubscriptionsByAddress", mock.Anything, mock.Anything)
+}
+
+func TestDeliverAlert_SignsAndRetries(t *testing.T) {
+	calls := 0
+	var signatureValid bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(AlertTimestampHeader), 10, 64)
+		expected := "sha256=" + domain.SignWebhookPayload("secret", timestamp, body)
+		signatureValid = r.Header.Get(AlertSignatureHeader) == expected && r.Header.Get(AlertDeliveryHeader) == "9"
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+	mockStore.On("UpdateDelivery", mock.Anything, mock.Anything).Return(nil)
+
+	subscription := alertSubscription(1, 13, false)
+	subscription.WebhookURL = server.URL
+	delivery := &model.AlertDelivery{ID: 9, SubscriptionID: 1, Payload: `{"healthFactor":"1"}`, Status: model.AlertDeliveryPending}
+
+	err := service.DeliverAlert(context.Background(), AlertDispatch{Subscription: subscription, Delivery: delivery})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.True(t, signatureValid)
+	assert.Equal(t, model.AlertDeliveryDelivered, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+	assert.NotNil(t, delivery.DeliveredAt)
+}
+
+func TestDeliverAlert_GivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+	mockStore.On("UpdateDelivery", mock.Anything, mock.Anything).Return(nil)
+
+	subscription := alertSubscription(1, 13, false)
+	subscription.WebhookURL = server.URL
+	delivery := &model.AlertDelivery{ID: 9, SubscriptionID: 1, Payload: `{}`, Status: model.AlertDeliveryPending}
+
+	err := service.DeliverAlert(context.Background(), AlertDispatch{Subscription: subscription, Delivery: delivery})
+
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, model.AlertDeliveryFailed, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Nil(t, delivery.DeliveredAt)
+}
+
+func TestDeliverAlert_DoesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+	mockStore.On("UpdateDelivery", mock.Anything, mock.Anything).Return(nil)
+
+	subscription := alertSubscription(1, 13, false)
+	subscription.WebhookURL = server.URL
+	delivery := &model.AlertDelivery{ID: 9, SubscriptionID: 1, Payload: `{}`, Status: model.AlertDeliveryPending}
+
+	err := service.DeliverAlert(context.Background(), AlertDispatch{Subscription: subscription, Delivery: delivery})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, model.AlertDeliveryFailed, delivery.Status)
+	assert.Equal(t, http.StatusGone, delivery.ResponseStatus)
+}
+
+func TestDeliverAlert_RefusesNonPublicAddresses(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+	service.Client = newWebhookClient(time.Second, false)
+	mockStore.On("UpdateDelivery", mock.Anything, mock.Anything).Return(nil)
+
+	subscription := alertSubscription(1, 13, false)
+	subscription.WebhookURL = server.URL
+	delivery := &model.AlertDelivery{ID: 9, SubscriptionID: 1, Payload: `{}`, Status: model.AlertDeliveryPending}
+
+	err := service.DeliverAlert(context.Background(), AlertDispatch{Subscription: subscription, Delivery: delivery})
+
+	assert.ErrorIs(t, err, model.ErrWebhookNotAllowed)
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, model.AlertDeliveryFailed, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+}
+
+func TestDeliverAlert_RefusesRedirectsToNonPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+	service.Client = newWebhookClient(time.Second, true)
+	service.Client.CheckRedirect = newWebhookClient(time.Second, false).CheckRedirect
+	mockStore.On("UpdateDelivery", mock.Anything, mock.Anything).Return(nil)
+
+	subscription := alertSubscription(1, 13, false)
+	subscription.WebhookURL = server.URL
+	delivery := &model.AlertDelivery{ID: 9, SubscriptionID: 1, Payload: `{}`, Status: model.AlertDeliveryPending}
+
+	err := service.DeliverAlert(context.Background(), AlertDispatch{Subscription: subscription, Delivery: delivery})
+
+	assert.ErrorIs(t, err, model.ErrWebhookNotAllowed)
+	assert.Equal(t, 1, delivery.Attempts)
+}
+
+func TestResumeStaleDeliveries(t *testing.T) {
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+
+	mockStore.On("ClaimStalePendingDeliveries", mock.Anything, mock.MatchedBy(func(staleBefore time.Time) bool {
+		return time.Since(staleBefore) >= time.Minute
+	}), alertResumeBatchSize).Return([]model.AlertDelivery{
+		{ID: 9, SubscriptionID: 1, Address: "0x123", Attempts: 2, Status: model.AlertDeliveryPending},
+		{ID: 10, SubscriptionID: 2, Address: "0x123", Status: model.AlertDeliveryPending},
+	}, nil)
+	subscription := alertSubscription(1, 13, true)
+	mockStore.On("GetSubscription", mock.Anything, "0x123", uint(1)).Return(&subscription, nil)
+	mockStore.On("GetSubscription", mock.Anything, "0x123", uint(2)).Return(nil, nil)
+	mockStore.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(delivery *model.AlertDelivery) bool {
+		return delivery.ID == 10 && delivery.Status == model.AlertDeliveryFailed
+	})).Return(nil)
+
+	dispatches, err := service.ResumeStaleDeliveries(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, dispatches, 1)
+	assert.Equal(t, uint(9), dispatches[0].Delivery.ID)
+	assert.Equal(t, uint(1), dispatches[0].Subscription.ID)
+	mockStore.AssertExpectations(t)
+}
+
+func TestDeliverAlert_ContinuesFromLastAttempt(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+	mockStore.On("UpdateDelivery", mock.Anything, mock.Anything).Return(nil)
+
+	subscription := alertSubscription(1, 13, false)
+	subscription.WebhookURL = server.URL
+	delivery := &model.AlertDelivery{ID: 9, SubscriptionID: 1, Payload: `{}`, Attempts: 2, Status: model.AlertDeliveryPending}
+
+	err := service.DeliverAlert(context.Background(), AlertDispatch{Subscription: subscription, Delivery: delivery})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, model.AlertDeliveryFailed, delivery.Status)
+}
+
+func TestDeliverAlert_LeavesInterruptedDeliveriesPending(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	mockStore := new(MockAlertStore)
+	service := newTestAlertsService(mockStore)
+	service.RetryBaseDelay = time.Minute
+	service.RetryMaxDelay = time.Minute
+	mockStore.On("UpdateDelivery", mock.Anything, mock.Anything).Return(nil)
+
+	subscription := alertSubscription(1, 13, false)
+	subscription.WebhookURL = server.URL
+	delivery := &model.AlertDelivery{ID: 9, SubscriptionID: 1, Payload: `{}`, Status: model.AlertDeliveryPending}
+
+	err := service.DeliverAlert(ctx, AlertDispatch{Subscription: subscription, Delivery: delivery})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, model.AlertDeliveryPending, delivery.Status)
+}
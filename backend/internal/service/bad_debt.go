@@ -0,0 +1,81 @@
+package service
+
+import (
+	"math/big"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/metrics"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+const (
+	userStatusKey = "user:status"
+	insolventKey  = "insolvent"
+	badDebtKey    = "bad_debt"
+)
+
+// recordPositionStatus stores the user's position classification and keeps the insolvent set,
+// which maps each insolvent user to the debt their collateral no longer covers, in sync.
+func recordPositionStatus(cacheStore storage.ICacheStore, userAddress string, collateralUSD, debt *big.Int) (model.PositionStatus, error) {
+	logger := utils.GetLogger()
+
+	status := domain.ClassifyPosition(collateralUSD, debt)
+
+	err := cacheStore.HSet(userStatusKey, userAddress, string(status))
+	if err != nil {
+		logger.Error().Err(err).Str("user", userAddress).Msg("Failed to set user position status")
+		return status, err
+	}
+
+	if status != model.PositionStatusInsolvent {
+		if err := cacheStore.HDel(insolventKey, userAddress); err != nil {
+			logger.Error().Err(err).Str("user", userAddress).Msg("Failed to remove user from insolvent set")
+			return status, err
+		}
+		return status, nil
+	}
+
+	badDebt := domain.BadDebt(collateralUSD, debt)
+	err = cacheStore.HSet(insolventKey, userAddress, badDebt.String())
+	if err != nil {
+		logger.Error().Err(err).Str("user", userAddress).Msg("Failed to add user to insolvent set")
+		return status, err
+	}
+
+	logger.Warn().Str("user", userAddress).Str("bad_debt", badDebt.String()).Msg("Insolvent position detected")
+	return status, nil
+}
+
+// UpdateBadDebt sums the uncovered debt of every insolvent user and publishes the total
+// to Redis and Prometheus.
+func UpdateBadDebt(cacheStore storage.ICacheStore) (*big.Int, error) {
+	logger := utils.GetLogger()
+
+	insolventUsers, err := cacheStore.HGetAll(insolventKey)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get insolvent users")
+		return nil, err
+	}
+
+	totalBadDebt := big.NewInt(0)
+	for _, badDebtStr := range insolventUsers {
+		badDebt, ok := new(big.Int).SetString(badDebtStr, 10)
+		if !ok {
+			continue
+		}
+		totalBadDebt.Add(totalBadDebt, badDebt)
+	}
+
+	err = cacheStore.HSet(badDebtKey, "total", totalBadDebt.String())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to set total bad debt")
+		return nil, err
+	}
+
+	metrics.BadDebtUSD.Set(parseFloat64(totalBadDebt.String()))
+
+	logger.Info().Int("insolvent_users", len(insolventUsers)).Str("bad_debt", totalBadDebt.String()).Msg("Bad debt updated")
+	return totalBadDebt, nil
+}
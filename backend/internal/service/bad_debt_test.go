@@ -0,0 +1,72 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecordPositionStatus_Insolvent(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	// $800 of collateral against 1000 AUSD of debt leaves 200 AUSD uncovered
+	collateralUSD := big.NewInt(80000000000)
+	debt, _ := new(big.Int).SetString("1000000000000000000000", 10)
+
+	mockCache.On("HSet", "user:status", "0x123", "insolvent").Return(nil)
+	mockCache.On("HSet", "insolvent", "0x123", "200000000000000000000").Return(nil)
+
+	status, err := recordPositionStatus(mockCache, "0x123", collateralUSD, debt)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.PositionStatusInsolvent, status)
+	mockCache.AssertExpectations(t)
+}
+
+func TestRecordPositionStatus_Solvent(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	collateralUSD := big.NewInt(150000000000)
+	debt, _ := new(big.Int).SetString("1000000000000000000000", 10)
+
+	mockCache.On("HSet", "user:status", "0x123", "liquidatable").Return(nil)
+	mockCache.On("HDel", "insolvent", []string{"0x123"}).Return(nil)
+
+	status, err := recordPositionStatus(mockCache, "0x123", collateralUSD, debt)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.PositionStatusLiquidatable, status)
+	mockCache.AssertExpectations(t)
+}
+
+func TestUpdateBadDebt(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("HGetAll", "insolvent").Return(map[string]string{
+		"0x123": "200000000000000000000",
+		"0x456": "50000000000000000000",
+		"0x789": "invalid",
+	}, nil)
+	mockCache.On("HSet", "bad_debt", "total", "250000000000000000000").Return(nil)
+
+	total, err := UpdateBadDebt(mockCache)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "250000000000000000000", total.String())
+	mockCache.AssertExpectations(t)
+}
+
+func TestUpdateBadDebt_StoreError(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("HGetAll", "insolvent").Return(nil, assert.AnError)
+
+	total, err := UpdateBadDebt(mockCache)
+
+	assert.Error(t, err)
+	assert.Nil(t, total)
+	mockCache.AssertNotCalled(t, "HSet", "bad_debt", "total", mock.Anything)
+}
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/external"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
@@ -60,7 +63,9 @@ func CalculateLiquidations(priceFeed external.IPriceFeedAPI, cacheStore storage.
 
 	totalCollateralSupply := new(big.Int)
 	logger.Debug().Int("total_users", len(totalUSDCollateralByUser)).Msg("Updating liquidation health factors")
-	updateLiquidationHealthFactors(totalCollateralSupply, cacheStore, totalUSDCollateralByUser)
+	prices := map[string]string{"BTC": btcPrice, "ETH": ethPrice}
+	updateLiquidationHealthFactors(totalCollateralSupply, cacheStore, totalUSDCollateralByUser, prices)
+	reconcileLiquidatable(cacheStore, totalUSDCollateralByUser, prices)
 
 	err = cacheStore.HSet("collateral", "total_supply", totalCollateralSupply.String())
 	if err != nil {
@@ -68,6 +73,10 @@ func CalculateLiquidations(priceFeed external.IPriceFeedAPI, cacheStore storage.
 		return err
 	}
 
+	if _, err := UpdateBadDebt(cacheStore); err != nil {
+		logger.Error().Err(err).Msg("Failed to update bad debt")
+	}
+
 	logger.Info().Str("total_collateral_supply", totalCollateralSupply.String()).Msg("Liquidation calculations completed successfully")
 	return nil
 }
@@ -114,7 +123,7 @@ func updateETHCollateralMetrics(collateralSupplyByUser map[string]string, ethPri
 	}
 }
 
-func updateLiquidationHealthFactors(totalCollateralSupply *big.Int, cacheStore storage.ICacheStore, totalUSDCollateralByUser map[string]*big.Int) {
+func updateLiquidationHealthFactors(totalCollateralSupply *big.Int, cacheStore storage.ICacheStore, totalUSDCollateralByUser map[string]*big.Int, prices map[string]string) {
 	for userAddress, totalCollateralUSD := range totalUSDCollateralByUser {
 		err := cacheStore.HSet("user:collateral_usd", userAddress, totalCollateralUSD.String())
 		if err != nil {
@@ -123,25 +132,163 @@ func updateLiquidationHealthFactors(totalCollateralSupply *big.Int, cacheStore s
 
 		totalCollateralSupply.Add(totalCollateralSupply, totalCollateralUSD)
 
-		debt, err := cacheStore.HGet("user:debt", userAddress)
+		updateUserLiquidationHealthFactor(cacheStore, userAddress, totalCollateralUSD, prices)
+	}
+}
+
+func updateUserLiquidationHealthFactor(cacheStore storage.ICacheStore, userAddress string, totalCollateralUSD *big.Int, prices map[string]string) error {
+	debt, err := cacheStore.HGet("user:debt", userAddress)
+	if errors.Is(err, storage.ErrCacheMiss) {
+		debt = "0"
+	} else if err != nil {
+		return err
+	}
+
+	debtBigInt := new(big.Int)
+	_, ok := debtBigInt.SetString(debt, 10)
+	if !ok {
+		return fmt.Errorf("invalid debt %q for user %s", debt, userAddress)
+	}
+
+	healthFactor := domain.CalculateHealthFactor(totalCollateralUSD, debtBigInt)
+
+	return RecordHealthFactor(cacheStore, model.HealthFactorSnapshot{
+		UserAddress:   userAddress,
+		HealthFactor:  healthFactor.String(),
+		CollateralUSD: totalCollateralUSD.String(),
+		Debt:          debtBigInt.String(),
+		Source:        model.HealthFactorSourceLiquidationScan,
+		Prices:        prices,
+	})
+}
+
+// reconcileLiquidatable re-evaluates members of the liquidatable set the scan did not reach. The scan
+// only covers users holding collateral, so these positions were fully redeemed or seized and are
+// recorded with no collateral, which moves them out of the set once their debt is repaid. Since
+// timestamps left without a member are then dropped.
+func reconcileLiquidatable(cacheStore storage.ICacheStore, scanned map[string]*big.Int, prices map[string]string) {
+	logger := utils.GetLogger()
+
+	members, err := cacheStore.HGetAll("liquidatable")
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get liquidatable users")
+		return
+	}
+
+	for userAddress := range members {
+		if _, ok := scanned[userAddress]; ok {
+			continue
+		}
+
+		if err := cacheStore.HSet("user:collateral_usd", userAddress, "0"); err != nil {
+			logger.Error().Err(err).Str("user", userAddress).Msg("Failed to set user collateral USD")
+			continue
+		}
+		if err := updateUserLiquidationHealthFactor(cacheStore, userAddress, big.NewInt(0), prices); err != nil {
+			logger.Error().Err(err).Str("user", userAddress).Msg("Failed to reconcile liquidatable user")
+		}
+	}
+
+	pruned, err := cacheStore.PruneLiquidatableSince()
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to prune liquidatable since timestamps")
+		return
+	}
+	if pruned > 0 {
+		logger.Info().Int64("pruned", pruned).Msg("Pruned stale liquidatable since timestamps")
+	}
+}
+
+// ReevaluateTokenHolders recomputes the health factor of every user holding the given collateral token
+// with the given prices, so a price move only touches the positions it can affect and they are valued
+// at the price that triggered the move.
+func ReevaluateTokenHolders(cacheStore storage.ICacheStore, tokenName string, prices map[string]string) error {
+	logger := utils.GetLogger()
+	logger.Info().Str("token", tokenName).Msg("Re-evaluating health factors of token holders")
+
+	tokenAddress, exists := constants.CollateralTokens[tokenName]
+	if !exists {
+		return fmt.Errorf("unknown collateral token %q", tokenName)
+	}
+
+	holders, err := cacheStore.HGetAll("collateral:" + tokenAddress)
+	if err != nil {
+		logger.Error().Err(err).Str("token", tokenName).Msg("Failed to get token holders")
+		return err
+	}
+
+	reevaluated := 0
+	for userAddress, amountStr := range holders {
+		amount, ok := new(big.Int).SetString(amountStr, 10)
+		if !ok || amount.Sign() <= 0 {
+			continue
+		}
+
+		totalCollateralUSD, err := getUserCollateralUSD(cacheStore, userAddress, prices)
 		if err != nil {
+			logger.Error().Err(err).Str("user", userAddress).Msg("Failed to get user collateral USD")
 			continue
 		}
 
-		debtBigInt := new(big.Int)
-		_, ok := debtBigInt.SetString(debt, 10)
-        if !ok {
-            continue
-        }
+		err = cacheStore.HSet("user:collateral_usd", userAddress, totalCollateralUSD.String())
+		if err != nil {
+			logger.Error().Err(err).Str("user", userAddress).Msg("Failed to set user collateral USD")
+			continue
+		}
 
-		healthFactor := domain.CalculateHealthFactor(totalCollateralUSD, debtBigInt)
-		if healthFactor.Cmp(constants.MIN_HEALTH_FACTOR) < 0 {
-			err := cacheStore.HSet("liquidatable", userAddress, healthFactor.String())
-			if err != nil {
-				continue
-			}
+		if err := updateUserLiquidationHealthFactor(cacheStore, userAddress, totalCollateralUSD, prices); err != nil {
+			logger.Debug().Err(err).Str("user", userAddress).Msg("Skipping health factor update")
+			continue
+		}
+		reevaluated++
+	}
+
+	if _, err := UpdateBadDebt(cacheStore); err != nil {
+		logger.Error().Err(err).Msg("Failed to update bad debt")
+	}
+
+	logger.Info().Str("token", tokenName).Int("holders", len(holders)).Int("reevaluated", reevaluated).Msg("Token holders re-evaluated successfully")
+	return nil
+}
+
+// GetCollateralPrices fetches the current USD price of every configured collateral token, keyed by token name.
+func GetCollateralPrices(priceFeed external.IPriceFeedAPI) (map[string]string, error) {
+	prices := make(map[string]string, len(constants.CollateralTokens))
+	for name := range constants.CollateralTokens {
+		price, err := getTokenPrice(priceFeed, name)
+		if err != nil {
+			return nil, err
+		}
+		prices[name] = price
+	}
+	return prices, nil
+}
+
+// getUserCollateralUSD values the user's collateral with the given prices. A token the user holds
+// none of counts as zero; any other failure is returned, so a partial read never undervalues the
+// position.
+func getUserCollateralUSD(cacheStore storage.ICacheStore, userAddress string, prices map[string]string) (*big.Int, error) {
+	totalCollateralUSD := big.NewInt(0)
+	for name, tokenAddress := range constants.CollateralTokens {
+		amountStr, found, err := getCachedField(cacheStore, "collateral:"+tokenAddress, userAddress)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+
+		amount, ok := new(big.Int).SetString(amountStr, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s collateral %q for user %s", name, amountStr, userAddress)
+		}
+
+		usdValue, err := domain.GetTokenAmountInUSD(amount, prices[name])
+		if err != nil {
+			return nil, fmt.Errorf("value %s collateral for user %s: %w", name, userAddress, err)
 		}
 
-		cacheStore.HSet("user:health_factor", userAddress, healthFactor.String())
+		totalCollateralUSD.Add(totalCollateralUSD, usdValue)
 	}
+	return totalCollateralUSD, nil
 }
@@ -1,10 +1,14 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
 	"math/big"
 	"testing"
 
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -33,9 +37,18 @@ func TestCalculateLiquidations_Success(t *testing.T) {
 
 	mockCache.On("HGet", "user:debt", mock.Anything).Return("1000000000000000000", nil)
 	mockCache.On("HSet", "user:collateral_usd", mock.Anything, mock.Anything).Return(nil)
-	mockCache.On("HSet", "user:health_factor", mock.Anything, mock.Anything).Return(nil)
-	mockCache.On("HSet", "liquidatable", mock.Anything, mock.Anything).Return(nil)
+	mockCache.On("SetHealthFactor", mock.Anything, mock.Anything, false, mock.Anything).Return(storage.HealthFactorChange{}, nil)
+	mockCache.On("XAdd", mock.Anything, mock.Anything, mock.Anything).Return("1700000000000-0", nil)
+	mockCache.On("XTrimMinID", mock.Anything, mock.Anything).Return(nil)
+	mockCache.On("Publish", constants.HEALTH_FACTORS_CHANNEL, mock.Anything).Return(nil)
+	mockCache.On("HGetAll", "liquidatable").Return(map[string]string{}, nil)
+	mockCache.On("PruneLiquidatableSince").Return(int64(0), nil)
+	mockCache.On("HSet", "user:status", mock.Anything, mock.Anything).Return(nil)
+	mockCache.On("HSet", "insolvent", mock.Anything, mock.Anything).Return(nil)
+	mockCache.On("HDel", "insolvent", mock.Anything).Return(nil)
 	mockCache.On("HSet", "collateral", "total_supply", mock.Anything).Return(nil)
+	mockCache.On("HGetAll", "insolvent").Return(map[string]string{}, nil)
+	mockCache.On("HSet", "bad_debt", "total", "0").Return(nil)
 
 	err := CalculateLiquidations(mockPriceFeed, mockCache)
 
@@ -120,10 +133,144 @@ func TestUpdateLiquidationHealthFactors(t *testing.T) {
 
 	mockCache.On("HSet", "user:collateral_usd", "0x123", "50000").Return(nil)
 	mockCache.On("HGet", "user:debt", "0x123").Return("10000", nil)
-	mockCache.On("HSet", "user:health_factor", "0x123", mock.Anything).Return(nil)
+	mockCache.On("SetHealthFactor", "0x123", mock.Anything, false, mock.Anything).Return(storage.HealthFactorChange{}, nil)
+	mockCache.On("XAdd", "user:health_factor:history:0x123", mock.Anything, mock.Anything).Return("1700000000000-0", nil)
+	mockCache.On("XTrimMinID", "user:health_factor:history:0x123", mock.Anything).Return(nil)
+	mockCache.On("Publish", constants.HEALTH_FACTORS_CHANNEL, mock.Anything).Return(nil)
+	mockCache.On("HSet", "user:status", "0x123", "healthy").Return(nil)
+	mockCache.On("HDel", "insolvent", []string{"0x123"}).Return(nil)
 
-	updateLiquidationHealthFactors(totalCollateralSupply, mockCache, totalUSDCollateralByUser)
+	updateLiquidationHealthFactors(totalCollateralSupply, mockCache, totalUSDCollateralByUser, map[string]string{"ETH": "2000"})
 
 	assert.Equal(t, "50000", totalCollateralSupply.String())
 	mockCache.AssertExpectations(t)
 }
+
+func TestReconcileLiquidatable(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("HGetAll", "liquidatable").Return(map[string]string{"0x123": "0", "0x456": "0"}, nil)
+	mockCache.On("HSet", "user:collateral_usd", "0x456", "0").Return(nil)
+	mockCache.On("HGet", "user:debt", "0x456").Return("", storage.ErrCacheMiss)
+	mockCache.On("SetHealthFactor", "0x456", "0", false, mock.Anything).Return(storage.HealthFactorChange{
+		PreviousHealthFactor: "0",
+		Transition:           storage.LiquidatableExited,
+		Since:                "1700000000",
+	}, nil)
+	mockCache.On("XAdd", "user:health_factor:history:0x456", mock.Anything, mock.Anything).Return("1700000000000-0", nil)
+	mockCache.On("XTrimMinID", "user:health_factor:history:0x456", mock.Anything).Return(nil)
+	mockCache.On("Publish", constants.HEALTH_FACTORS_CHANNEL, mock.Anything).Return(nil)
+	mockCache.On("HGet", "sync", "last_block").Return("42", nil)
+	mockCache.On("XAdd", "liquidations:transitions", mock.Anything, mock.Anything).Return("1700000000000-1", nil)
+	mockCache.On("Publish", constants.LIQUIDATIONS_CHANNEL, mock.MatchedBy(func(message string) bool {
+		var transition model.LiquidationTransition
+		if err := json.Unmarshal([]byte(message), &transition); err != nil {
+			return false
+		}
+		return transition.Address == "0x456" && transition.Direction == model.LiquidationTransitionExit
+	})).Return(nil)
+	mockCache.On("HSet", "user:status", "0x456", "healthy").Return(nil)
+	mockCache.On("HDel", "insolvent", []string{"0x456"}).Return(nil)
+	mockCache.On("PruneLiquidatableSince").Return(int64(1), nil)
+
+	reconcileLiquidatable(mockCache, map[string]*big.Int{"0x123": big.NewInt(50000)}, map[string]string{"ETH": "2000"})
+
+	mockCache.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "SetHealthFactor", "0x123", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReevaluateTokenHolders(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	constants.CollateralTokens["BTC"] = "0xbtcaddress"
+	defer func() {
+		delete(constants.CollateralTokens, "ETH")
+		delete(constants.CollateralTokens, "BTC")
+	}()
+
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("HGetAll", "collateral:0xethaddress").Return(map[string]string{
+		"0x123": "1000000000000000000",
+		"0x456": "0",
+	}, nil)
+	mockCache.On("HGet", "collateral:0xethaddress", "0x123").Return("1000000000000000000", nil)
+	mockCache.On("HGet", "collateral:0xbtcaddress", "0x123").Return("", storage.ErrCacheMiss)
+	mockCache.On("HSet", "user:collateral_usd", "0x123", "200000000000").Return(nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("1500000000000000000000", nil)
+	mockCache.On("SetHealthFactor", "0x123", "666666666666666666", true, mock.Anything).Return(storage.HealthFactorChange{
+		PreviousHealthFactor: "1000000000000000000",
+		Transition:           storage.LiquidatableEntered,
+	}, nil)
+	mockCache.On("XAdd", "user:health_factor:history:0x123", mock.Anything, mock.Anything).Return("1700000000000-0", nil)
+	mockCache.On("XTrimMinID", "user:health_factor:history:0x123", mock.Anything).Return(nil)
+	mockCache.On("Publish", constants.HEALTH_FACTORS_CHANNEL, mock.Anything).Return(nil)
+	mockCache.On("HGet", "sync", "last_block").Return("42", nil)
+	mockCache.On("XAdd", "liquidations:transitions", mock.Anything, mock.MatchedBy(func(values map[string]any) bool {
+		var transition model.LiquidationTransition
+		if err := json.Unmarshal([]byte(values["transition"].(string)), &transition); err != nil {
+			return false
+		}
+		return transition.BlockNumber == 42 && transition.Prices["ETH"] == "2000" && transition.Prices["BTC"] == "40000"
+	})).Return("1700000000000-0", nil)
+	mockCache.On("Publish", constants.LIQUIDATIONS_CHANNEL, mock.Anything).Return(nil)
+	mockCache.On("HSet", "user:status", "0x123", "liquidatable").Return(nil)
+	mockCache.On("HDel", "insolvent", []string{"0x123"}).Return(nil)
+	mockCache.On("HGetAll", "insolvent").Return(map[string]string{"0x999": "5"}, nil)
+	mockCache.On("HSet", "bad_debt", "total", "5").Return(nil)
+
+	err := ReevaluateTokenHolders(mockCache, "ETH", map[string]string{"ETH": "2000", "BTC": "40000"})
+
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "HGet", "collateral:0xbtcaddress", "0x456")
+}
+
+func TestReevaluateTokenHolders_SkipsUsersOnCacheErrors(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	constants.CollateralTokens["BTC"] = "0xbtcaddress"
+	defer func() {
+		delete(constants.CollateralTokens, "ETH")
+		delete(constants.CollateralTokens, "BTC")
+	}()
+
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("HGetAll", "collateral:0xethaddress").Return(map[string]string{"0x123": "1000000000000000000"}, nil)
+	mockCache.On("HGet", "collateral:0xethaddress", "0x123").Return("1000000000000000000", nil)
+	mockCache.On("HGet", "collateral:0xbtcaddress", "0x123").Return("", assert.AnError)
+	mockCache.On("HGetAll", "insolvent").Return(map[string]string{}, nil)
+	mockCache.On("HSet", "bad_debt", "total", "0").Return(nil)
+
+	err := ReevaluateTokenHolders(mockCache, "ETH", map[string]string{"ETH": "2000", "BTC": "40000"})
+
+	assert.NoError(t, err)
+	mockCache.AssertNotCalled(t, "HSet", "user:collateral_usd", mock.Anything, mock.Anything)
+	mockCache.AssertNotCalled(t, "SetHealthFactor", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReevaluateTokenHolders_UnknownToken(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	err := ReevaluateTokenHolders(mockCache, "DOGE", map[string]string{})
+
+	assert.Error(t, err)
+	mockCache.AssertNotCalled(t, "HGetAll", mock.Anything)
+}
+
+func TestGetCollateralPrices(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	constants.CollateralTokens["BTC"] = "0xbtcaddress"
+	defer func() {
+		delete(constants.CollateralTokens, "ETH")
+		delete(constants.CollateralTokens, "BTC")
+	}()
+
+	mockPriceFeed := new(MockPriceFeedAPI)
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockPriceFeed.On("GetBtcUsdPrice").Return("", errors.New("btc price error"))
+
+	_, err := GetCollateralPrices(mockPriceFeed)
+
+	assert.ErrorIs(t, err, model.ErrPriceUnavailable)
+	assert.ErrorContains(t, err, "btc price error")
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+const dailyStatsDayFormat = "2006-01-02"
+
+type DailyStatsStore interface {
+	GetDailyActivity(ctx context.Context, from, to time.Time) (model.DailyActivity, error)
+	GetPendingRollupStart(ctx context.Context) (*time.Time, error)
+	GetLastRolledUpDay(ctx context.Context) (*time.Time, error)
+	GetDailyCollateralStats(ctx context.Context, day time.Time) ([]model.DailyCollateralStats, error)
+	SaveDailyStats(ctx context.Context, stats model.DailyProtocolStats, collateral []model.DailyCollateralStats) error
+	GetDailyStats(ctx context.Context, from, to time.Time) ([]model.DailyProtocolStats, []model.DailyCollateralStats, error)
+}
+
+type StatsPriceStore interface {
+	GetPriceInBlock(tokenName string, blockNumber uint64) (*string, error)
+}
+
+type dailyStatsService struct {
+	Store  DailyStatsStore
+	Prices StatsPriceStore
+}
+
+func NewDailyStatsService(store DailyStatsStore, prices StatsPriceStore) *dailyStatsService {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Initializing daily stats service")
+	return &dailyStatsService{
+		Store:  store,
+		Prices: prices,
+	}
+}
+
+// RollupDailyStats rolls up every day from the first one with events no rollup has seen, or the last
+// rolled up day if that is earlier, through the day of until. The first run backfills all history.
+// Collateral is valued with the latest price indexed by the day's last block.
+func (s *dailyStatsService) RollupDailyStats(ctx context.Context, until time.Time) error {
+	logger := utils.GetLogger()
+
+	pending, err := s.Store.GetPendingRollupStart(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to find pending daily stats")
+		return err
+	}
+	if pending == nil {
+		logger.Debug().Msg("Daily stats are up to date")
+		return nil
+	}
+
+	start := domain.StartOfDay(*pending)
+	last, err := s.Store.GetLastRolledUpDay(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get last rolled up day")
+		return err
+	}
+	if last != nil && last.Before(start) {
+		start = domain.StartOfDay(*last)
+	}
+
+	end := domain.StartOfDay(until)
+	if end.Before(start) {
+		end = start
+	}
+
+	previous, err := s.Store.GetDailyCollateralStats(ctx, start.AddDate(0, 0, -1))
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get previous day collateral stats")
+		return err
+	}
+
+	logger.Info().Str("from", start.Format(dailyStatsDayFormat)).Str("to", end.Format(dailyStatsDayFormat)).Msg("Rolling up daily stats")
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		activity, err := s.Store.GetDailyActivity(ctx, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			logger.Error().Err(err).Str("day", day.Format(dailyStatsDayFormat)).Msg("Failed to aggregate daily activity")
+			return err
+		}
+
+		collateral := domain.RollDailyCollateral(day, previous, activity.Collateral)
+		for i := range collateral {
+			collateral[i].TVLUSD = model.NewBigInt(s.collateralValue(collateral[i], activity.LastBlockNumber))
+		}
+
+		stats := model.DailyProtocolStats{
+			Day:              day,
+			MintVolume:       model.NewBigInt(activity.MintVolume.Int),
+			BurnVolume:       model.NewBigInt(activity.BurnVolume.Int),
+			LiquidationCount: activity.LiquidationCount,
+			NewUsers:         activity.NewUsers,
+			TVLUSD:           model.NewBigInt(domain.SumTVL(collateral)),
+			LastBlockNumber:  activity.LastBlockNumber,
+		}
+		if err := s.Store.SaveDailyStats(ctx, stats, collateral); err != nil {
+			logger.Error().Err(err).Str("day", day.Format(dailyStatsDayFormat)).Msg("Failed to save daily stats")
+			return err
+		}
+
+		previous = collateral
+	}
+
+	logger.Info().Str("to", end.Format(dailyStatsDayFormat)).Msg("Daily stats rolled up successfully")
+	return nil
+}
+
+func (s *dailyStatsService) collateralValue(stats model.DailyCollateralStats, blockNumber uint64) *big.Int {
+	if stats.Balance.Int.Sign() <= 0 || blockNumber == 0 {
+		return big.NewInt(0)
+	}
+
+	price, err := s.Prices.GetPriceInBlock(collateralSymbol(stats.CollateralAddress), blockNumber)
+	if err != nil || price == nil {
+		logger := utils.GetLogger()
+		logger.Warn().Err(err).Str("collateral", stats.CollateralAddress).Uint64("block", blockNumber).Msg("No price to value daily collateral")
+		return big.NewInt(0)
+	}
+
+	value, err := domain.GetTokenAmountInUSD(stats.Balance.Int, *price)
+	if err != nil {
+		return big.NewInt(0)
+	}
+	return value
+}
+
+// GetDailyStats returns the stored rollups for every day between query.From and query.To.
+func (s *dailyStatsService) GetDailyStats(ctx context.Context, query model.DailyStatsQuery) (model.DailyStats, error) {
+	logger := utils.GetLogger()
+
+	from, to := domain.StartOfDay(query.From), domain.StartOfDay(query.To)
+	logger.Info().Str("from", from.Format(dailyStatsDayFormat)).Str("to", to.Format(dailyStatsDayFormat)).Msg("Getting daily stats")
+
+	stats, collateral, err := s.Store.GetDailyStats(ctx, from, to)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get daily stats")
+		return model.DailyStats{}, err
+	}
+
+	collateralByDay := make(map[string][]model.DailyCollateralStat, len(stats))
+	for _, c := range collateral {
+		day := c.Day.UTC().Format(dailyStatsDayFormat)
+		collateralByDay[day] = append(collateralByDay[day], model.DailyCollateralStat{
+			Asset:            c.CollateralAddress,
+			Token:            collateralSymbol(c.CollateralAddress),
+			DepositVolume:    c.DepositVolume.Int.String(),
+			RedeemVolume:     c.RedeemVolume.Int.String(),
+			LiquidatedAmount: c.LiquidatedAmount.Int.String(),
+			Balance:          c.Balance.Int.String(),
+			TVLUSD:           c.TVLUSD.Int.String(),
+		})
+	}
+
+	result := model.DailyStats{
+		From: from.Format(dailyStatsDayFormat),
+		To:   to.Format(dailyStatsDayFormat),
+		Days: make([]model.DailyStat, 0, len(stats)),
+	}
+	for _, day := range stats {
+		key := day.Day.UTC().Format(dailyStatsDayFormat)
+		daily := model.DailyStat{
+			Day:              key,
+			MintVolume:       day.MintVolume.Int.String(),
+			BurnVolume:       day.BurnVolume.Int.String(),
+			LiquidationCount: day.LiquidationCount,
+			NewUsers:         day.NewUsers,
+			TVLUSD:           day.TVLUSD.Int.String(),
+			Collateral:       collateralByDay[key],
+		}
+		if daily.Collateral == nil {
+			daily.Collateral = []model.DailyCollateralStat{}
+		}
+		result.Days = append(result.Days, daily)
+	}
+
+	logger.Info().Int("days", len(result.Days)).Msg("Daily stats retrieved successfully")
+	return result, nil
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDailyStatsStore struct {
+	mock.Mock
+}
+
+func (m *MockDailyStatsStore) GetDailyActivity(ctx context.Context, from, to time.Time) (model.DailyActivity, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).(model.DailyActivity), args.Error(1)
+}
+
+func (m *MockDailyStatsStore) GetPendingRollupStart(ctx context.Context) (*time.Time, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockDailyStatsStore) GetLastRolledUpDay(ctx context.Context) (*time.Time, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockDailyStatsStore) GetDailyCollateralStats(ctx context.Context, day time.Time) ([]model.DailyCollateralStats, error) {
+	args := m.Called(ctx, day)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.DailyCollateralStats), args.Error(1)
+}
+
+func (m *MockDailyStatsStore) SaveDailyStats(ctx context.Context, stats model.DailyProtocolStats, collateral []model.DailyCollateralStats) error {
+	args := m.Called(ctx, stats, collateral)
+	return args.Error(0)
+}
+
+func (m *MockDailyStatsStore) GetDailyStats(ctx context.Context, from, to time.Time) ([]model.DailyProtocolStats, []model.DailyCollateralStats, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]model.DailyProtocolStats), args.Get(1).([]model.DailyCollateralStats), args.Error(2)
+}
+
+type MockStatsPriceStore struct {
+	mock.Mock
+}
+
+func (m *MockStatsPriceStore) GetPriceInBlock(tokenName string, blockNumber uint64) (*string, error) {
+	args := m.Called(tokenName, blockNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*string), args.Error(1)
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRollupDailyStats_Backfill(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xEth"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	store := new(MockDailyStatsStore)
+	prices := new(MockStatsPriceStore)
+	service := NewDailyStatsService(store, prices)
+
+	ctx := context.Background()
+	firstEvent := time.Date(2024, 3, 4, 18, 30, 0, 0, time.UTC)
+	price := "2000"
+
+	store.On("GetPendingRollupStart", ctx).Return(&firstEvent, nil)
+	store.On("GetLastRolledUpDay", ctx).Return(nil, nil)
+	store.On("GetDailyCollateralStats", ctx, day(2024, 3, 3)).Return([]model.DailyCollateralStats{}, nil)
+	store.On("GetDailyActivity", ctx, day(2024, 3, 4), day(2024, 3, 5)).Return(model.DailyActivity{
+		MintVolume:       wei(1000),
+		BurnVolume:       wei(0),
+		LiquidationCount: 0,
+		NewUsers:         1,
+		LastBlockNumber:  10,
+		Collateral:       []model.DailyCollateralFlow{{CollateralAddress: "0xEth", DepositVolume: wei(2), RedeemVolume: wei(0), LiquidatedAmount: wei(0)}},
+	}, nil)
+	store.On("GetDailyActivity", ctx, day(2024, 3, 5), day(2024, 3, 6)).Return(model.DailyActivity{
+		MintVolume:       wei(0),
+		BurnVolume:       wei(100),
+		LiquidationCount: 1,
+		LastBlockNumber:  20,
+		Collateral:       []model.DailyCollateralFlow{{CollateralAddress: "0xEth", DepositVolume: wei(0), RedeemVolume: wei(0), LiquidatedAmount: wei(1)}},
+	}, nil)
+	prices.On("GetPriceInBlock", "ETH", uint64(10)).Return(&price, nil)
+	prices.On("GetPriceInBlock", "ETH", uint64(20)).Return(nil, nil)
+
+	var saved []model.DailyProtocolStats
+	var savedCollateral [][]model.DailyCollateralStats
+	store.On("SaveDailyStats", ctx, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(1).(model.DailyProtocolStats))
+		savedCollateral = append(savedCollateral, args.Get(2).([]model.DailyCollateralStats))
+	}).Return(nil)
+
+	err := service.RollupDailyStats(ctx, time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Len(t, saved, 2)
+
+	assert.Equal(t, day(2024, 3, 4), saved[0].Day)
+	assert.Equal(t, wei(1000).Int.String(), saved[0].MintVolume.Int.String())
+	assert.Equal(t, int64(1), saved[0].NewUsers)
+	assert.Equal(t, uint64(10), saved[0].LastBlockNumber)
+	assert.Equal(t, "400000000000", saved[0].TVLUSD.Int.String())
+	assert.Equal(t, wei(2).Int.String(), savedCollateral[0][0].Balance.Int.String())
+
+	assert.Equal(t, day(2024, 3, 5), saved[1].Day)
+	assert.Equal(t, int64(1), saved[1].LiquidationCount)
+	assert.Equal(t, wei(1).Int.String(), savedCollateral[1][0].Balance.Int.String())
+	assert.Equal(t, "0", saved[1].TVLUSD.Int.String())
+	store.AssertExpectations(t)
+}
+
+func TestRollupDailyStats_ResumesFromLastDay(t *testing.T) {
+	store := new(MockDailyStatsStore)
+	service := NewDailyStatsService(store, new(MockStatsPriceStore))
+
+	ctx := context.Background()
+	pending := time.Date(2024, 3, 6, 1, 0, 0, 0, time.UTC)
+	last := day(2024, 3, 5)
+	previous := []model.DailyCollateralStats{{CollateralAddress: "0xbtc", Balance: model.BigInt{Int: big.NewInt(0)}}}
+
+	store.On("GetPendingRollupStart", ctx).Return(&pending, nil)
+	store.On("GetLastRolledUpDay", ctx).Return(&last, nil)
+	store.On("GetDailyCollateralStats", ctx, day(2024, 3, 4)).Return(previous, nil)
+	store.On("GetDailyActivity", ctx, mock.Anything, mock.Anything).Return(model.DailyActivity{MintVolume: wei(0), BurnVolume: wei(0)}, nil)
+	store.On("SaveDailyStats", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	err := service.RollupDailyStats(ctx, pending)
+
+	assert.NoError(t, err)
+	store.AssertCalled(t, "GetDailyActivity", ctx, day(2024, 3, 5), day(2024, 3, 6))
+	store.AssertCalled(t, "GetDailyActivity", ctx, day(2024, 3, 6), day(2024, 3, 7))
+	store.AssertNumberOfCalls(t, "SaveDailyStats", 2)
+}
+
+func TestRollupDailyStats_UpToDate(t *testing.T) {
+	store := new(MockDailyStatsStore)
+	service := NewDailyStatsService(store, new(MockStatsPriceStore))
+
+	ctx := context.Background()
+	store.On("GetPendingRollupStart", ctx).Return(nil, nil)
+
+	err := service.RollupDailyStats(ctx, time.Now())
+
+	assert.NoError(t, err)
+	store.AssertNotCalled(t, "GetDailyActivity", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRollupDailyStats_SaveError(t *testing.T) {
+	store := new(MockDailyStatsStore)
+	service := NewDailyStatsService(store, new(MockStatsPriceStore))
+
+	ctx := context.Background()
+	pending := day(2024, 3, 4)
+	store.On("GetPendingRollupStart", ctx).Return(&pending, nil)
+	store.On("GetLastRolledUpDay", ctx).Return(nil, nil)
+	store.On("GetDailyCollateralStats", ctx, day(2024, 3, 3)).Return([]model.DailyCollateralStats{}, nil)
+	store.On("GetDailyActivity", ctx, mock.Anything, mock.Anything).Return(model.DailyActivity{MintVolume: wei(0), BurnVolume: wei(0)}, nil)
+	store.On("SaveDailyStats", ctx, mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+	err := service.RollupDailyStats(ctx, day(2024, 3, 10))
+
+	assert.EqualError(t, err, "database error")
+	store.AssertNumberOfCalls(t, "SaveDailyStats", 1)
+}
+
+func TestGetDailyStats(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xEth"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	store := new(MockDailyStatsStore)
+	service := NewDailyStatsService(store, new(MockStatsPriceStore))
+
+	ctx := context.Background()
+	store.On("GetDailyStats", ctx, day(2024, 3, 4), day(2024, 3, 5)).Return(
+		[]model.DailyProtocolStats{
+			{Day: day(2024, 3, 4), MintVolume: wei(10), BurnVolume: wei(0), NewUsers: 2, TVLUSD: model.BigInt{Int: big.NewInt(5)}},
+			{Day: day(2024, 3, 5), MintVolume: wei(0), BurnVolume: wei(1), LiquidationCount: 1, TVLUSD: model.BigInt{Int: big.NewInt(0)}},
+		},
+		[]model.DailyCollateralStats{
+			{Day: day(2024, 3, 4), CollateralAddress: "0xeth", DepositVolume: wei(1), RedeemVolume: wei(0), LiquidatedAmount: wei(0), Balance: wei(1), TVLUSD: model.BigInt{Int: big.NewInt(5)}},
+		},
+		nil,
+	)
+
+	stats, err := service.GetDailyStats(ctx, model.DailyStatsQuery{From: time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC), To: time.Date(2024, 3, 5, 23, 0, 0, 0, time.UTC)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-03-04", stats.From)
+	assert.Equal(t, "2024-03-05", stats.To)
+	assert.Len(t, stats.Days, 2)
+	assert.Equal(t, "2024-03-04", stats.Days[0].Day)
+	assert.Equal(t, int64(2), stats.Days[0].NewUsers)
+	assert.Equal(t, []model.DailyCollateralStat{{Asset: "0xeth", Token: "ETH", DepositVolume: wei(1).Int.String(), RedeemVolume: "0", LiquidatedAmount: "0", Balance: wei(1).Int.String(), TVLUSD: "5"}}, stats.Days[0].Collateral)
+	assert.NotNil(t, stats.Days[1].Collateral)
+	assert.Empty(t, stats.Days[1].Collateral)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
 	"time"
 
@@ -46,6 +47,11 @@ func (s *dashboardMetricsService) GetDashboardMetrics(ctx context.Context) (mode
 	
 	metrics.LiquidatableUsers.Set(float64(len(liquidatableUsers)))
 
+	insolventPositions, badDebt := s.getInsolventPositions()
+	logger.Debug().Int("count", len(insolventPositions)).Str("bad_debt", badDebt.String()).Msg("Insolvent positions retrieved")
+
+	metrics.BadDebtUSD.Set(parseFloat64(badDebt.String()))
+
 	totalCollateral, err := s.getTotalCollateral()
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to get total collateral, using default")
@@ -81,13 +87,33 @@ func (s *dashboardMetricsService) GetDashboardMetrics(ctx context.Context) (mode
 	logger.Info().Msg("Dashboard metrics aggregation completed successfully")
 
 	return model.DashboardMetrics{
-		LiquidatableUsers: liquidatableUsers,
-		TotalCollateral:   totalCollateral,
-		StableSupply:      stableSupply,
-		ProtocolHealth:    protocolHealth,
+		LiquidatableUsers:  liquidatableUsers,
+		InsolventPositions: insolventPositions,
+		BadDebt:            badDebt.String(),
+		TotalCollateral:    totalCollateral,
+		StableSupply:       stableSupply,
+		ProtocolHealth:     protocolHealth,
 	}, nil
 }
 
+// PublishDashboardUpdate notifies live dashboard subscribers that protocol-wide state changed.
+func PublishDashboardUpdate(cacheStore storage.ICacheStore, blockNumber uint64) {
+	logger := utils.GetLogger()
+
+	payload, err := json.Marshal(model.DashboardUpdate{
+		BlockNumber: blockNumber,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to encode dashboard update")
+		return
+	}
+
+	if err := cacheStore.Publish(constants.DASHBOARD_CHANNEL, string(payload)); err != nil {
+		logger.Error().Err(err).Msg("Failed to publish dashboard update")
+	}
+}
+
 func (s *dashboardMetricsService) getLiquidatableUsers() ([]model.LiquidatableUser, error) {
 	liquidatableMap, err := s.Store.HGetAll("liquidatable")
 	if err != nil {
@@ -123,6 +149,49 @@ func (s *dashboardMetricsService) getLiquidatableUsers() ([]model.LiquidatableUs
 	return users, nil
 }
 
+func (s *dashboardMetricsService) getInsolventPositions() ([]model.InsolventPosition, *big.Int) {
+	positions := []model.InsolventPosition{}
+	totalBadDebt := big.NewInt(0)
+
+	insolventMap, err := s.Store.HGetAll(insolventKey)
+	if err != nil {
+		return positions, totalBadDebt
+	}
+
+	for address, badDebtStr := range insolventMap {
+		badDebt, ok := new(big.Int).SetString(badDebtStr, 10)
+		if !ok {
+			continue
+		}
+		totalBadDebt.Add(totalBadDebt, badDebt)
+
+		healthFactor, err := s.Store.HGet("user:health_factor", address)
+		if err != nil {
+			healthFactor = "0"
+		}
+
+		collateralUsd, err := s.Store.HGet("user:collateral_usd", address)
+		if err != nil {
+			collateralUsd = "0"
+		}
+
+		debtUsd, err := s.Store.HGet("user:debt", address)
+		if err != nil {
+			debtUsd = "0"
+		}
+
+		positions = append(positions, model.InsolventPosition{
+			Address:       address,
+			HealthFactor:  healthFactor,
+			CollateralUsd: collateralUsd,
+			DebtUsd:       debtUsd,
+			BadDebt:       badDebt.String(),
+		})
+	}
+
+	return positions, totalBadDebt
+}
+
 func (s *dashboardMetricsService) getTotalCollateral() (model.TotalCollateral, error) {
 	totalCollateralStr, err := s.Store.HGet("collateral", "total_supply")
 	if err != nil {
@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -180,3 +183,19 @@ func TestGetProtocolHealth_NoUsers(t *testing.T) {
 	assert.Equal(t, float64(0), health.AverageHealthFactor)
 	assert.Equal(t, 0, health.UsersAtRisk)
 }
+
+func TestPublishDashboardUpdate(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("Publish", constants.DASHBOARD_CHANNEL, mock.MatchedBy(func(payload string) bool {
+		var update model.DashboardUpdate
+		if err := json.Unmarshal([]byte(payload), &update); err != nil {
+			return false
+		}
+		return update.BlockNumber == 42 && update.Timestamp != ""
+	})).Return(nil)
+
+	PublishDashboardUpdate(mockCache, 42)
+
+	mockCache.AssertExpectations(t)
+}
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
+	"sort"
+	"strings"
 
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/external"
@@ -12,6 +16,13 @@ import (
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
 )
 
+// engineUSDDecimals is the precision of the USD values AUSDEngine computes. Cached collateral values
+// have usdDecimals, like prices.
+const (
+	engineUSDDecimals = 18
+	usdSymbol         = "USD"
+)
+
 type healthFactorCalculationService struct {
 	Store     storage.ICacheStore
 	PriceFeed external.IPriceFeedAPI
@@ -30,114 +41,88 @@ func (s *healthFactorCalculationService) CalculateMint(ctx context.Context, req
 	logger := utils.GetLogger()
 	logger.Info().Str("user", req.Address).Str("amount", req.MintAmount).Msg("Calculating health factor projection for mint operation")
 
-	collateralUSDStr, err := s.Store.HGet("user:collateral_usd", req.Address)
+	mintAmount, err := parseAmount("mintAmount", req.MintAmount)
+	if err == nil {
+		err = requirePositive("mintAmount", mintAmount)
+	}
 	if err != nil {
-		logger.Debug().Err(err).Str("user", req.Address).Msg("Collateral USD not found, defaulting to 0")
-		collateralUSDStr = "0"
+		return model.HealthFactorProjection{}, err
 	}
 
-	debtStr, err := s.Store.HGet("user:debt", req.Address)
+	collateralUSD, currentDebt, err := getCachedCollateralAndDebt(s.Store, req.Address)
 	if err != nil {
-		logger.Debug().Err(err).Str("user", req.Address).Msg("User debt not found, defaulting to 0")
-		debtStr = "0"
+		logger.Error().Err(err).Str("user", req.Address).Msg("Failed to read user position from cache")
+		return model.HealthFactorProjection{}, err
 	}
 
-	collateralUSD := new(big.Int)
-	collateralUSD.SetString(collateralUSDStr, 10)
-
-	currentDebt := new(big.Int)
-	currentDebt.SetString(debtStr, 10)
-
-	mintAmount := new(big.Int)
-	mintAmount.SetString(req.MintAmount, 10)
-
 	newDebt := new(big.Int).Add(currentDebt, mintAmount)
 
-	logger.Debug().Str("user", req.Address).Str("collateral_usd", collateralUSDStr).Str("current_debt", debtStr).Str("mint_amount", req.MintAmount).Msg("Calculating health factor after mint")
+	logger.Debug().Str("user", req.Address).Str("collateral_usd", collateralUSD.String()).Str("current_debt", currentDebt.String()).Str("mint_amount", req.MintAmount).Msg("Calculating health factor after mint")
 
 	healthFactorAfter := domain.CalculateHealthFactorAfterMint(collateralUSD, currentDebt, mintAmount)
 
 	logger.Info().Str("user", req.Address).Str("health_factor_after", healthFactorAfter.String()).Str("new_debt", newDebt.String()).Msg("Mint health factor projection calculated successfully")
 
-	return model.HealthFactorProjection{
-		HealthFactorAfter:  healthFactorAfter.String(),
-		NewDebt:            newDebt.String(),
-		NewCollateralValue: collateralUSD.String(),
-	}, nil
+	return toHealthFactorProjection(req.Units, healthFactorAfter, newDebt, collateralUSD), nil
 }
 
 func (s *healthFactorCalculationService) CalculateBurn(ctx context.Context, req model.CalculateBurnRequest) (model.HealthFactorProjection, error) {
 	logger := utils.GetLogger()
 	logger.Info().Str("user", req.Address).Str("amount", req.BurnAmount).Msg("Calculating health factor projection for burn operation")
 
-	collateralUSDStr, err := s.Store.HGet("user:collateral_usd", req.Address)
+	burnAmount, err := parseAmount("burnAmount", req.BurnAmount)
+	if err == nil {
+		err = requirePositive("burnAmount", burnAmount)
+	}
 	if err != nil {
-		logger.Debug().Err(err).Str("user", req.Address).Msg("Collateral USD not found, defaulting to 0")
-		collateralUSDStr = "0"
+		return model.HealthFactorProjection{}, err
 	}
 
-	debtStr, err := s.Store.HGet("user:debt", req.Address)
+	collateralUSD, currentDebt, err := getCachedCollateralAndDebt(s.Store, req.Address)
 	if err != nil {
-		logger.Debug().Err(err).Str("user", req.Address).Msg("User debt not found, defaulting to 0")
-		debtStr = "0"
+		logger.Error().Err(err).Str("user", req.Address).Msg("Failed to read user position from cache")
+		return model.HealthFactorProjection{}, err
 	}
 
-	collateralUSD := new(big.Int)
-	collateralUSD.SetString(collateralUSDStr, 10)
-
-	currentDebt := new(big.Int)
-	currentDebt.SetString(debtStr, 10)
-
-	burnAmount := new(big.Int)
-	burnAmount.SetString(req.BurnAmount, 10)
-
 	newDebt := new(big.Int).Sub(currentDebt, burnAmount)
 	if newDebt.Sign() < 0 {
-		logger.Warn().Str("user", req.Address).Str("current_debt", debtStr).Str("burn_amount", req.BurnAmount).Msg("Burn amount exceeds debt, setting new debt to 0")
+		logger.Warn().Str("user", req.Address).Str("current_debt", currentDebt.String()).Str("burn_amount", req.BurnAmount).Msg("Burn amount exceeds debt, setting new debt to 0")
 		newDebt = big.NewInt(0)
 	}
 
-	logger.Debug().Str("user", req.Address).Str("collateral_usd", collateralUSDStr).Str("current_debt", debtStr).Str("burn_amount", req.BurnAmount).Msg("Calculating health factor after burn")
+	logger.Debug().Str("user", req.Address).Str("collateral_usd", collateralUSD.String()).Str("current_debt", currentDebt.String()).Str("burn_amount", req.BurnAmount).Msg("Calculating health factor after burn")
 
 	healthFactorAfter := domain.CalculateHealthFactorAfterBurn(collateralUSD, currentDebt, burnAmount)
 
 	logger.Info().Str("user", req.Address).Str("health_factor_after", healthFactorAfter.String()).Str("new_debt", newDebt.String()).Msg("Burn health factor projection calculated successfully")
 
-	return model.HealthFactorProjection{
-		HealthFactorAfter:  healthFactorAfter.String(),
-		NewDebt:            newDebt.String(),
-		NewCollateralValue: collateralUSD.String(),
-	}, nil
+	return toHealthFactorProjection(req.Units, healthFactorAfter, newDebt, collateralUSD), nil
 }
 
 func (s *healthFactorCalculationService) CalculateDeposit(ctx context.Context, req model.CalculateDepositRequest) (model.HealthFactorProjection, error) {
 	logger := utils.GetLogger()
 	logger.Info().Str("user", req.Address).Str("token", req.TokenAddress).Str("amount", req.DepositAmount).Msg("Calculating health factor projection for deposit operation")
 
-	collateralUSDStr, err := s.Store.HGet("user:collateral_usd", req.Address)
+	depositAmount, err := parseAmount("depositAmount", req.DepositAmount)
 	if err != nil {
-		logger.Debug().Err(err).Str("user", req.Address).Msg("Collateral USD not found, defaulting to 0")
-		collateralUSDStr = "0"
+		return model.HealthFactorProjection{}, err
 	}
 
-	debtStr, err := s.Store.HGet("user:debt", req.Address)
+	tokenName, err := allowedToken(req.TokenAddress)
+	if err == nil {
+		err = requirePositive("depositAmount", depositAmount)
+	}
 	if err != nil {
-		logger.Debug().Err(err).Str("user", req.Address).Msg("User debt not found, defaulting to 0")
-		debtStr = "0"
+		return model.HealthFactorProjection{}, err
 	}
-
-	currentCollateralUSD := new(big.Int)
-	currentCollateralUSD.SetString(collateralUSDStr, 10)
-
-	currentDebt := new(big.Int)
-	currentDebt.SetString(debtStr, 10)
-
-	depositAmount := new(big.Int)
-	depositAmount.SetString(req.DepositAmount, 10)
-
-	tokenName := getTokenNameByAddress(req.TokenAddress)
 	logger.Debug().Str("token_address", req.TokenAddress).Str("token_name", tokenName).Msg("Token identified")
 
+	currentCollateralUSD, currentDebt, err := getCachedCollateralAndDebt(s.Store, req.Address)
+	if err != nil {
+		logger.Error().Err(err).Str("user", req.Address).Msg("Failed to read user position from cache")
+		return model.HealthFactorProjection{}, err
+	}
+
 	priceStr, err := getTokenPrice(s.PriceFeed, tokenName)
 	if err != nil {
 		logger.Error().Err(err).Str("token_name", tokenName).Msg("Failed to get token price")
@@ -153,46 +138,43 @@ func (s *healthFactorCalculationService) CalculateDeposit(ctx context.Context, r
 
 	newCollateralUSD := new(big.Int).Add(currentCollateralUSD, depositAmountUSD)
 
-	logger.Debug().Str("user", req.Address).Str("current_collateral_usd", collateralUSDStr).Str("deposit_usd", depositAmountUSD.String()).Str("new_collateral_usd", newCollateralUSD.String()).Msg("Calculating health factor after deposit")
+	logger.Debug().Str("user", req.Address).Str("current_collateral_usd", currentCollateralUSD.String()).Str("deposit_usd", depositAmountUSD.String()).Str("new_collateral_usd", newCollateralUSD.String()).Msg("Calculating health factor after deposit")
 
 	healthFactorAfter := domain.CalculateHealthFactorAfterDeposit(currentCollateralUSD, currentDebt, depositAmountUSD)
 
 	logger.Info().Str("user", req.Address).Str("token", tokenName).Str("health_factor_after", healthFactorAfter.String()).Str("new_collateral_value", newCollateralUSD.String()).Msg("Deposit health factor projection calculated successfully")
 
-	return model.HealthFactorProjection{
-		HealthFactorAfter:  healthFactorAfter.String(),
-		NewDebt:            currentDebt.String(),
-		NewCollateralValue: newCollateralUSD.String(),
-	}, nil
+	return toHealthFactorProjection(req.Units, healthFactorAfter, currentDebt, newCollateralUSD), nil
 }
 
 func (s *healthFactorCalculationService) CalculateRedeem(ctx context.Context, req model.CalculateRedeemRequest) (model.HealthFactorProjection, error) {
 	logger := utils.GetLogger()
 	logger.Info().Str("user", req.Address).Str("token", req.TokenAddress).Str("amount", req.RedeemAmount).Msg("Calculating health factor projection for redeem operation")
 
-	collateralUSDStr, err := s.Store.HGet("user:collateral_usd", req.Address)
+	redeemAmount, err := parseAmount("redeemAmount", req.RedeemAmount)
 	if err != nil {
-		logger.Debug().Err(err).Str("user", req.Address).Msg("Collateral USD not found, defaulting to 0")
-		collateralUSDStr = "0"
+		return model.HealthFactorProjection{}, err
 	}
 
-	debtStr, err := s.Store.HGet("user:debt", req.Address)
+	tokenName, err := allowedToken(req.TokenAddress)
+	if err == nil {
+		err = requirePositive("redeemAmount", redeemAmount)
+	}
 	if err != nil {
-		logger.Debug().Err(err).Str("user", req.Address).Msg("User debt not found, defaulting to 0")
-		debtStr = "0"
+		return model.HealthFactorProjection{}, err
 	}
+	logger.Debug().Str("token_address", req.TokenAddress).Str("token_name", tokenName).Msg("Token identified")
 
-	currentCollateralUSD := new(big.Int)
-	currentCollateralUSD.SetString(collateralUSDStr, 10)
-
-	currentDebt := new(big.Int)
-	currentDebt.SetString(debtStr, 10)
-
-	redeemAmount := new(big.Int)
-	redeemAmount.SetString(req.RedeemAmount, 10)
+	if err := requireCollateralBalance(s.Store, req.Address, req.TokenAddress, tokenName, redeemAmount); err != nil {
+		logger.Warn().Err(err).Str("user", req.Address).Str("token", tokenName).Msg("Redeem exceeds deposited collateral")
+		return model.HealthFactorProjection{}, err
+	}
 
-	tokenName := getTokenNameByAddress(req.TokenAddress)
-	logger.Debug().Str("token_address", req.TokenAddress).Str("token_name", tokenName).Msg("Token identified")
+	currentCollateralUSD, currentDebt, err := getCachedCollateralAndDebt(s.Store, req.Address)
+	if err != nil {
+		logger.Error().Err(err).Str("user", req.Address).Msg("Failed to read user position from cache")
+		return model.HealthFactorProjection{}, err
+	}
 
 	priceStr, err := getTokenPrice(s.PriceFeed, tokenName)
 	if err != nil {
@@ -209,17 +191,453 @@ func (s *healthFactorCalculationService) CalculateRedeem(ctx context.Context, re
 
 	newCollateralUSD := new(big.Int).Sub(currentCollateralUSD, redeemAmountUSD)
 
-	logger.Debug().Str("user", req.Address).Str("current_collateral_usd", collateralUSDStr).Str("redeem_usd", redeemAmountUSD.String()).Str("new_collateral_usd", newCollateralUSD.String()).Msg("Calculating health factor after redeem")
+	logger.Debug().Str("user", req.Address).Str("current_collateral_usd", currentCollateralUSD.String()).Str("redeem_usd", redeemAmountUSD.String()).Str("new_collateral_usd", newCollateralUSD.String()).Msg("Calculating health factor after redeem")
 
 	healthFactorAfter := domain.CalculateHealthFactorAfterDeposit(currentCollateralUSD, currentDebt, redeemAmountUSD)
 
 	logger.Info().Str("user", req.Address).Str("token", tokenName).Str("health_factor_after", healthFactorAfter.String()).Str("new_collateral_value", newCollateralUSD.String()).Msg("Deposit health factor projection calculated successfully")
 
+	return toHealthFactorProjection(req.Units, healthFactorAfter, currentDebt, newCollateralUSD), nil
+}
+
+func (s *healthFactorCalculationService) CalculateLiquidation(ctx context.Context, req model.CalculateLiquidationRequest) (model.LiquidationProjection, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("user", req.User).Str("token", req.CollateralToken).Str("debt_to_cover", req.DebtToCover).Msg("Simulating liquidation")
+
+	debtToCover, err := parseAmount("debtToCover", req.DebtToCover)
+	if err != nil {
+		logger.Warn().Str("debt_to_cover", req.DebtToCover).Msg("Invalid debt to cover")
+		return model.LiquidationProjection{}, err
+	}
+
+	prices, err := GetCollateralPrices(s.PriceFeed)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get collateral prices")
+		return model.LiquidationProjection{}, err
+	}
+
+	position, err := getContractPosition(s.Store, req.User, prices)
+	if errors.Is(err, storage.ErrCacheMiss) {
+		logger.Debug().Err(err).Str("user", req.User).Msg("User debt not found, defaulting to 0")
+		position = domain.Position{Debt: big.NewInt(0)}
+	} else if err != nil {
+		logger.Error().Err(err).Str("user", req.User).Msg("Failed to read user position from cache")
+		return model.LiquidationProjection{}, err
+	}
+
+	tokenName := getTokenNameByAddress(req.CollateralToken)
+	if tokenName == "" {
+		healthFactor := position.HealthFactor().String()
+		formatted := newFormattedAmounts(req.Units)
+		formatted.add("collateralSeizedUsd", big.NewInt(0), engineUSDDecimals, usdSymbol)
+		formatted.add("liquidatorProfitUsd", big.NewInt(0), engineUSDDecimals, usdSymbol)
+		formatted.add("newDebt", position.Debt, tokenDecimals, stablecoinSymbol)
+		formatted.add("newCollateralValueUsd", position.CollateralValueUSD(), engineUSDDecimals, usdSymbol)
+		return model.LiquidationProjection{
+			WillRevert:            true,
+			RevertReason:          constants.REVERT_TOKEN_NOT_ALLOWED,
+			HealthFactorBefore:    healthFactor,
+			HealthFactorAfter:     healthFactor,
+			CollateralSeized:      "0",
+			BonusCollateral:       "0",
+			CollateralSeizedUsd:   "0",
+			LiquidatorProfitUsd:   "0",
+			NewDebt:               position.Debt.String(),
+			NewCollateralValueUsd: position.CollateralValueUSD().String(),
+			Formatted:             formatted,
+		}, nil
+	}
+
+	simulation := domain.SimulateLiquidation(position, tokenName, debtToCover)
+
+	logger.Info().Str("user", req.User).Str("token", tokenName).Str("revert", simulation.Revert).Str("health_factor_after", simulation.HealthFactorAfter.String()).Msg("Liquidation simulated successfully")
+
+	formatted := newFormattedAmounts(req.Units)
+	formatted.add("collateralSeized", simulation.CollateralSeized, tokenDecimals, tokenName)
+	formatted.add("bonusCollateral", simulation.BonusCollateral, tokenDecimals, tokenName)
+	formatted.add("collateralSeizedUsd", simulation.CollateralValueUSD, engineUSDDecimals, usdSymbol)
+	formatted.add("liquidatorProfitUsd", simulation.ProfitUSD, engineUSDDecimals, usdSymbol)
+	formatted.add("newDebt", simulation.After.Debt, tokenDecimals, stablecoinSymbol)
+	formatted.add("newCollateralValueUsd", simulation.After.CollateralValueUSD(), engineUSDDecimals, usdSymbol)
+
+	return model.LiquidationProjection{
+		WillRevert:            simulation.Revert != "",
+		RevertReason:          simulation.Revert,
+		HealthFactorBefore:    simulation.HealthFactorBefore.String(),
+		HealthFactorAfter:     simulation.HealthFactorAfter.String(),
+		CollateralSeized:      simulation.CollateralSeized.String(),
+		BonusCollateral:       simulation.BonusCollateral.String(),
+		CollateralSeizedUsd:   simulation.CollateralValueUSD.String(),
+		LiquidatorProfitUsd:   simulation.ProfitUSD.String(),
+		NewDebt:               simulation.After.Debt.String(),
+		NewCollateralValueUsd: simulation.After.CollateralValueUSD().String(),
+		Formatted:             formatted,
+	}, nil
+}
+
+// CalculateBatch projects an ordered list of deposits, redeems, mints and burns with the contract's
+// math, as depositCollateralAndMintAUSD, redeemCollateralForAUSD or a chain of separate calls would
+// apply them.
+func (s *healthFactorCalculationService) CalculateBatch(ctx context.Context, req model.CalculateBatchRequest) (model.BatchProjection, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("user", req.Address).Int("steps", len(req.Steps)).Msg("Calculating batch projection")
+
+	steps := make([]domain.PositionStep, 0, len(req.Steps))
+	for i, step := range req.Steps {
+		amount, err := parseAmount(fmt.Sprintf("steps[%d].amount", i), step.Amount)
+		if err != nil {
+			return model.BatchProjection{}, err
+		}
+
+		positionStep := domain.PositionStep{Type: step.Type, Amount: amount}
+		if step.Type == model.TransactionTypeDeposit || step.Type == model.TransactionTypeRedeem {
+			// Unknown tokens are left unnamed so the simulation reports the engine's TokenNotAllowed revert.
+			positionStep.Token = getTokenNameByAddress(step.TokenAddress)
+		}
+		steps = append(steps, positionStep)
+	}
+
+	position, prices, err := loadContractPosition(s.Store, s.PriceFeed, req.Address)
+	if err != nil {
+		logger.Error().Err(err).Str("user", req.Address).Msg("Failed to load user position")
+		return model.BatchProjection{}, err
+	}
+
+	simulation := domain.SimulateOperations(position, steps, prices)
+
+	projection := model.BatchProjection{
+		Steps:         make([]model.BatchStepProjection, 0, len(simulation.Steps)),
+		WillRevert:    simulation.RevertIndex >= 0,
+		FinalPosition: toBatchPosition(simulation.Final, req.Units),
+	}
+	if projection.WillRevert {
+		revertStep := simulation.RevertIndex
+		projection.RevertStep = &revertStep
+	}
+	for _, step := range simulation.Steps {
+		formatted := newFormattedAmounts(req.Units)
+		formatted.add("amount", step.Amount, tokenDecimals, stepSymbol(step.PositionStep))
+		formatted.add("debtAfter", step.After.Debt, tokenDecimals, stablecoinSymbol)
+		formatted.add("collateralUsdAfter", step.After.CollateralValueUSD(), engineUSDDecimals, usdSymbol)
+
+		projection.Steps = append(projection.Steps, model.BatchStepProjection{
+			Type:               step.Type,
+			Token:              step.Token,
+			Amount:             step.Amount.String(),
+			WillRevert:         step.Revert != "",
+			RevertReason:       step.Revert,
+			HealthFactorBefore: step.HealthFactorBefore.String(),
+			HealthFactorAfter:  step.HealthFactorAfter.String(),
+			DebtAfter:          step.After.Debt.String(),
+			CollateralUsdAfter: step.After.CollateralValueUSD().String(),
+			Formatted:          formatted,
+		})
+	}
+
+	logger.Info().Str("user", req.Address).Bool("will_revert", projection.WillRevert).Str("health_factor_after", projection.FinalPosition.HealthFactor).Msg("Batch projection calculated successfully")
+
+	return projection, nil
+}
+
+func (s *healthFactorCalculationService) SolveMaxMint(ctx context.Context, req model.SolveMintRequest) (model.SolverResult, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("user", req.Address).Str("target", req.TargetHealthFactor).Msg("Solving maximum mint")
+
+	target, err := parseTargetHealthFactor(req.TargetHealthFactor)
+	if err != nil {
+		return model.SolverResult{}, err
+	}
+
+	position, prices, err := loadContractPosition(s.Store, s.PriceFeed, req.Address)
+	if err != nil {
+		logger.Error().Err(err).Str("user", req.Address).Msg("Failed to load user position")
+		return model.SolverResult{}, err
+	}
+
+	amount, reachable := domain.SolveMaxMint(position, target)
+	step := domain.PositionStep{Type: model.TransactionTypeMint, Amount: amount}
+	return toSolverResult(position, step, prices, target, reachable, req.Units), nil
+}
+
+func (s *healthFactorCalculationService) SolveMaxRedeem(ctx context.Context, req model.SolveCollateralRequest) (model.SolverResult, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("user", req.Address).Str("token", req.TokenAddress).Str("target", req.TargetHealthFactor).Msg("Solving maximum redeem")
+
+	target, tokenName, err := parseSolveCollateralRequest(req)
+	if err != nil {
+		return model.SolverResult{}, err
+	}
+
+	position, prices, err := loadContractPosition(s.Store, s.PriceFeed, req.Address)
+	if err != nil {
+		logger.Error().Err(err).Str("user", req.Address).Msg("Failed to load user position")
+		return model.SolverResult{}, err
+	}
+
+	amount, reachable := domain.SolveMaxRedeem(position, tokenName, target)
+	step := domain.PositionStep{Type: model.TransactionTypeRedeem, Token: tokenName, Amount: amount}
+	return toSolverResult(position, step, prices, target, reachable, req.Units), nil
+}
+
+func (s *healthFactorCalculationService) SolveRequiredDeposit(ctx context.Context, req model.SolveCollateralRequest) (model.SolverResult, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("user", req.Address).Str("token", req.TokenAddress).Str("target", req.TargetHealthFactor).Msg("Solving required deposit")
+
+	target, tokenName, err := parseSolveCollateralRequest(req)
+	if err != nil {
+		return model.SolverResult{}, err
+	}
+
+	position, prices, err := loadContractPosition(s.Store, s.PriceFeed, req.Address)
+	if err != nil {
+		logger.Error().Err(err).Str("user", req.Address).Msg("Failed to load user position")
+		return model.SolverResult{}, err
+	}
+
+	amount := domain.SolveRequiredDeposit(position, tokenName, prices[tokenName], target)
+	step := domain.PositionStep{Type: model.TransactionTypeDeposit, Token: tokenName, Amount: amount}
+	return toSolverResult(position, step, prices, target, true, req.Units), nil
+}
+
+// toSolverResult reports the solved amount with the health factor the engine would compute after
+// applying it.
+func toSolverResult(position domain.Position, step domain.PositionStep, prices map[string]*big.Int, target *big.Int, reachable bool, units model.AmountUnits) model.SolverResult {
+	simulation := domain.SimulateStep(position, step, prices)
+
+	logger := utils.GetLogger()
+	logger.Info().Str("type", string(step.Type)).Str("token", step.Token).Str("amount", step.Amount.String()).Bool("reachable", reachable).Str("health_factor_after", simulation.HealthFactorAfter.String()).Msg("Solver completed successfully")
+
+	formatted := newFormattedAmounts(units)
+	formatted.add("amount", step.Amount, tokenDecimals, stepSymbol(step))
+
+	return model.SolverResult{
+		Amount:             step.Amount.String(),
+		Token:              step.Token,
+		TargetHealthFactor: target.String(),
+		Reachable:          reachable,
+		HealthFactorBefore: simulation.HealthFactorBefore.String(),
+		HealthFactorAfter:  simulation.HealthFactorAfter.String(),
+		Formatted:          formatted,
+	}
+}
+
+func parseSolveCollateralRequest(req model.SolveCollateralRequest) (*big.Int, string, error) {
+	target, err := parseTargetHealthFactor(req.TargetHealthFactor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tokenName, err := allowedToken(req.TokenAddress)
+	if err != nil {
+		return nil, "", err
+	}
+	return target, tokenName, nil
+}
+
+// parseTargetHealthFactor scales a decimal health factor such as "1.5" to the engine's 18 decimals.
+func parseTargetHealthFactor(value string) (*big.Int, error) {
+	target, ok := domain.ParseDecimalToScaledInt(value, constants.PRECISION)
+	if !ok || target.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid target health factor %q", value)
+	}
+	return target, nil
+}
+
+// loadContractPosition returns the user's position in the engine's terms together with the 8 decimal
+// price of every collateral. A user without cached debt has an empty position.
+func loadContractPosition(store storage.ICacheStore, priceFeed external.IPriceFeedAPI, user string) (domain.Position, map[string]*big.Int, error) {
+	logger := utils.GetLogger()
+
+	prices, err := GetCollateralPrices(priceFeed)
+	if err != nil {
+		return domain.Position{}, nil, err
+	}
+
+	scaledPrices := make(map[string]*big.Int, len(prices))
+	for name, price := range prices {
+		scaled, ok := domain.ParseDecimalToScaledInt(price, constants.PRICE_PRECISION)
+		if !ok || scaled.Sign() <= 0 {
+			return domain.Position{}, nil, fmt.Errorf("%w for %s: invalid price %q", model.ErrPriceUnavailable, name, price)
+		}
+		scaledPrices[name] = scaled
+	}
+
+	position, err := getContractPosition(store, user, prices)
+	if errors.Is(err, storage.ErrCacheMiss) {
+		logger.Debug().Err(err).Str("user", user).Msg("User debt not found, projecting from an empty position")
+		position = domain.Position{Debt: big.NewInt(0)}
+	} else if err != nil {
+		return domain.Position{}, nil, err
+	}
+
+	return position, scaledPrices, nil
+}
+
+func toBatchPosition(position domain.Position, units model.AmountUnits) model.BatchPosition {
+	collateral := make([]model.CollateralDeposited, 0, len(position.Collateral))
+	for _, c := range position.Collateral {
+		valueUSD := domain.ContractUSDValue(c.Amount, c.Price)
+		formatted := newFormattedAmounts(units)
+		formatted.add("amount", c.Amount, tokenDecimals, c.Token)
+		formatted.add("valueUsd", valueUSD, engineUSDDecimals, usdSymbol)
+
+		collateral = append(collateral, model.CollateralDeposited{
+			Asset:     c.Token,
+			Amount:    c.Amount.String(),
+			ValueUsd:  valueUSD.String(),
+			Formatted: formatted,
+		})
+	}
+	sort.Slice(collateral, func(i, j int) bool { return collateral[i].Asset < collateral[j].Asset })
+
+	formatted := newFormattedAmounts(units)
+	formatted.add("collateralUsd", position.CollateralValueUSD(), engineUSDDecimals, usdSymbol)
+	formatted.add("debt", position.Debt, tokenDecimals, stablecoinSymbol)
+
+	return model.BatchPosition{
+		Collateral:    collateral,
+		CollateralUsd: position.CollateralValueUSD().String(),
+		Debt:          position.Debt.String(),
+		HealthFactor:  position.HealthFactor().String(),
+		Formatted:     formatted,
+	}
+}
+
+// toHealthFactorProjection builds the projection of a mint, burn, deposit or redeem from the cached
+// position, whose collateral value has usdDecimals.
+func toHealthFactorProjection(units model.AmountUnits, healthFactorAfter, newDebt, newCollateralUSD *big.Int) model.HealthFactorProjection {
+	formatted := newFormattedAmounts(units)
+	formatted.add("newDebt", newDebt, tokenDecimals, stablecoinSymbol)
+	formatted.add("newCollateralValue", newCollateralUSD, usdDecimals, usdSymbol)
+
 	return model.HealthFactorProjection{
 		HealthFactorAfter:  healthFactorAfter.String(),
-		NewDebt:            currentDebt.String(),
+		NewDebt:            newDebt.String(),
 		NewCollateralValue: newCollateralUSD.String(),
-	}, nil
+		Formatted:          formatted,
+	}
+}
+
+// formattedAmounts collects the decimal form of a projection's amounts, keyed by the JSON name of
+// the wei field they repeat. It is nil unless decimal amounts were asked for, so the default
+// response is unchanged.
+type formattedAmounts map[string]model.FormattedAmount
+
+func newFormattedAmounts(units model.AmountUnits) formattedAmounts {
+	if units != model.AmountUnitsDecimal {
+		return nil
+	}
+	return formattedAmounts{}
+}
+
+// add formats amount under field. Amounts of a token the engine does not allow have no symbol and
+// are left out.
+func (f formattedAmounts) add(field string, amount *big.Int, decimals int, symbol string) {
+	if f == nil || symbol == "" {
+		return
+	}
+	f[field] = model.FormattedAmount{Value: domain.FormatUnits(amount, decimals), Symbol: symbol}
+}
+
+// stepSymbol is the symbol of a step's amount: AUSD for mints and burns, the collateral otherwise.
+func stepSymbol(step domain.PositionStep) string {
+	if step.Type == model.TransactionTypeMint || step.Type == model.TransactionTypeBurn {
+		return stablecoinSymbol
+	}
+	return step.Token
+}
+
+// getCachedCollateralAndDebt returns the user's cached collateral value and debt. A user without cached
+// values has an empty position, which is where a first deposit is projected from.
+func getCachedCollateralAndDebt(store storage.ICacheStore, user string) (*big.Int, *big.Int, error) {
+	values := [2]*big.Int{}
+	for i, key := range []string{"user:collateral_usd", "user:debt"} {
+		value, found, err := getCachedField(store, key, user)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !found {
+			values[i] = big.NewInt(0)
+			continue
+		}
+
+		amount, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid cached %s %q for %s", key, value, user)
+		}
+		values[i] = amount
+	}
+	return values[0], values[1], nil
+}
+
+// parseAmount parses a request amount, in wei or, when it has a decimal point, in whole tokens of 18
+// decimals like every token the engine accepts. It must fit a uint256; field names it in the error.
+func parseAmount(field string, value string) (*big.Int, error) {
+	if strings.Contains(value, ".") {
+		amount, err := domain.ParseUnits(value, tokenDecimals)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s' must be in wei or a decimal with at most %d decimals, got %q", model.ErrInvalidAmount, field, tokenDecimals, value)
+		}
+		return checkUint256(field, amount)
+	}
+
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s' must be an integer in wei or a decimal such as 1.5, got %q", model.ErrInvalidAmount, field, value)
+	}
+	return checkUint256(field, amount)
+}
+
+// checkUint256 rejects amounts the engine could not be called with, since every amount it takes is a
+// uint256.
+func checkUint256(field string, amount *big.Int) (*big.Int, error) {
+	if amount.Sign() < 0 || amount.Cmp(constants.MAX_UINT256) > 0 {
+		return nil, fmt.Errorf("%w: '%s' must be between 0 and 2^256-1, got %s", model.ErrInvalidAmount, field, amount)
+	}
+	return amount, nil
+}
+
+// requirePositive rejects a zero amount like the engine's moreThanZero modifier.
+func requirePositive(field string, amount *big.Int) error {
+	if amount.Sign() == 0 {
+		return engineRevert(constants.REVERT_MUST_BE_MORE_THAN_ZERO, model.ErrInvalidAmount, "'%s' must be more than zero", field)
+	}
+	return nil
+}
+
+// allowedToken returns the collateral name of tokenAddress, rejecting tokens outside the allowed
+// collateral set like the engine's onlyAllowedTokens modifier.
+func allowedToken(tokenAddress string) (string, error) {
+	tokenName := getTokenNameByAddress(tokenAddress)
+	if tokenName == "" {
+		return "", engineRevert(constants.REVERT_TOKEN_NOT_ALLOWED, model.ErrUnknownToken, "%s is not an allowed collateral token", tokenAddress)
+	}
+	return tokenName, nil
+}
+
+// requireCollateralBalance rejects redeeming more of a token than the user's cached deposit, which
+// the engine reverts on before touching the health factor.
+func requireCollateralBalance(store storage.ICacheStore, user, tokenAddress, tokenName string, amount *big.Int) error {
+	value, found, err := getCachedField(store, "collateral:"+tokenAddress, user)
+	if err != nil {
+		return err
+	}
+
+	balance := big.NewInt(0)
+	if found {
+		if _, ok := balance.SetString(value, 10); !ok {
+			return fmt.Errorf("invalid cached %s balance %q for %s", tokenName, value, user)
+		}
+	}
+
+	if balance.Cmp(amount) < 0 {
+		return engineRevert(constants.REVERT_INSUFFICIENT_COLLATERAL, model.ErrInsufficientCollateral, "cannot redeem %s %s wei, %s wei deposited", amount, tokenName, balance)
+	}
+	return nil
+}
+
+func engineRevert(revert string, err error, format string, args ...any) error {
+	return &model.EngineError{Revert: revert, Err: err, Detail: fmt.Sprintf(format, args...)}
 }
 
 func getTokenNameByAddress(tokenAddress string) string {
@@ -234,25 +652,29 @@ func getTokenNameByAddress(tokenAddress string) string {
 	return ""
 }
 
+// getTokenPrice fetches the live price of a collateral token. Feed failures are reported as
+// model.ErrPriceUnavailable unless the feed already classified them, as it does for stale prices.
 func getTokenPrice(priceFeed external.IPriceFeedAPI, tokenName string) (string, error) {
 	logger := utils.GetLogger()
 	logger.Debug().Str("token", tokenName).Msg("Fetching token price")
 
+	var price string
+	var err error
 	switch tokenName {
 	case "ETH":
-		price, err := priceFeed.GetEthUsdPrice()
-		if err != nil {
-			logger.Error().Err(err).Msg("Failed to fetch ETH price")
-		}
-		return price, err
+		price, err = priceFeed.GetEthUsdPrice()
 	case "BTC":
-		price, err := priceFeed.GetBtcUsdPrice()
-		if err != nil {
-			logger.Error().Err(err).Msg("Failed to fetch BTC price")
-		}
-		return price, err
+		price, err = priceFeed.GetBtcUsdPrice()
 	default:
 		logger.Warn().Str("token", tokenName).Msg("Unknown token, returning 0 price")
 		return "0", nil
 	}
+
+	if err != nil {
+		logger.Error().Err(err).Str("token", tokenName).Msg("Failed to fetch token price")
+		if !errors.Is(err, model.ErrStalePrice) && !errors.Is(err, model.ErrPriceUnavailable) {
+			err = fmt.Errorf("%w for %s: %v", model.ErrPriceUnavailable, tokenName, err)
+		}
+	}
+	return price, err
 }
@@ -2,11 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestNewHealthFactorCalculationService(t *testing.T) {
@@ -48,7 +53,7 @@ func TestCalculateMint_NoCollateral(t *testing.T) {
 	mockPriceFeed := new(MockPriceFeedAPI)
 	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
 
-	mockCache.On("HGet", "user:collateral_usd", "0x123").Return("", assert.AnError)
+	mockCache.On("HGet", "user:collateral_usd", "0x123").Return("", storage.ErrCacheMiss)
 	mockCache.On("HGet", "user:debt", "0x123").Return("0", nil)
 
 	req := model.CalculateMintRequest{
@@ -64,6 +69,75 @@ func TestCalculateMint_NoCollateral(t *testing.T) {
 	assert.Equal(t, "10000000000000000000", projection.NewDebt)
 }
 
+func TestCalculateMint_InvalidAmount(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	_, err := service.CalculateMint(context.Background(), model.CalculateMintRequest{
+		Address:    "0x123",
+		MintAmount: "0.0000000000000000001",
+	})
+
+	assert.ErrorIs(t, err, model.ErrInvalidAmount)
+	mockCache.AssertNotCalled(t, "HGet", mock.Anything, mock.Anything)
+}
+
+func TestCalculateMint_DecimalAmount(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockCache.On("HGet", "user:collateral_usd", "0x123").Return("400000000000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("1000000000000000000000", nil)
+
+	projection, err := service.CalculateMint(context.Background(), model.CalculateMintRequest{
+		Address:    "0x123",
+		MintAmount: "500.25",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1500250000000000000000", projection.NewDebt)
+	assert.Nil(t, projection.Formatted)
+}
+
+func TestCalculateMint_DecimalUnits(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockCache.On("HGet", "user:collateral_usd", "0x123").Return("400000000000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("1000000000000000000000", nil)
+
+	projection, err := service.CalculateMint(context.Background(), model.CalculateMintRequest{
+		Address:    "0x123",
+		MintAmount: "500000000000000000000",
+		Units:      model.AmountUnitsDecimal,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1500000000000000000000", projection.NewDebt)
+	assert.Equal(t, map[string]model.FormattedAmount{
+		"newDebt":            {Value: "1500", Symbol: "AUSD"},
+		"newCollateralValue": {Value: "4000", Symbol: "USD"},
+	}, projection.Formatted)
+}
+
+func TestCalculateMint_CacheFailure(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockCache.On("HGet", "user:collateral_usd", "0x123").Return("", assert.AnError)
+
+	_, err := service.CalculateMint(context.Background(), model.CalculateMintRequest{
+		Address:    "0x123",
+		MintAmount: "10000000000000000000",
+	})
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
 func TestCalculateBurn_Success(t *testing.T) {
 	mockCache := new(MockCacheStore)
 	mockPriceFeed := new(MockPriceFeedAPI)
@@ -162,6 +236,64 @@ func TestCalculateDeposit_PriceError(t *testing.T) {
 	mockPriceFeed.AssertExpectations(t)
 }
 
+func TestCalculateDeposit_PriceUnavailable(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockCache.On("HGet", "user:collateral_usd", "0x123").Return("100000000000000000000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("50000000000000000000", nil)
+	mockPriceFeed.On("GetEthUsdPrice").Return("", assert.AnError)
+
+	_, err := service.CalculateDeposit(context.Background(), model.CalculateDepositRequest{
+		Address:       "0x123",
+		TokenAddress:  "0xethaddress",
+		DepositAmount: "1000000000000000000",
+	})
+
+	assert.ErrorIs(t, err, model.ErrPriceUnavailable)
+}
+
+func TestCalculateDeposit_StalePrice(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockCache.On("HGet", "user:collateral_usd", "0x123").Return("100000000000000000000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("50000000000000000000", nil)
+	mockPriceFeed.On("GetEthUsdPrice").Return("", fmt.Errorf("%w: last ETH price is 10m0s old", model.ErrStalePrice))
+
+	_, err := service.CalculateDeposit(context.Background(), model.CalculateDepositRequest{
+		Address:       "0x123",
+		TokenAddress:  "0xethaddress",
+		DepositAmount: "1000000000000000000",
+	})
+
+	assert.ErrorIs(t, err, model.ErrStalePrice)
+	assert.NotErrorIs(t, err, model.ErrPriceUnavailable)
+}
+
+func TestCalculateDeposit_UnknownToken(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	_, err := service.CalculateDeposit(context.Background(), model.CalculateDepositRequest{
+		Address:       "0x123",
+		TokenAddress:  "0xunknown",
+		DepositAmount: "1000000000000000000",
+	})
+
+	assert.ErrorIs(t, err, model.ErrUnknownToken)
+	mockPriceFeed.AssertNotCalled(t, "GetEthUsdPrice")
+}
+
 func TestCalculateRedeem_Success(t *testing.T) {
 	constants.CollateralTokens["BTC"] = "0xbtcaddress"
 	defer delete(constants.CollateralTokens, "BTC")
@@ -170,6 +302,7 @@ func TestCalculateRedeem_Success(t *testing.T) {
 	mockPriceFeed := new(MockPriceFeedAPI)
 	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
 
+	mockCache.On("HGet", "collateral:0xbtcaddress", "0x123").Return("1000000000000000000", nil)
 	mockCache.On("HGet", "user:collateral_usd", "0x123").Return("100000000000000000000", nil)
 	mockCache.On("HGet", "user:debt", "0x123").Return("50000000000000000000", nil)
 	mockPriceFeed.On("GetBtcUsdPrice").Return("50000000000000000000000", nil)
@@ -191,6 +324,480 @@ func TestCalculateRedeem_Success(t *testing.T) {
 	mockPriceFeed.AssertExpectations(t)
 }
 
+func TestCalculateRedeem_ExceedsBalance(t *testing.T) {
+	constants.CollateralTokens["BTC"] = "0xbtcaddress"
+	defer delete(constants.CollateralTokens, "BTC")
+
+	tests := []struct {
+		name    string
+		balance string
+		err     error
+	}{
+		{name: "more than deposited", balance: "1000000000000000000"},
+		{name: "nothing deposited", err: storage.ErrCacheMiss},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCache := new(MockCacheStore)
+			mockPriceFeed := new(MockPriceFeedAPI)
+			service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+			mockCache.On("HGet", "collateral:0xbtcaddress", "0x123").Return(tt.balance, tt.err)
+
+			_, err := service.CalculateRedeem(context.Background(), model.CalculateRedeemRequest{
+				Address:      "0x123",
+				TokenAddress: "0xbtcaddress",
+				RedeemAmount: "1000000000000000001",
+			})
+
+			assert.ErrorIs(t, err, model.ErrInsufficientCollateral)
+			var engineErr *model.EngineError
+			if assert.ErrorAs(t, err, &engineErr) {
+				assert.Equal(t, constants.REVERT_INSUFFICIENT_COLLATERAL, engineErr.Revert)
+			}
+			mockPriceFeed.AssertNotCalled(t, "GetBtcUsdPrice")
+		})
+	}
+}
+
+func TestCalculateAmountValidation(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	tooLarge := new(big.Int).Add(constants.MAX_UINT256, big.NewInt(1)).String()
+
+	tests := []struct {
+		name     string
+		calc     func(*healthFactorCalculationService) error
+		expected error
+		revert   string
+	}{
+		{
+			name: "zero mint",
+			calc: func(s *healthFactorCalculationService) error {
+				_, err := s.CalculateMint(context.Background(), model.CalculateMintRequest{Address: "0x123", MintAmount: "0"})
+				return err
+			},
+			expected: model.ErrInvalidAmount,
+			revert:   constants.REVERT_MUST_BE_MORE_THAN_ZERO,
+		},
+		{
+			name: "negative burn",
+			calc: func(s *healthFactorCalculationService) error {
+				_, err := s.CalculateBurn(context.Background(), model.CalculateBurnRequest{Address: "0x123", BurnAmount: "-1"})
+				return err
+			},
+			expected: model.ErrInvalidAmount,
+		},
+		{
+			name: "mint above uint256",
+			calc: func(s *healthFactorCalculationService) error {
+				_, err := s.CalculateMint(context.Background(), model.CalculateMintRequest{Address: "0x123", MintAmount: tooLarge})
+				return err
+			},
+			expected: model.ErrInvalidAmount,
+		},
+		{
+			name: "zero deposit",
+			calc: func(s *healthFactorCalculationService) error {
+				_, err := s.CalculateDeposit(context.Background(), model.CalculateDepositRequest{Address: "0x123", TokenAddress: "0xethaddress", DepositAmount: "0.0"})
+				return err
+			},
+			expected: model.ErrInvalidAmount,
+			revert:   constants.REVERT_MUST_BE_MORE_THAN_ZERO,
+		},
+		{
+			name: "zero deposit of a token that is not allowed",
+			calc: func(s *healthFactorCalculationService) error {
+				_, err := s.CalculateDeposit(context.Background(), model.CalculateDepositRequest{Address: "0x123", TokenAddress: "0xunknown", DepositAmount: "0"})
+				return err
+			},
+			expected: model.ErrUnknownToken,
+			revert:   constants.REVERT_TOKEN_NOT_ALLOWED,
+		},
+		{
+			name: "redeem of a token that is not allowed",
+			calc: func(s *healthFactorCalculationService) error {
+				_, err := s.CalculateRedeem(context.Background(), model.CalculateRedeemRequest{Address: "0x123", TokenAddress: "0xunknown", RedeemAmount: "1"})
+				return err
+			},
+			expected: model.ErrUnknownToken,
+			revert:   constants.REVERT_TOKEN_NOT_ALLOWED,
+		},
+		{
+			name: "negative debt to cover",
+			calc: func(s *healthFactorCalculationService) error {
+				_, err := s.CalculateLiquidation(context.Background(), model.CalculateLiquidationRequest{User: "0x123", CollateralToken: "0xethaddress", DebtToCover: "-5"})
+				return err
+			},
+			expected: model.ErrInvalidAmount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCache := new(MockCacheStore)
+			mockPriceFeed := new(MockPriceFeedAPI)
+			service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+			err := tt.calc(service)
+
+			assert.ErrorIs(t, err, tt.expected)
+			var engineErr *model.EngineError
+			if tt.revert == "" {
+				assert.False(t, errors.As(err, &engineErr), "unexpected engine error %v", err)
+			} else if assert.ErrorAs(t, err, &engineErr) {
+				assert.Equal(t, tt.revert, engineErr.Revert)
+			}
+			mockCache.AssertNotCalled(t, "HGet", mock.Anything, mock.Anything)
+			mockPriceFeed.AssertNotCalled(t, "GetEthUsdPrice")
+		})
+	}
+}
+
+func TestCalculateLiquidation_Success(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("1300000000000000000000", nil)
+	mockCache.On("HGet", "collateral:0xethaddress", "0x123").Return("1000000000000000000", nil)
+
+	projection, err := service.CalculateLiquidation(context.Background(), model.CalculateLiquidationRequest{
+		User:            "0x123",
+		CollateralToken: "0xethaddress",
+		DebtToCover:     "500000000000000000000",
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, projection.WillRevert)
+	assert.Empty(t, projection.RevertReason)
+	assert.Equal(t, "275000000000000000", projection.CollateralSeized)
+	assert.Equal(t, "25000000000000000", projection.BonusCollateral)
+	assert.Equal(t, "50000000000000000000", projection.LiquidatorProfitUsd)
+	assert.Equal(t, "800000000000000000000", projection.NewDebt)
+	assert.Equal(t, "1450000000000000000000", projection.NewCollateralValueUsd)
+	assert.Equal(t, "906250000000000000", projection.HealthFactorAfter)
+}
+
+func TestCalculateLiquidation_HealthFactorOk(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("500000000000000000000", nil)
+	mockCache.On("HGet", "collateral:0xethaddress", "0x123").Return("1000000000000000000", nil)
+
+	projection, err := service.CalculateLiquidation(context.Background(), model.CalculateLiquidationRequest{
+		User:            "0x123",
+		CollateralToken: "0xethaddress",
+		DebtToCover:     "100000000000000000000",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, projection.WillRevert)
+	assert.Equal(t, constants.REVERT_HEALTH_FACTOR_OK, projection.RevertReason)
+	assert.Equal(t, projection.HealthFactorBefore, projection.HealthFactorAfter)
+}
+
+func TestCalculateLiquidation_TokenNotAllowed(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockCache.On("HGet", "user:debt", "0x123").Return("", storage.ErrCacheMiss)
+
+	projection, err := service.CalculateLiquidation(context.Background(), model.CalculateLiquidationRequest{
+		User:            "0x123",
+		CollateralToken: "0xunknown",
+		DebtToCover:     "1",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, projection.WillRevert)
+	assert.Equal(t, constants.REVERT_TOKEN_NOT_ALLOWED, projection.RevertReason)
+}
+
+func TestCalculateLiquidation_DebtReadError(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockCache.On("HGet", "user:debt", "0x123").Return("", assert.AnError)
+
+	_, err := service.CalculateLiquidation(context.Background(), model.CalculateLiquidationRequest{
+		User:            "0x123",
+		CollateralToken: "0xethaddress",
+		DebtToCover:     "1",
+	})
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestCalculateLiquidation_CollateralReadError(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("1300000000000000000000", nil)
+	mockCache.On("HGet", "collateral:0xethaddress", "0x123").Return("", assert.AnError)
+
+	_, err := service.CalculateLiquidation(context.Background(), model.CalculateLiquidationRequest{
+		User:            "0x123",
+		CollateralToken: "0xethaddress",
+		DebtToCover:     "500000000000000000000",
+	})
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestCalculateLiquidation_InvalidDebtToCover(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	_, err := service.CalculateLiquidation(context.Background(), model.CalculateLiquidationRequest{
+		User:            "0x123",
+		CollateralToken: "0xethaddress",
+		DebtToCover:     "abc",
+	})
+
+	assert.Error(t, err)
+	mockPriceFeed.AssertNotCalled(t, "GetEthUsdPrice")
+}
+
+func TestCalculateBatch_DepositAndMint(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("", storage.ErrCacheMiss)
+
+	projection, err := service.CalculateBatch(context.Background(), model.CalculateBatchRequest{
+		Address: "0x123",
+		Steps: []model.BatchStep{
+			{Type: model.TransactionTypeDeposit, TokenAddress: "0xethaddress", Amount: "1000000000000000000"},
+			{Type: model.TransactionTypeMint, Amount: "800000000000000000000"},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, projection.WillRevert)
+	assert.Nil(t, projection.RevertStep)
+	assert.Len(t, projection.Steps, 2)
+	assert.Equal(t, "ETH", projection.Steps[0].Token)
+	assert.Equal(t, constants.MAX_UINT256.String(), projection.Steps[0].HealthFactorAfter)
+	assert.Equal(t, "2000000000000000000000", projection.Steps[0].CollateralUsdAfter)
+	assert.Equal(t, "1250000000000000000", projection.Steps[1].HealthFactorAfter)
+	assert.Equal(t, "800000000000000000000", projection.FinalPosition.Debt)
+	assert.Equal(t, "1250000000000000000", projection.FinalPosition.HealthFactor)
+	assert.Equal(t, []model.CollateralDeposited{{Asset: "ETH", Amount: "1000000000000000000", ValueUsd: "2000000000000000000000"}}, projection.FinalPosition.Collateral)
+}
+
+func TestCalculateBatch_StopsAtFirstRevert(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("500000000000000000000", nil)
+	mockCache.On("HGet", "collateral:0xethaddress", "0x123").Return("1000000000000000000", nil)
+
+	projection, err := service.CalculateBatch(context.Background(), model.CalculateBatchRequest{
+		Address: "0x123",
+		Steps: []model.BatchStep{
+			{Type: model.TransactionTypeBurn, Amount: "100000000000000000000"},
+			{Type: model.TransactionTypeRedeem, TokenAddress: "0xethaddress", Amount: "700000000000000000"},
+			{Type: model.TransactionTypeMint, Amount: "1"},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, projection.WillRevert)
+	if assert.NotNil(t, projection.RevertStep) {
+		assert.Equal(t, 1, *projection.RevertStep)
+	}
+	assert.Len(t, projection.Steps, 2)
+	assert.Equal(t, constants.REVERT_HEALTH_FACTOR_BROKEN, projection.Steps[1].RevertReason)
+	assert.Equal(t, "400000000000000000000", projection.FinalPosition.Debt)
+	assert.Equal(t, "2000000000000000000000", projection.FinalPosition.CollateralUsd)
+}
+
+func TestCalculateBatch_UnknownTokenReverts(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockCache.On("HGet", "user:debt", "0x123").Return("", storage.ErrCacheMiss)
+
+	projection, err := service.CalculateBatch(context.Background(), model.CalculateBatchRequest{
+		Address: "0x123",
+		Steps:   []model.BatchStep{{Type: model.TransactionTypeDeposit, TokenAddress: "0xunknown", Amount: "1"}},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, projection.WillRevert)
+	assert.Equal(t, constants.REVERT_TOKEN_NOT_ALLOWED, projection.Steps[0].RevertReason)
+}
+
+func TestCalculateBatch_DecimalUnits(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("", storage.ErrCacheMiss)
+
+	projection, err := service.CalculateBatch(context.Background(), model.CalculateBatchRequest{
+		Address: "0x123",
+		Steps: []model.BatchStep{
+			{Type: model.TransactionTypeDeposit, TokenAddress: "0xethaddress", Amount: "1.5"},
+			{Type: model.TransactionTypeMint, Amount: "1000"},
+		},
+		Units: model.AmountUnitsDecimal,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1500000000000000000", projection.Steps[0].Amount)
+	assert.Equal(t, model.FormattedAmount{Value: "1.5", Symbol: "ETH"}, projection.Steps[0].Formatted["amount"])
+	assert.Equal(t, "1000", projection.Steps[1].Amount)
+	assert.Equal(t, model.FormattedAmount{Value: "0.000000000000001", Symbol: "AUSD"}, projection.Steps[1].Formatted["amount"])
+	assert.Equal(t, map[string]model.FormattedAmount{
+		"collateralUsd": {Value: "3000", Symbol: "USD"},
+		"debt":          {Value: "0.000000000000001", Symbol: "AUSD"},
+	}, projection.FinalPosition.Formatted)
+	assert.Equal(t, map[string]model.FormattedAmount{
+		"amount":   {Value: "1.5", Symbol: "ETH"},
+		"valueUsd": {Value: "3000", Symbol: "USD"},
+	}, projection.FinalPosition.Collateral[0].Formatted)
+}
+
+func TestCalculateBatch_InvalidAmount(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	_, err := service.CalculateBatch(context.Background(), model.CalculateBatchRequest{
+		Address: "0x123",
+		Steps:   []model.BatchStep{{Type: model.TransactionTypeMint, Amount: "-1"}},
+	})
+
+	assert.ErrorIs(t, err, model.ErrInvalidAmount)
+	mockCache.AssertNotCalled(t, "HGet", mock.Anything, mock.Anything)
+}
+
+func TestSolveMaxMint(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("300000000000000000000", nil)
+	mockCache.On("HGet", "collateral:0xethaddress", "0x123").Return("1000000000000000000", nil)
+
+	result, err := service.SolveMaxMint(context.Background(), model.SolveMintRequest{
+		Address:            "0x123",
+		TargetHealthFactor: "1.25",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, result.Reachable)
+	assert.Equal(t, "500000000000000000000", result.Amount)
+	assert.Equal(t, "1250000000000000000", result.TargetHealthFactor)
+	assert.Equal(t, "1250000000000000000", result.HealthFactorAfter)
+}
+
+func TestSolveMaxRedeem(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("1000000000000000000000", nil)
+	mockCache.On("HGet", "collateral:0xethaddress", "0x123").Return("2000000000000000000", nil)
+
+	result, err := service.SolveMaxRedeem(context.Background(), model.SolveCollateralRequest{
+		Address:            "0x123",
+		TokenAddress:       "0xethaddress",
+		TargetHealthFactor: "1.5",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, result.Reachable)
+	assert.Equal(t, "ETH", result.Token)
+	assert.Equal(t, "500000000000000000", result.Amount)
+	assert.Equal(t, "2000000000000000000", result.HealthFactorBefore)
+	assert.Equal(t, "1500000000000000000", result.HealthFactorAfter)
+}
+
+func TestSolveRequiredDeposit(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockCache.On("HGet", "user:debt", "0x123").Return("1500000000000000000000", nil)
+	mockCache.On("HGet", "collateral:0xethaddress", "0x123").Return("1000000000000000000", nil)
+
+	result, err := service.SolveRequiredDeposit(context.Background(), model.SolveCollateralRequest{
+		Address:            "0x123",
+		TokenAddress:       "0xethaddress",
+		TargetHealthFactor: "2",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, result.Reachable)
+	assert.Equal(t, "2000000000000000000", result.Amount)
+	assert.Equal(t, "2000000000000000000", result.HealthFactorAfter)
+}
+
+func TestSolveRequiredDeposit_UnknownToken(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewHealthFactorCalculationService(mockCache, mockPriceFeed)
+
+	_, err := service.SolveRequiredDeposit(context.Background(), model.SolveCollateralRequest{
+		Address:            "0x123",
+		TokenAddress:       "0xunknown",
+		TargetHealthFactor: "2",
+	})
+
+	assert.ErrorIs(t, err, model.ErrUnknownToken)
+	mockCache.AssertNotCalled(t, "HGet", mock.Anything, mock.Anything)
+}
+
 func TestGetTokenNameByAddress_ETH(t *testing.T) {
 	constants.CollateralTokens["ETH"] = "0xethaddress"
 	defer delete(constants.CollateralTokens, "ETH")
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+const (
+	defaultHealthFactorHistoryMaxLen    = 10000
+	defaultHealthFactorHistoryRetention = 90 * 24 * time.Hour
+)
+
+// DurableCacheKeys match the Redis keys that hold history rather than derived state. They are kept
+// when the cache is flushed on boot, since replaying events cannot rebuild them. The liquidatable set
+// and its since timestamps are kept with the transitions stream, so a position that stays underwater
+// across a restart does not record a second enter transition.
+var DurableCacheKeys = []string{
+	healthFactorHistoryKey("*"),
+	liquidationTransitionsKey,
+	"liquidatable",
+	"liquidatable:since",
+}
+
+func healthFactorHistoryKey(userAddress string) string {
+	return "user:health_factor:history:" + userAddress
+}
+
+// RecordHealthFactor stores the user's current health factor and position status, appends it to the
+// user's health factor time series and keeps the liquidatable and insolvent sets in sync. When the
+// position enters or leaves the liquidatable set the transition is persisted and published.
+func RecordHealthFactor(cacheStore storage.ICacheStore, snapshot model.HealthFactorSnapshot) error {
+	logger := utils.GetLogger()
+
+	if snapshot.Timestamp.IsZero() {
+		snapshot.Timestamp = time.Now()
+	}
+
+	currentHF, ok := new(big.Int).SetString(snapshot.HealthFactor, 10)
+	if !ok {
+		logger.Error().Str("user", snapshot.UserAddress).Str("health_factor", snapshot.HealthFactor).Msg("Invalid health factor")
+		return fmt.Errorf("invalid health factor %q for user %s", snapshot.HealthFactor, snapshot.UserAddress)
+	}
+	debt, _ := new(big.Int).SetString(snapshot.Debt, 10)
+	collateralUSD, ok := new(big.Int).SetString(snapshot.CollateralUSD, 10)
+	if !ok {
+		collateralUSD = big.NewInt(0)
+	}
+
+	change, err := cacheStore.SetHealthFactor(snapshot.UserAddress, currentHF.String(), domain.IsPositionLiquidatable(currentHF, debt), snapshot.Timestamp)
+	if err != nil {
+		logger.Error().Err(err).Str("user", snapshot.UserAddress).Msg("Failed to set user health factor")
+		return err
+	}
+
+	if _, err := recordPositionStatus(cacheStore, snapshot.UserAddress, collateralUSD, debt); err != nil {
+		return err
+	}
+
+	_, err = cacheStore.XAdd(healthFactorHistoryKey(snapshot.UserAddress), utils.Int64FromEnv("HEALTH_FACTOR_HISTORY_MAX_LEN", defaultHealthFactorHistoryMaxLen), map[string]any{
+		"health_factor":  snapshot.HealthFactor,
+		"collateral_usd": snapshot.CollateralUSD,
+		"debt":           snapshot.Debt,
+		"source":         string(snapshot.Source),
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("user", snapshot.UserAddress).Msg("Failed to append health factor to history")
+		return err
+	}
+
+	retention := utils.DurationFromEnv("HEALTH_FACTOR_HISTORY_RETENTION", defaultHealthFactorHistoryRetention)
+	minID := strconv.FormatInt(snapshot.Timestamp.Add(-retention).UnixMilli(), 10)
+	if err := cacheStore.XTrimMinID(healthFactorHistoryKey(snapshot.UserAddress), minID); err != nil {
+		logger.Warn().Err(err).Str("user", snapshot.UserAddress).Msg("Failed to trim health factor history")
+	}
+
+	publishHealthFactorUpdate(cacheStore, snapshot)
+
+	if change.Transition != "" {
+		recordLiquidationTransition(cacheStore, snapshot, change)
+	}
+
+	return nil
+}
+
+// publishHealthFactorUpdate notifies subscribers such as the alerts worker that a health factor was recomputed.
+func publishHealthFactorUpdate(cacheStore storage.ICacheStore, snapshot model.HealthFactorSnapshot) {
+	logger := utils.GetLogger()
+
+	payload, err := json.Marshal(model.HealthFactorUpdate{
+		Address:       snapshot.UserAddress,
+		HealthFactor:  snapshot.HealthFactor,
+		CollateralUsd: snapshot.CollateralUSD,
+		Debt:          snapshot.Debt,
+		Source:        snapshot.Source,
+		Timestamp:     snapshot.Timestamp.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("user", snapshot.UserAddress).Msg("Failed to encode health factor update")
+		return
+	}
+
+	if err := cacheStore.Publish(constants.HEALTH_FACTORS_CHANNEL, string(payload)); err != nil {
+		logger.Error().Err(err).Str("user", snapshot.UserAddress).Msg("Failed to publish health factor update")
+	}
+}
+
+type healthFactorHistoryService struct {
+	Store storage.ICacheStore
+}
+
+func NewHealthFactorHistoryService(store storage.ICacheStore) *healthFactorHistoryService {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Initializing health factor history service")
+	return &healthFactorHistoryService{
+		Store: store,
+	}
+}
+
+func (s *healthFactorHistoryService) GetHealthFactorHistory(ctx context.Context, user string, query model.HealthFactorHistoryQuery) (model.HealthFactorHistory, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("user", user).Time("from", query.From).Time("to", query.To).Str("resolution", query.Resolution.String()).Msg("Getting health factor history")
+
+	entries, err := s.Store.XRange(
+		healthFactorHistoryKey(user),
+		strconv.FormatInt(query.From.UnixMilli(), 10),
+		strconv.FormatInt(query.To.UnixMilli(), 10),
+	)
+	if err != nil {
+		logger.Error().Err(err).Str("user", user).Msg("Failed to read health factor history")
+		return model.HealthFactorHistory{}, err
+	}
+
+	snapshots := make([]model.HealthFactorSnapshot, 0, len(entries))
+	for _, entry := range entries {
+		timestamp, ok := parseStreamEntryTime(entry.ID)
+		if !ok {
+			logger.Warn().Str("user", user).Str("entry_id", entry.ID).Msg("Skipping health factor entry with invalid ID")
+			continue
+		}
+
+		snapshots = append(snapshots, model.HealthFactorSnapshot{
+			UserAddress:   user,
+			HealthFactor:  entry.Values["health_factor"],
+			CollateralUSD: entry.Values["collateral_usd"],
+			Debt:          entry.Values["debt"],
+			Source:        model.HealthFactorSource(entry.Values["source"]),
+			Timestamp:     timestamp,
+		})
+	}
+
+	points := domain.DownsampleHealthFactors(snapshots, query.Resolution)
+
+	resolution := "raw"
+	if query.Resolution > 0 {
+		resolution = query.Resolution.String()
+	}
+
+	logger.Info().Str("user", user).Int("entries", len(entries)).Int("points", len(points)).Msg("Health factor history retrieved successfully")
+
+	return model.HealthFactorHistory{
+		Address:    user,
+		From:       query.From.UTC().Format(time.RFC3339),
+		To:         query.To.UTC().Format(time.RFC3339),
+		Resolution: resolution,
+		Points:     points,
+	}, nil
+}
+
+func parseStreamEntryTime(id string) (time.Time, bool) {
+	millis, _, _ := strings.Cut(id, "-")
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
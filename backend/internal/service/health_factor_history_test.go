@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecordHealthFactor(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("SetHealthFactor", "0x123", "1400000000000000000", false, mock.Anything).Return(storage.HealthFactorChange{PreviousHealthFactor: "2000000000000000000"}, nil)
+	mockCache.On("HSet", "user:status", "0x123", string(model.PositionStatusAtRisk)).Return(nil)
+	mockCache.On("HDel", "insolvent", []string{"0x123"}).Return(nil)
+	mockCache.On("XAdd", "user:health_factor:history:0x123", int64(defaultHealthFactorHistoryMaxLen), map[string]any{
+		"health_factor":  "1400000000000000000",
+		"collateral_usd": "280000000000",
+		"debt":           "1000000000000000000000",
+		"source":         "coin",
+	}).Return("1700000000000-0", nil)
+	mockCache.On("XTrimMinID", "user:health_factor:history:0x123", mock.Anything).Return(nil)
+	mockCache.On("Publish", constants.HEALTH_FACTORS_CHANNEL, mock.MatchedBy(func(payload string) bool {
+		var update model.HealthFactorUpdate
+		if err := json.Unmarshal([]byte(payload), &update); err != nil {
+			return false
+		}
+		return update.Address == "0x123" && update.HealthFactor == "1400000000000000000" && update.Source == model.HealthFactorSourceCoin
+	})).Return(nil)
+
+	err := RecordHealthFactor(mockCache, model.HealthFactorSnapshot{
+		UserAddress:   "0x123",
+		HealthFactor:  "1400000000000000000",
+		CollateralUSD: "280000000000",
+		Debt:          "1000000000000000000000",
+		Source:        model.HealthFactorSourceCoin,
+	})
+
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "Publish", constants.LIQUIDATIONS_CHANNEL, mock.Anything)
+}
+
+func TestRecordHealthFactor_PublishesEnterTransition(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	timestamp := time.Unix(1700000000, 0)
+
+	var published string
+	mockCache.On("SetHealthFactor", "0x123", "0", true, timestamp).Return(storage.HealthFactorChange{
+		PreviousHealthFactor: "1000000000000000000",
+		Transition:           storage.LiquidatableEntered,
+	}, nil)
+	mockCache.On("HSet", "user:status", "0x123", string(model.PositionStatusInsolvent)).Return(nil)
+	mockCache.On("HSet", "insolvent", "0x123", "1000000000000000000000").Return(nil)
+	mockCache.On("XAdd", "user:health_factor:history:0x123", mock.Anything, mock.Anything).Return("1700000000000-0", nil)
+	mockCache.On("XTrimMinID", "user:health_factor:history:0x123", "1692224000000").Return(nil)
+	mockCache.On("Publish", constants.HEALTH_FACTORS_CHANNEL, mock.Anything).Return(nil)
+	mockCache.On("HGetAll", "prices").Return(map[string]string{"ETH": "2000"}, nil)
+	mockCache.On("XAdd", "liquidations:transitions", int64(defaultLiquidationTransitionsMaxLen), mock.Anything).Return("1700000000000-1", nil)
+	mockCache.On("Publish", constants.LIQUIDATIONS_CHANNEL, mock.Anything).Run(func(args mock.Arguments) {
+		published = args.String(1)
+	}).Return(nil)
+
+	err := RecordHealthFactor(mockCache, model.HealthFactorSnapshot{
+		UserAddress:  "0x123",
+		HealthFactor: "0",
+		Debt:         "1000000000000000000000",
+		Source:       model.HealthFactorSourceLiquidationScan,
+		Timestamp:    timestamp,
+		BlockNumber:  7,
+	})
+
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "HGet", "sync", "last_block")
+
+	var transition model.LiquidationTransition
+	assert.NoError(t, json.Unmarshal([]byte(published), &transition))
+	assert.Equal(t, "0x123", transition.Address)
+	assert.Equal(t, model.LiquidationTransitionEnter, transition.Direction)
+	assert.Equal(t, "1000000000000000000", transition.PreviousHealthFactor)
+	assert.Equal(t, model.HealthFactorSourceLiquidationScan, transition.Source)
+	assert.Equal(t, uint64(7), transition.BlockNumber)
+	assert.Equal(t, map[string]string{"ETH": "2000"}, transition.Prices)
+}
+
+func TestRecordHealthFactor_PublishesExitTransition(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("SetHealthFactor", "0x123", "2000000000000000000", false, mock.Anything).Return(storage.HealthFactorChange{
+		PreviousHealthFactor: "0",
+		Transition:           storage.LiquidatableExited,
+		Since:                "1699999400",
+	}, nil)
+	mockCache.On("HSet", "user:status", "0x123", string(model.PositionStatusHealthy)).Return(nil)
+	mockCache.On("HDel", "insolvent", []string{"0x123"}).Return(nil)
+	mockCache.On("XAdd", "user:health_factor:history:0x123", mock.Anything, mock.Anything).Return("1700000000000-0", nil)
+	mockCache.On("XTrimMinID", "user:health_factor:history:0x123", mock.Anything).Return(nil)
+	mockCache.On("Publish", constants.HEALTH_FACTORS_CHANNEL, mock.Anything).Return(nil)
+	mockCache.On("HGet", "sync", "last_block").Return("120", nil)
+	mockCache.On("XAdd", "liquidations:transitions", mock.Anything, mock.Anything).Return("1700000000000-1", nil)
+	mockCache.On("Publish", constants.LIQUIDATIONS_CHANNEL, mock.MatchedBy(func(message string) bool {
+		var transition model.LiquidationTransition
+		if err := json.Unmarshal([]byte(message), &transition); err != nil {
+			return false
+		}
+		return transition.Direction == model.LiquidationTransitionExit &&
+			transition.UnderwaterSeconds == 600 &&
+			transition.BlockNumber == 120
+	})).Return(nil)
+
+	err := RecordHealthFactor(mockCache, model.HealthFactorSnapshot{
+		UserAddress:   "0x123",
+		HealthFactor:  "2000000000000000000",
+		CollateralUSD: "400000000000",
+		Debt:          "1000000000000000000000",
+		Timestamp:     time.Unix(1700000000, 0),
+		Prices:        map[string]string{"ETH": "2000"},
+	})
+
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+}
+
+func TestRecordHealthFactor_ClosedPositionIsNotLiquidatable(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("SetHealthFactor", "0x123", "0", false, mock.Anything).Return(storage.HealthFactorChange{PreviousHealthFactor: "0"}, nil)
+	mockCache.On("HSet", "user:status", "0x123", string(model.PositionStatusHealthy)).Return(nil)
+	mockCache.On("HDel", "insolvent", []string{"0x123"}).Return(nil)
+	mockCache.On("XAdd", "user:health_factor:history:0x123", mock.Anything, mock.Anything).Return("1700000000000-0", nil)
+	mockCache.On("XTrimMinID", "user:health_factor:history:0x123", mock.Anything).Return(nil)
+	mockCache.On("Publish", constants.HEALTH_FACTORS_CHANNEL, mock.Anything).Return(nil)
+
+	err := RecordHealthFactor(mockCache, model.HealthFactorSnapshot{
+		UserAddress:   "0x123",
+		HealthFactor:  "0",
+		CollateralUSD: "0",
+		Debt:          "0",
+	})
+
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "XAdd", "liquidations:transitions", mock.Anything, mock.Anything)
+}
+
+func TestRecordHealthFactor_StaysLiquidatableWithoutTransition(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("SetHealthFactor", "0x123", "750000000000000000", true, mock.Anything).Return(storage.HealthFactorChange{PreviousHealthFactor: "500000000000000000"}, nil)
+	mockCache.On("HSet", "user:status", "0x123", string(model.PositionStatusLiquidatable)).Return(nil)
+	mockCache.On("HDel", "insolvent", []string{"0x123"}).Return(nil)
+	mockCache.On("XAdd", "user:health_factor:history:0x123", mock.Anything, mock.Anything).Return("1700000000000-0", nil)
+	mockCache.On("XTrimMinID", "user:health_factor:history:0x123", mock.Anything).Return(nil)
+	mockCache.On("Publish", constants.HEALTH_FACTORS_CHANNEL, mock.Anything).Return(nil)
+
+	err := RecordHealthFactor(mockCache, model.HealthFactorSnapshot{
+		UserAddress:   "0x123",
+		HealthFactor:  "750000000000000000",
+		CollateralUSD: "150000000000",
+		Debt:          "1000000000000000000000",
+	})
+
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "XAdd", "liquidations:transitions", mock.Anything, mock.Anything)
+	mockCache.AssertNotCalled(t, "Publish", constants.LIQUIDATIONS_CHANNEL, mock.Anything)
+}
+
+func TestRecordHealthFactor_SetError(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("SetHealthFactor", "0x123", "1", false, mock.Anything).Return(storage.HealthFactorChange{}, assert.AnError)
+
+	err := RecordHealthFactor(mockCache, model.HealthFactorSnapshot{UserAddress: "0x123", HealthFactor: "1"})
+
+	assert.Error(t, err)
+	mockCache.AssertNotCalled(t, "XAdd", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecordHealthFactor_StatusError(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("SetHealthFactor", "0x123", "1", false, mock.Anything).Return(storage.HealthFactorChange{}, nil)
+	mockCache.On("HSet", "user:status", "0x123", string(model.PositionStatusHealthy)).Return(assert.AnError)
+
+	err := RecordHealthFactor(mockCache, model.HealthFactorSnapshot{UserAddress: "0x123", HealthFactor: "1"})
+
+	assert.ErrorIs(t, err, assert.AnError)
+	mockCache.AssertNotCalled(t, "XAdd", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecordHealthFactor_InvalidHealthFactor(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	err := RecordHealthFactor(mockCache, model.HealthFactorSnapshot{UserAddress: "0x123", HealthFactor: "not a number"})
+
+	assert.Error(t, err)
+	mockCache.AssertNotCalled(t, "SetHealthFactor", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetHealthFactorHistory(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	service := NewHealthFactorHistoryService(mockCache)
+
+	from := time.UnixMilli(1700000000000)
+	to := from.Add(2 * time.Hour)
+
+	mockCache.On("XRange", "user:health_factor:history:0x123", "1700000000000", "1700007200000").Return([]storage.StreamEntry{
+		{ID: "1700000000000-0", Values: map[string]string{"health_factor": "2000000000000000000", "collateral_usd": "400", "debt": "100", "source": "coin"}},
+		{ID: "1700000060000-0", Values: map[string]string{"health_factor": "1000000000000000000", "collateral_usd": "200", "debt": "100", "source": "collateral"}},
+		{ID: "invalid", Values: map[string]string{"health_factor": "5"}},
+	}, nil)
+
+	history, err := service.GetHealthFactorHistory(context.Background(), "0x123", model.HealthFactorHistoryQuery{
+		From:       from,
+		To:         to,
+		Resolution: time.Hour,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "0x123", history.Address)
+	assert.Equal(t, "1h0m0s", history.Resolution)
+	assert.Len(t, history.Points, 1)
+	assert.Equal(t, "1000000000000000000", history.Points[0].HealthFactor)
+	assert.Equal(t, "1000000000000000000", history.Points[0].MinHealthFactor)
+	assert.Equal(t, "2000000000000000000", history.Points[0].MaxHealthFactor)
+	assert.Equal(t, 2, history.Points[0].Samples)
+}
+
+func TestGetHealthFactorHistory_RawResolution(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	service := NewHealthFactorHistoryService(mockCache)
+
+	mockCache.On("XRange", "user:health_factor:history:0x123", mock.Anything, mock.Anything).Return([]storage.StreamEntry{
+		{ID: "1700000000000-0", Values: map[string]string{"health_factor": "2000000000000000000"}},
+		{ID: "1700000060000-0", Values: map[string]string{"health_factor": "1000000000000000000"}},
+	}, nil)
+
+	history, err := service.GetHealthFactorHistory(context.Background(), "0x123", model.HealthFactorHistoryQuery{
+		From: time.UnixMilli(1700000000000),
+		To:   time.UnixMilli(1700000120000),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "raw", history.Resolution)
+	assert.Len(t, history.Points, 2)
+}
+
+func TestGetHealthFactorHistory_StoreError(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	service := NewHealthFactorHistoryService(mockCache)
+
+	mockCache.On("XRange", mock.Anything, mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	_, err := service.GetHealthFactorHistory(context.Background(), "0x123", model.HealthFactorHistoryQuery{
+		From: time.Now().Add(-time.Hour),
+		To:   time.Now(),
+	})
+
+	assert.Error(t, err)
+}
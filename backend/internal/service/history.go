@@ -2,177 +2,364 @@ package service
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
 )
 
-type CollateralHistoryStore interface {
-	GetLatestDeposits(ctx context.Context, userAddress string, limit int) ([]model.Deposit, error)
-	GetLatestRedeems(ctx context.Context, userAddress string, limit int) ([]model.Redeem, error)
-}
-
-type CoinHistoryStore interface {
-	GetLatestMints(ctx context.Context, userAddress string, limit int) ([]model.Mints, error)
-	GetLatestBurns(ctx context.Context, userAddress string, limit int) ([]model.Burns, error)
-}
+const (
+	latestTransactionsPerType = 10
+	stablecoinSymbol          = "AUSD"
+	tokenDecimals             = 18
+	usdDecimals               = 8
+)
 
-type LiquidationHistoryStore interface {
-	GetLatestLiquidations(ctx context.Context, userAddress string, limit int) ([]model.Liquidations, error)
+type TransactionHistoryStore interface {
+	GetTransactions(ctx context.Context, userAddress string, filter model.HistoryFilter, before *model.HistoryCursor, limit int) ([]model.HistoryEntry, int64, error)
+	GetLatestTransactions(ctx context.Context, userAddress string, perType int) ([]model.HistoryEntry, error)
+	CountTransactions(ctx context.Context, userAddress string, filter model.HistoryFilter) (int64, error)
+	GetOperations(ctx context.Context, userAddress string, filter model.HistoryFilter, before *model.HistoryCursor, limit int) ([]model.HistoryOperationKey, int64, error)
+	GetOperationLegs(ctx context.Context, userAddress string, groupKeys []string) ([]model.HistoryEntry, error)
+	GetPositionBefore(ctx context.Context, userAddress string, cursor model.HistoryCursor) ([]model.HistoryEntry, error)
+	CountOperations(ctx context.Context, userAddress string, filter model.HistoryFilter) (int64, error)
+	IterateStatement(ctx context.Context, userAddress string, filter model.HistoryFilter, tokens map[string]string, cb func(model.StatementEntry) error) error
 }
 
-type EventHistoryStore interface {
-	GetEventByID(ctx context.Context, eventID uint) (*model.Events, error)
+type HistoryPriceStore interface {
+	GetPricesAtBlocks(ctx context.Context, blockNumbers []uint64) (map[uint64]map[string]string, error)
 }
 
 type HistoryService struct {
-	collateralStore  CollateralHistoryStore
-	coinStore        CoinHistoryStore
-	liquidationStore LiquidationHistoryStore
-	eventsStore      EventHistoryStore
+	transactionStore TransactionHistoryStore
+	priceStore       HistoryPriceStore
 }
 
-func NewHistoryService(collateralStore CollateralHistoryStore, coinStore CoinHistoryStore, liquidationStore LiquidationHistoryStore, eventsStore EventHistoryStore) *HistoryService {
+func NewHistoryService(transactionStore TransactionHistoryStore, priceStore HistoryPriceStore) *HistoryService {
 	logger := utils.GetLogger()
 	logger.Info().Msg("Initializing history service")
 	return &HistoryService{
-		collateralStore:  collateralStore,
-		coinStore:        coinStore,
-		liquidationStore: liquidationStore,
-		eventsStore:      eventsStore,
+		transactionStore: transactionStore,
+		priceStore:       priceStore,
 	}
 }
 
+// GetUserHistory returns the latest transactions of each type in the legacy bucketed shape:
+// deposits with redeems, mints with burns, and liquidations, each newest first.
 func (hs *HistoryService) GetUserHistory(ctx context.Context, userAddress string) (model.HistoryData, error) {
 	logger := utils.GetLogger()
 	logger.Info().Str("user", userAddress).Msg("Fetching user history")
 
-	deposits, err := hs.collateralStore.GetLatestDeposits(ctx, userAddress, 10)
+	entries, err := hs.transactionStore.GetLatestTransactions(ctx, userAddress, latestTransactionsPerType)
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to fetch deposits")
+		logger.Error().Err(err).Msg("Failed to fetch latest transactions")
 		return model.HistoryData{}, err
 	}
 
-	redeems, err := hs.collateralStore.GetLatestRedeems(ctx, userAddress, 10)
-	if err != nil {
-		logger.Error().Err(err).Msg("Failed to fetch redeems")
-		return model.HistoryData{}, err
+	history := model.HistoryData{
+		Deposits:     make([]model.Transaction, 0),
+		MintBurn:     make([]model.Transaction, 0),
+		Liquidations: make([]model.Transaction, 0),
+	}
+
+	for _, entry := range entries {
+		transaction := toTransaction(entry)
+
+		switch entry.Type {
+		case model.TransactionTypeDeposit, model.TransactionTypeRedeem:
+			history.Deposits = append(history.Deposits, transaction)
+		case model.TransactionTypeMint, model.TransactionTypeBurn:
+			history.MintBurn = append(history.MintBurn, transaction)
+		case model.TransactionTypeLiquidation:
+			history.Liquidations = append(history.Liquidations, transaction)
+		}
+	}
+
+	logger.Info().
+		Int("deposits_redeems", len(history.Deposits)).
+		Int("mint_burns", len(history.MintBurn)).
+		Int("liquidations", len(history.Liquidations)).
+		Msg("User history fetched successfully")
+
+	return history, nil
+}
+
+// GetUserHistoryPage returns one page of the user's transactions across every type, newest first.
+// NextCursor is empty on the last page.
+func (hs *HistoryService) GetUserHistoryPage(ctx context.Context, userAddress string, query model.HistoryQuery) (model.HistoryPage, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("user", userAddress).Int("limit", query.Limit).Msg("Fetching user history page")
+
+	var before *model.HistoryCursor
+	if query.Cursor != "" {
+		cursor, err := domain.DecodeHistoryCursor(query.Cursor)
+		if err != nil {
+			return model.HistoryPage{}, err
+		}
+		before = &cursor
 	}
 
-	mints, err := hs.coinStore.GetLatestMints(ctx, userAddress, 10)
+	entries, total, err := hs.transactionStore.GetTransactions(ctx, userAddress, query.HistoryFilter, before, query.Limit+1)
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to fetch mints")
-		return model.HistoryData{}, err
+		logger.Error().Err(err).Msg("Failed to fetch transaction history page")
+		return model.HistoryPage{}, err
+	}
+
+	// The total comes with the page rows, so a cursor past the last transaction needs its own count.
+	if len(entries) == 0 && before != nil {
+		total, err = hs.transactionStore.CountTransactions(ctx, userAddress, query.HistoryFilter)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to count transaction history")
+			return model.HistoryPage{}, err
+		}
 	}
 
-	burns, err := hs.coinStore.GetLatestBurns(ctx, userAddress, 10)
+	nextCursor := ""
+	if len(entries) > query.Limit {
+		entries = entries[:query.Limit]
+		last := entries[len(entries)-1]
+		nextCursor = domain.EncodeHistoryCursor(model.HistoryCursor{BlockNumber: last.BlockNumber, LogIndex: last.LogIndex, Key: last.RowKey})
+	}
+
+	transactions := make([]model.Transaction, 0, len(entries))
+	for _, entry := range entries {
+		transactions = append(transactions, toTransaction(entry))
+	}
+
+	logger.Info().Str("user", userAddress).Int("transactions", len(transactions)).Int64("total", total).Msg("User history page fetched successfully")
+
+	return model.HistoryPage{
+		Transactions: transactions,
+		NextCursor:   nextCursor,
+		TotalCount:   total,
+	}, nil
+}
+
+// GetUserHistoryOperations returns one page of the user's history grouped by transaction, newest
+// first. The position before the page is rebuilt from every earlier leg and replayed forward, so each
+// operation's health factors are priced at its own block. They are left empty when a price is missing.
+func (hs *HistoryService) GetUserHistoryOperations(ctx context.Context, userAddress string, query model.HistoryQuery) (model.HistoryOperationsPage, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("user", userAddress).Int("limit", query.Limit).Msg("Fetching user history operations")
+
+	var before *model.HistoryCursor
+	if query.Cursor != "" {
+		cursor, err := domain.DecodeHistoryCursor(query.Cursor)
+		if err != nil {
+			return model.HistoryOperationsPage{}, err
+		}
+		before = &cursor
+	}
+
+	keys, total, err := hs.transactionStore.GetOperations(ctx, userAddress, query.HistoryFilter, before, query.Limit+1)
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to fetch burns")
-		return model.HistoryData{}, err
+		logger.Error().Err(err).Msg("Failed to fetch history operations")
+		return model.HistoryOperationsPage{}, err
+	}
+
+	if len(keys) == 0 {
+		if before != nil {
+			total, err = hs.transactionStore.CountOperations(ctx, userAddress, query.HistoryFilter)
+			if err != nil {
+				logger.Error().Err(err).Msg("Failed to count history operations")
+				return model.HistoryOperationsPage{}, err
+			}
+		}
+		return model.HistoryOperationsPage{Operations: []model.HistoryOperation{}, TotalCount: total}, nil
+	}
+
+	nextCursor := ""
+	if len(keys) > query.Limit {
+		keys = keys[:query.Limit]
+		last := keys[len(keys)-1]
+		nextCursor = domain.EncodeHistoryCursor(model.HistoryCursor{BlockNumber: last.BlockNumber, LogIndex: last.LogIndex, Key: last.GroupKey})
+	}
+
+	groupKeys := make([]string, len(keys))
+	for i, key := range keys {
+		groupKeys[i] = key.GroupKey
 	}
 
-	liquidations, err := hs.liquidationStore.GetLatestLiquidations(ctx, userAddress, 10)
+	legs, err := hs.transactionStore.GetOperationLegs(ctx, userAddress, groupKeys)
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to fetch liquidations")
-		return model.HistoryData{}, err
+		logger.Error().Err(err).Msg("Failed to fetch history operation legs")
+		return model.HistoryOperationsPage{}, err
+	}
+
+	legsByKey := make(map[string][]model.HistoryEntry, len(keys))
+	for _, leg := range legs {
+		legsByKey[leg.GroupKey] = append(legsByKey[leg.GroupKey], leg)
 	}
 
-	depositTransactions := make([]model.Transaction, 0)
-	for _, d := range deposits {
-		event, err := hs.eventsStore.GetEventByID(ctx, d.EventID)
-		if err != nil || event == nil {
-			logger.Warn().Uint("event_id", d.EventID).Msg("Event not found for deposit")
-			continue
+	balances := domain.NewHistoryBalances()
+	if len(legs) > 0 {
+		earlier, err := hs.transactionStore.GetPositionBefore(ctx, userAddress, model.HistoryCursor{BlockNumber: legs[0].BlockNumber, LogIndex: legs[0].LogIndex, Key: legs[0].RowKey})
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to fetch position before history operations")
+			return model.HistoryOperationsPage{}, err
 		}
-		depositTransactions = append(depositTransactions, model.Transaction{
-			ID:        d.ID,
-			Type:      model.TransactionTypeDeposit,
-			Amount:    d.Amount.Int.String(),
-			Asset:     d.CollateralAddress,
-			Timestamp: time.Unix(event.CreatedAt, 0).Format(time.RFC3339),
-			TxHash:    event.TxHash,
-			Status:    model.TransactionStatusCompleted,
-		})
-	}
-
-	for _, r := range redeems {
-		event, err := hs.eventsStore.GetEventByID(ctx, r.EventID)
-		if err != nil || event == nil {
-			logger.Warn().Uint("event_id", r.EventID).Msg("Event not found for redeem")
-			continue
+		for _, entry := range earlier {
+			balances.Apply(entry)
 		}
-		depositTransactions = append(depositTransactions, model.Transaction{
-			ID:        r.ID,
-			Type:      model.TransactionTypeRedeem,
-			Amount:    r.Amount.Int.String(),
-			Asset:     r.CollateralAddress,
-			Timestamp: time.Unix(event.CreatedAt, 0).Format(time.RFC3339),
-			TxHash:    event.TxHash,
-			Status:    model.TransactionStatusCompleted,
-		})
-	}
-
-	mintBurnTransactions := make([]model.Transaction, 0)
-	for _, m := range mints {
-		event, err := hs.eventsStore.GetEventByID(ctx, m.EventID)
-		if err != nil || event == nil {
-			logger.Warn().Uint("event_id", m.EventID).Msg("Event not found for mint")
-			continue
+	}
+
+	blockNumbers := make([]uint64, 0, len(keys))
+	for _, key := range keys {
+		if !slices.Contains(blockNumbers, key.BlockNumber) {
+			blockNumbers = append(blockNumbers, key.BlockNumber)
 		}
-		mintBurnTransactions = append(mintBurnTransactions, model.Transaction{
-			ID:        m.ID,
-			Type:      model.TransactionTypeMint,
-			Amount:    m.Amount.Int.String(),
-			Timestamp: time.Unix(event.CreatedAt, 0).Format(time.RFC3339),
-			TxHash:    event.TxHash,
-			Status:    model.TransactionStatusCompleted,
-		})
-	}
-
-	for _, b := range burns {
-		event, err := hs.eventsStore.GetEventByID(ctx, b.EventID)
-		if err != nil || event == nil {
-			logger.Warn().Uint("event_id", b.EventID).Msg("Event not found for burn")
-			continue
+	}
+	prices, err := hs.priceStore.GetPricesAtBlocks(ctx, blockNumbers)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to fetch prices for history operations")
+		return model.HistoryOperationsPage{}, err
+	}
+
+	operations := make([]model.HistoryOperation, len(keys))
+	for i := len(keys) - 1; i >= 0; i-- {
+		operationLegs := legsByKey[keys[i].GroupKey]
+		assetPrices := pricesByAsset(prices[keys[i].BlockNumber])
+
+		operation := model.HistoryOperation{
+			Kind:               domain.HistoryOperationKind(operationLegs),
+			BlockNumber:        keys[i].BlockNumber,
+			Legs:               make([]model.Transaction, 0, len(operationLegs)),
+			HealthFactorBefore: historyHealthFactor(balances, assetPrices),
 		}
-		mintBurnTransactions = append(mintBurnTransactions, model.Transaction{
-			ID:        b.ID,
-			Type:      model.TransactionTypeBurn,
-			Amount:    b.Amount.Int.String(),
-			Timestamp: time.Unix(event.CreatedAt, 0).Format(time.RFC3339),
-			TxHash:    event.TxHash,
-			Status:    model.TransactionStatusCompleted,
-		})
-	}
-
-	liquidationTransactions := make([]model.Transaction, 0)
-	for _, l := range liquidations {
-		event, err := hs.eventsStore.GetEventByID(ctx, l.EventID)
-		if err != nil || event == nil {
-			logger.Warn().Uint("event_id", l.EventID).Msg("Event not found for liquidation")
-			continue
+
+		changes := domain.NewHistoryBalances()
+		for _, leg := range operationLegs {
+			balances.Apply(leg)
+			changes.Apply(leg)
+
+			transaction := toTransaction(leg)
+			operation.Legs = append(operation.Legs, transaction)
+			operation.TxHash = transaction.TxHash
+			operation.Timestamp = transaction.Timestamp
 		}
-		liquidationTransactions = append(liquidationTransactions, model.Transaction{
-			ID:        l.ID,
-			Type:      model.TransactionTypeLiquidation,
-			Amount:    l.DebtCovered.Int.String(),
-			Asset:     l.CollateralAddress,
-			Timestamp: time.Unix(event.CreatedAt, 0).Format(time.RFC3339),
-			TxHash:    event.TxHash,
-			Status:    model.TransactionStatusCompleted,
-		})
+
+		operation.CollateralChanges = changes.CollateralChanges()
+		operation.DebtChange = changes.Debt.String()
+		operation.HealthFactorAfter = historyHealthFactor(balances, assetPrices)
+		operations[i] = operation
 	}
 
-	logger.Info().
-		Int("deposits_redeems", len(depositTransactions)).
-		Int("mint_burns", len(mintBurnTransactions)).
-		Int("liquidations", len(liquidationTransactions)).
-		Msg("User history fetched successfully")
+	logger.Info().Str("user", userAddress).Int("operations", len(operations)).Int64("total", total).Msg("User history operations fetched successfully")
 
-	return model.HistoryData{
-		Deposits:     depositTransactions,
-		MintBurn:     mintBurnTransactions,
-		Liquidations: liquidationTransactions,
+	return model.HistoryOperationsPage{
+		Operations: operations,
+		NextCursor: nextCursor,
+		TotalCount: total,
 	}, nil
 }
+
+// ExportUserHistory streams the user's account statement, oldest first, without loading it into
+// memory. Mints and burns are valued at the AUSD peg of one dollar; liquidations are reported as
+// the collateral seized, bonus included.
+func (hs *HistoryService) ExportUserHistory(ctx context.Context, userAddress string, filter model.HistoryFilter, cb func(model.StatementRow) error) error {
+	logger := utils.GetLogger()
+	logger.Info().Str("user", userAddress).Msg("Exporting user history")
+
+	rows := 0
+	err := hs.transactionStore.IterateStatement(ctx, userAddress, filter, constants.CollateralTokens, func(entry model.StatementEntry) error {
+		rows++
+		return cb(toStatementRow(entry))
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("user", userAddress).Int("rows", rows).Msg("Failed to export user history")
+		return err
+	}
+
+	logger.Info().Str("user", userAddress).Int("rows", rows).Msg("User history exported successfully")
+	return nil
+}
+
+func toStatementRow(entry model.StatementEntry) model.StatementRow {
+	row := model.StatementRow{
+		BlockNumber: entry.BlockNumber,
+		TxHash:      entry.TxHash,
+		Type:        entry.Type,
+		Token:       stablecoinSymbol,
+		Amount:      domain.FormatUnits(entry.Amount.Int, tokenDecimals),
+	}
+	if entry.TxHash != "" {
+		row.Timestamp = time.Unix(entry.CreatedAt, 0).UTC().Format(time.RFC3339)
+	}
+
+	if entry.Type == model.TransactionTypeMint || entry.Type == model.TransactionTypeBurn {
+		row.ValueUSD = row.Amount
+		return row
+	}
+
+	amount := entry.Amount.Int
+	if entry.Type == model.TransactionTypeLiquidation {
+		amount = entry.CollateralAmount.Int
+	}
+	row.Token = collateralSymbol(entry.Asset)
+	row.Amount = domain.FormatUnits(amount, tokenDecimals)
+
+	if entry.PriceInUSD != nil {
+		if value, err := domain.GetTokenAmountInUSD(amount, *entry.PriceInUSD); err == nil {
+			row.ValueUSD = domain.FormatUnits(value, usdDecimals)
+		}
+	}
+	return row
+}
+
+// collateralSymbol returns the configured name of a collateral address, or the address itself.
+func collateralSymbol(address string) string {
+	for name, tokenAddress := range constants.CollateralTokens {
+		if strings.EqualFold(tokenAddress, address) {
+			return name
+		}
+	}
+	return address
+}
+
+// pricesByAsset rekeys prices from token name to lowercased collateral address.
+func pricesByAsset(pricesByName map[string]string) map[string]string {
+	prices := make(map[string]string, len(pricesByName))
+	for name, address := range constants.CollateralTokens {
+		if price, ok := pricesByName[name]; ok {
+			prices[strings.ToLower(address)] = price
+		}
+	}
+	return prices
+}
+
+func historyHealthFactor(balances domain.HistoryBalances, prices map[string]string) string {
+	position, err := balances.Position(prices)
+	if err != nil {
+		logger := utils.GetLogger()
+		logger.Debug().Err(err).Msg("Cannot price history position")
+		return ""
+	}
+	return position.HealthFactor().String()
+}
+
+// toTransaction maps a history row to its API shape. Rows whose event was never stored have no
+// tx hash or timestamp; they are still returned so the user's balances add up.
+func toTransaction(entry model.HistoryEntry) model.Transaction {
+	transaction := model.Transaction{
+		ID:          entry.ID,
+		Type:        entry.Type,
+		Amount:      entry.Amount.Int.String(),
+		Asset:       entry.Asset,
+		TxHash:      entry.TxHash,
+		Status:      model.TransactionStatusCompleted,
+		BlockNumber: entry.BlockNumber,
+	}
+
+	if entry.TxHash == "" {
+		logger := utils.GetLogger()
+		logger.Warn().Str("id", entry.ID).Str("type", string(entry.Type)).Msg("Event not found for transaction")
+		return transaction
+	}
+
+	transaction.Timestamp = time.Unix(entry.CreatedAt, 0).UTC().Format(time.RFC3339)
+	return transaction
+}
@@ -5,180 +5,169 @@ import (
 	"errors"
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-type MockCollateralHistoryStore struct {
+type MockTransactionHistoryStore struct {
 	mock.Mock
 }
 
-func (m *MockCollateralHistoryStore) GetLatestDeposits(ctx context.Context, userAddress string, limit int) ([]model.Deposit, error) {
-	args := m.Called(ctx, userAddress, limit)
+func (m *MockTransactionHistoryStore) GetTransactions(ctx context.Context, userAddress string, filter model.HistoryFilter, before *model.HistoryCursor, limit int) ([]model.HistoryEntry, int64, error) {
+	args := m.Called(ctx, userAddress, filter, before, limit)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, 0, args.Error(2)
 	}
-	return args.Get(0).([]model.Deposit), args.Error(1)
+	return args.Get(0).([]model.HistoryEntry), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockCollateralHistoryStore) GetLatestRedeems(ctx context.Context, userAddress string, limit int) ([]model.Redeem, error) {
-	args := m.Called(ctx, userAddress, limit)
+func (m *MockTransactionHistoryStore) GetLatestTransactions(ctx context.Context, userAddress string, perType int) ([]model.HistoryEntry, error) {
+	args := m.Called(ctx, userAddress, perType)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]model.Redeem), args.Error(1)
+	return args.Get(0).([]model.HistoryEntry), args.Error(1)
 }
 
-type MockCoinHistoryStore struct {
-	mock.Mock
+func (m *MockTransactionHistoryStore) CountTransactions(ctx context.Context, userAddress string, filter model.HistoryFilter) (int64, error) {
+	args := m.Called(ctx, userAddress, filter)
+	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockCoinHistoryStore) GetLatestMints(ctx context.Context, userAddress string, limit int) ([]model.Mints, error) {
-	args := m.Called(ctx, userAddress, limit)
+func (m *MockTransactionHistoryStore) GetOperations(ctx context.Context, userAddress string, filter model.HistoryFilter, before *model.HistoryCursor, limit int) ([]model.HistoryOperationKey, int64, error) {
+	args := m.Called(ctx, userAddress, filter, before, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]model.HistoryOperationKey), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTransactionHistoryStore) GetOperationLegs(ctx context.Context, userAddress string, groupKeys []string) ([]model.HistoryEntry, error) {
+	args := m.Called(ctx, userAddress, groupKeys)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]model.Mints), args.Error(1)
+	return args.Get(0).([]model.HistoryEntry), args.Error(1)
 }
 
-func (m *MockCoinHistoryStore) GetLatestBurns(ctx context.Context, userAddress string, limit int) ([]model.Burns, error) {
-	args := m.Called(ctx, userAddress, limit)
+func (m *MockTransactionHistoryStore) GetPositionBefore(ctx context.Context, userAddress string, cursor model.HistoryCursor) ([]model.HistoryEntry, error) {
+	args := m.Called(ctx, userAddress, cursor)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]model.Burns), args.Error(1)
+	return args.Get(0).([]model.HistoryEntry), args.Error(1)
 }
 
-type MockLiquidationHistoryStore struct {
-	mock.Mock
+func (m *MockTransactionHistoryStore) CountOperations(ctx context.Context, userAddress string, filter model.HistoryFilter) (int64, error) {
+	args := m.Called(ctx, userAddress, filter)
+	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockLiquidationHistoryStore) GetLatestLiquidations(ctx context.Context, userAddress string, limit int) ([]model.Liquidations, error) {
-	args := m.Called(ctx, userAddress, limit)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
+func (m *MockTransactionHistoryStore) IterateStatement(ctx context.Context, userAddress string, filter model.HistoryFilter, tokens map[string]string, cb func(model.StatementEntry) error) error {
+	args := m.Called(ctx, userAddress, filter, tokens, cb)
+	if entries, ok := args.Get(0).([]model.StatementEntry); ok {
+		for _, entry := range entries {
+			if err := cb(entry); err != nil {
+				return err
+			}
+		}
 	}
-	return args.Get(0).([]model.Liquidations), args.Error(1)
+	return args.Error(1)
 }
 
-type MockEventHistoryStore struct {
+type MockHistoryPriceStore struct {
 	mock.Mock
 }
 
-func (m *MockEventHistoryStore) GetEventByID(ctx context.Context, eventID uint) (*model.Events, error) {
-	args := m.Called(ctx, eventID)
+func (m *MockHistoryPriceStore) GetPricesAtBlocks(ctx context.Context, blockNumbers []uint64) (map[uint64]map[string]string, error) {
+	args := m.Called(ctx, blockNumbers)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*model.Events), args.Error(1)
+	return args.Get(0).(map[uint64]map[string]string), args.Error(1)
 }
 
 func TestNewHistoryService(t *testing.T) {
-	mockCollateral := new(MockCollateralHistoryStore)
-	mockCoin := new(MockCoinHistoryStore)
-	mockLiquidation := new(MockLiquidationHistoryStore)
-	mockEvents := new(MockEventHistoryStore)
+	mockTransactions := new(MockTransactionHistoryStore)
 
-	service := NewHistoryService(mockCollateral, mockCoin, mockLiquidation, mockEvents)
+	mockPrices := new(MockHistoryPriceStore)
+
+	service := NewHistoryService(mockTransactions, mockPrices)
 
 	assert.NotNil(t, service)
-	assert.Equal(t, mockCollateral, service.collateralStore)
-	assert.Equal(t, mockCoin, service.coinStore)
-	assert.Equal(t, mockLiquidation, service.liquidationStore)
-	assert.Equal(t, mockEvents, service.eventsStore)
+	assert.Equal(t, mockTransactions, service.transactionStore)
+	assert.Equal(t, mockPrices, service.priceStore)
 }
 
 func TestGetUserHistory_Success(t *testing.T) {
-	mockCollateral := new(MockCollateralHistoryStore)
-	mockCoin := new(MockCoinHistoryStore)
-	mockLiquidation := new(MockLiquidationHistoryStore)
-	mockEvents := new(MockEventHistoryStore)
-
-	service := NewHistoryService(mockCollateral, mockCoin, mockLiquidation, mockEvents)
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
 
 	ctx := context.Background()
 	userAddress := "0x123"
 
-	deposits := []model.Deposit{
-		{
-			ID:                "1",
-			EventID:           100,
-			CollateralAddress: "0xeth",
-			Amount:            model.BigInt{Int: big.NewInt(1000)},
-		},
-	}
-	mockCollateral.On("GetLatestDeposits", ctx, userAddress, 10).Return(deposits, nil)
-
-	redeems := []model.Redeem{
-		{
-			ID:                "2",
-			EventID:           101,
-			CollateralAddress: "0xbtc",
-			Amount:            model.BigInt{Int: big.NewInt(500)},
-		},
-	}
-	mockCollateral.On("GetLatestRedeems", ctx, userAddress, 10).Return(redeems, nil)
-
-	mints := []model.Mints{
-		{
-			ID:      "3",
-			EventID: 102,
-			Amount:  model.BigInt{Int: big.NewInt(2000)},
-		},
-	}
-	mockCoin.On("GetLatestMints", ctx, userAddress, 10).Return(mints, nil)
-
-	burns := []model.Burns{
-		{
-			ID:      "4",
-			EventID: 103,
-			Amount:  model.BigInt{Int: big.NewInt(1000)},
-		},
+	entries := []model.HistoryEntry{
+		{ID: "5", Type: model.TransactionTypeLiquidation, Amount: model.BigInt{Int: big.NewInt(3000)}, Asset: "0xeth", TxHash: "0xe", BlockNumber: 50, CreatedAt: 1609459600},
+		{ID: "4", Type: model.TransactionTypeBurn, Amount: model.BigInt{Int: big.NewInt(1000)}, TxHash: "0xd", BlockNumber: 40, LogIndex: 1, CreatedAt: 1609459500},
+		{ID: "2", Type: model.TransactionTypeRedeem, Amount: model.BigInt{Int: big.NewInt(500)}, Asset: "0xbtc", TxHash: "0xd", BlockNumber: 40, CreatedAt: 1609459500},
+		{ID: "3", Type: model.TransactionTypeMint, Amount: model.BigInt{Int: big.NewInt(2000)}, TxHash: "0xc", BlockNumber: 30, LogIndex: 1, CreatedAt: 1609459300},
+		{ID: "1", Type: model.TransactionTypeDeposit, Amount: model.BigInt{Int: big.NewInt(1000)}, Asset: "0xeth", TxHash: "0xc", BlockNumber: 30, CreatedAt: 1609459300},
 	}
-	mockCoin.On("GetLatestBurns", ctx, userAddress, 10).Return(burns, nil)
-
-	liquidations := []model.Liquidations{
-		{
-			ID:                "5",
-			EventID:           104,
-			CollateralAddress: "0xeth",
-			DebtCovered:       model.BigInt{Int: big.NewInt(3000)},
-		},
-	}
-	mockLiquidation.On("GetLatestLiquidations", ctx, userAddress, 10).Return(liquidations, nil)
+	mockTransactions.On("GetLatestTransactions", ctx, userAddress, latestTransactionsPerType).Return(entries, nil)
+
+	history, err := service.GetUserHistory(ctx, userAddress)
+
+	assert.NoError(t, err)
+	assert.Len(t, history.Deposits, 2)
+	assert.Equal(t, "2", history.Deposits[0].ID)
+	assert.Equal(t, model.TransactionTypeRedeem, history.Deposits[0].Type)
+	assert.Equal(t, "0xbtc", history.Deposits[0].Asset)
+	assert.Equal(t, "1", history.Deposits[1].ID)
+	assert.Equal(t, "1000", history.Deposits[1].Amount)
+	assert.Equal(t, "2021-01-01T00:01:40Z", history.Deposits[1].Timestamp)
+	assert.Len(t, history.MintBurn, 2)
+	assert.Equal(t, model.TransactionTypeBurn, history.MintBurn[0].Type)
+	assert.Equal(t, model.TransactionTypeMint, history.MintBurn[1].Type)
+	assert.Len(t, history.Liquidations, 1)
+	assert.Equal(t, "3000", history.Liquidations[0].Amount)
+	assert.Equal(t, model.TransactionStatusCompleted, history.Liquidations[0].Status)
+	mockTransactions.AssertExpectations(t)
+}
+
+func TestGetUserHistory_MissingEvent(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
 
-	event := &model.Events{
-		ID:        100,
-		TxHash:    "0xabcd",
-		CreatedAt: 1609459200,
+	ctx := context.Background()
+	userAddress := "0x123"
+
+	entries := []model.HistoryEntry{
+		{ID: "1", Type: model.TransactionTypeDeposit, Amount: model.BigInt{Int: big.NewInt(1000)}, Asset: "0xeth"},
 	}
-	mockEvents.On("GetEventByID", ctx, mock.AnythingOfType("uint")).Return(event, nil)
+	mockTransactions.On("GetLatestTransactions", ctx, userAddress, latestTransactionsPerType).Return(entries, nil)
 
 	history, err := service.GetUserHistory(ctx, userAddress)
 
 	assert.NoError(t, err)
-	assert.GreaterOrEqual(t, len(history.Deposits), 0)
-	assert.GreaterOrEqual(t, len(history.MintBurn), 0)
-	assert.GreaterOrEqual(t, len(history.Liquidations), 0)
-	mockCollateral.AssertExpectations(t)
-	mockCoin.AssertExpectations(t)
-	mockLiquidation.AssertExpectations(t)
+	assert.Len(t, history.Deposits, 1)
+	assert.Equal(t, "1000", history.Deposits[0].Amount)
+	assert.Empty(t, history.Deposits[0].TxHash)
+	assert.Empty(t, history.Deposits[0].Timestamp)
 }
 
-func TestGetUserHistory_DepositsError(t *testing.T) {
-	mockCollateral := new(MockCollateralHistoryStore)
-	mockCoin := new(MockCoinHistoryStore)
-	mockLiquidation := new(MockLiquidationHistoryStore)
-	mockEvents := new(MockEventHistoryStore)
-
-	service := NewHistoryService(mockCollateral, mockCoin, mockLiquidation, mockEvents)
+func TestGetUserHistory_StoreError(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
 
 	ctx := context.Background()
 	userAddress := "0x123"
 
-	mockCollateral.On("GetLatestDeposits", ctx, userAddress, 10).Return(nil, errors.New("database error"))
+	mockTransactions.On("GetLatestTransactions", ctx, userAddress, latestTransactionsPerType).Return(nil, errors.New("database error"))
 
 	_, err := service.GetUserHistory(ctx, userAddress)
 
@@ -186,113 +175,299 @@ func TestGetUserHistory_DepositsError(t *testing.T) {
 	assert.EqualError(t, err, "database error")
 }
 
-func TestGetUserHistory_RedeemsError(t *testing.T) {
-	mockCollateral := new(MockCollateralHistoryStore)
-	mockCoin := new(MockCoinHistoryStore)
-	mockLiquidation := new(MockLiquidationHistoryStore)
-	mockEvents := new(MockEventHistoryStore)
+func TestGetUserHistory_EmptyResults(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
 
-	service := NewHistoryService(mockCollateral, mockCoin, mockLiquidation, mockEvents)
+	ctx := context.Background()
+	userAddress := "0x999"
+
+	mockTransactions.On("GetLatestTransactions", ctx, userAddress, latestTransactionsPerType).Return([]model.HistoryEntry{}, nil)
+
+	history, err := service.GetUserHistory(ctx, userAddress)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, history.Deposits)
+	assert.Equal(t, 0, len(history.Deposits))
+	assert.Equal(t, 0, len(history.MintBurn))
+	assert.Equal(t, 0, len(history.Liquidations))
+}
+
+func TestGetUserHistoryPage_FirstPage(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
 
 	ctx := context.Background()
 	userAddress := "0x123"
+	filter := model.HistoryFilter{Types: []model.TransactionType{model.TransactionTypeDeposit, model.TransactionTypeMint}}
 
-	mockCollateral.On("GetLatestDeposits", ctx, userAddress, 10).Return([]model.Deposit{}, nil)
-	mockCollateral.On("GetLatestRedeems", ctx, userAddress, 10).Return(nil, errors.New("redeems error"))
+	entries := []model.HistoryEntry{
+		{ID: "3", Type: model.TransactionTypeMint, Amount: model.BigInt{Int: big.NewInt(2000)}, TxHash: "0xc", BlockNumber: 30, LogIndex: 1, CreatedAt: 1609459300},
+		{ID: "2", Type: model.TransactionTypeDeposit, Amount: model.BigInt{Int: big.NewInt(1000)}, Asset: "0xeth", TxHash: "0xb", BlockNumber: 30, LogIndex: 0, CreatedAt: 1609459300, RowKey: "deposit:2"},
+		{ID: "1", Type: model.TransactionTypeDeposit, Amount: model.BigInt{Int: big.NewInt(500)}, Asset: "0xeth", TxHash: "0xa", BlockNumber: 20, LogIndex: 4, CreatedAt: 1609459200},
+	}
+	mockTransactions.On("GetTransactions", ctx, userAddress, filter, (*model.HistoryCursor)(nil), 3).Return(entries, int64(5), nil)
 
-	_, err := service.GetUserHistory(ctx, userAddress)
+	page, err := service.GetUserHistoryPage(ctx, userAddress, model.HistoryQuery{HistoryFilter: filter, Limit: 2})
 
-	assert.Error(t, err)
-	assert.EqualError(t, err, "redeems error")
+	assert.NoError(t, err)
+	assert.Len(t, page.Transactions, 2)
+	assert.Equal(t, "3", page.Transactions[0].ID)
+	assert.Equal(t, "2000", page.Transactions[0].Amount)
+	assert.Equal(t, uint64(30), page.Transactions[0].BlockNumber)
+	assert.Equal(t, "0xeth", page.Transactions[1].Asset)
+	assert.Equal(t, int64(5), page.TotalCount)
+
+	cursor, err := domain.DecodeHistoryCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, model.HistoryCursor{BlockNumber: 30, LogIndex: 0, Key: "deposit:2"}, cursor)
+	mockTransactions.AssertExpectations(t)
+	mockTransactions.AssertNotCalled(t, "CountTransactions", mock.Anything, mock.Anything, mock.Anything)
 }
 
-func TestGetUserHistory_MintsError(t *testing.T) {
-	mockCollateral := new(MockCollateralHistoryStore)
-	mockCoin := new(MockCoinHistoryStore)
-	mockLiquidation := new(MockLiquidationHistoryStore)
-	mockEvents := new(MockEventHistoryStore)
+func TestGetUserHistoryPage_LastPage(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
 
-	service := NewHistoryService(mockCollateral, mockCoin, mockLiquidation, mockEvents)
+	ctx := context.Background()
+	userAddress := "0x123"
+	before := model.HistoryCursor{BlockNumber: 30, LogIndex: 0}
+
+	entries := []model.HistoryEntry{
+		{ID: "1", Type: model.TransactionTypeDeposit, Amount: model.BigInt{Int: big.NewInt(500)}, TxHash: "0xa", BlockNumber: 20, LogIndex: 4},
+	}
+	mockTransactions.On("GetTransactions", ctx, userAddress, model.HistoryFilter{}, &before, 3).Return(entries, int64(3), nil)
+
+	page, err := service.GetUserHistoryPage(ctx, userAddress, model.HistoryQuery{Cursor: domain.EncodeHistoryCursor(before), Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Transactions, 1)
+	assert.Empty(t, page.NextCursor)
+	assert.Equal(t, int64(3), page.TotalCount)
+	mockTransactions.AssertExpectations(t)
+}
+
+func TestGetUserHistoryPage_CursorPastEnd(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
 
 	ctx := context.Background()
 	userAddress := "0x123"
+	before := model.HistoryCursor{BlockNumber: 1, LogIndex: 0}
 
-	mockCollateral.On("GetLatestDeposits", ctx, userAddress, 10).Return([]model.Deposit{}, nil)
-	mockCollateral.On("GetLatestRedeems", ctx, userAddress, 10).Return([]model.Redeem{}, nil)
-	mockCoin.On("GetLatestMints", ctx, userAddress, 10).Return(nil, errors.New("mints error"))
+	mockTransactions.On("GetTransactions", ctx, userAddress, model.HistoryFilter{}, &before, 11).Return([]model.HistoryEntry{}, int64(0), nil)
+	mockTransactions.On("CountTransactions", ctx, userAddress, model.HistoryFilter{}).Return(int64(7), nil)
 
-	_, err := service.GetUserHistory(ctx, userAddress)
+	page, err := service.GetUserHistoryPage(ctx, userAddress, model.HistoryQuery{Cursor: domain.EncodeHistoryCursor(before), Limit: 10})
 
-	assert.Error(t, err)
-	assert.EqualError(t, err, "mints error")
+	assert.NoError(t, err)
+	assert.Empty(t, page.Transactions)
+	assert.Equal(t, int64(7), page.TotalCount)
+	mockTransactions.AssertExpectations(t)
 }
 
-func TestGetUserHistory_BurnsError(t *testing.T) {
-	mockCollateral := new(MockCollateralHistoryStore)
-	mockCoin := new(MockCoinHistoryStore)
-	mockLiquidation := new(MockLiquidationHistoryStore)
-	mockEvents := new(MockEventHistoryStore)
+func TestGetUserHistoryPage_InvalidCursor(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
 
-	service := NewHistoryService(mockCollateral, mockCoin, mockLiquidation, mockEvents)
+	_, err := service.GetUserHistoryPage(context.Background(), "0x123", model.HistoryQuery{Cursor: "not-a-cursor", Limit: 10})
+
+	assert.ErrorIs(t, err, model.ErrInvalidHistoryCursor)
+	mockTransactions.AssertNotCalled(t, "GetTransactions", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetUserHistoryPage_StoreError(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
+
+	ctx := context.Background()
+	mockTransactions.On("GetTransactions", ctx, "0x123", model.HistoryFilter{}, (*model.HistoryCursor)(nil), 11).Return(nil, int64(0), errors.New("database error"))
+
+	_, err := service.GetUserHistoryPage(ctx, "0x123", model.HistoryQuery{Limit: 10})
+
+	assert.EqualError(t, err, "database error")
+}
+
+func TestGetUserHistoryOperations_ReplaysPosition(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xEthAddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockTransactions := new(MockTransactionHistoryStore)
+	mockPrices := new(MockHistoryPriceStore)
+	service := NewHistoryService(mockTransactions, mockPrices)
 
 	ctx := context.Background()
 	userAddress := "0x123"
+	eth := big.NewInt(1e18)
+
+	keys := []model.HistoryOperationKey{
+		{GroupKey: "0xd", BlockNumber: 40, LogIndex: 3, TotalCount: 2},
+		{GroupKey: "0xc", BlockNumber: 30, LogIndex: 1, TotalCount: 2},
+	}
+	legs := []model.HistoryEntry{
+		{ID: "1", Type: model.TransactionTypeDeposit, Amount: model.BigInt{Int: new(big.Int).Mul(eth, big.NewInt(2))}, Asset: "0xethaddress", TxHash: "0xc", GroupKey: "0xc", BlockNumber: 30, LogIndex: 0, CreatedAt: 1609459300, RowKey: "deposit:1"},
+		{ID: "1", Type: model.TransactionTypeMint, Amount: model.BigInt{Int: new(big.Int).Mul(eth, big.NewInt(1000))}, TxHash: "0xc", GroupKey: "0xc", BlockNumber: 30, LogIndex: 1, CreatedAt: 1609459300},
+		{ID: "2", Type: model.TransactionTypeBurn, Amount: model.BigInt{Int: new(big.Int).Mul(eth, big.NewInt(500))}, TxHash: "0xd", GroupKey: "0xd", BlockNumber: 40, LogIndex: 2, CreatedAt: 1609459400},
+		{ID: "2", Type: model.TransactionTypeRedeem, Amount: model.BigInt{Int: eth}, Asset: "0xethaddress", TxHash: "0xd", GroupKey: "0xd", BlockNumber: 40, LogIndex: 3, CreatedAt: 1609459400},
+	}
+	earlier := []model.HistoryEntry{
+		{Type: model.TransactionTypeDeposit, Amount: model.BigInt{Int: eth}, Asset: "0xethaddress"},
+	}
 
-	mockCollateral.On("GetLatestDeposits", ctx, userAddress, 10).Return([]model.Deposit{}, nil)
-	mockCollateral.On("GetLatestRedeems", ctx, userAddress, 10).Return([]model.Redeem{}, nil)
-	mockCoin.On("GetLatestMints", ctx, userAddress, 10).Return([]model.Mints{}, nil)
-	mockCoin.On("GetLatestBurns", ctx, userAddress, 10).Return(nil, errors.New("burns error"))
+	mockTransactions.On("GetOperations", ctx, userAddress, model.HistoryFilter{}, (*model.HistoryCursor)(nil), 21).Return(keys, int64(2), nil)
+	mockTransactions.On("GetOperationLegs", ctx, userAddress, []string{"0xd", "0xc"}).Return(legs, nil)
+	mockTransactions.On("GetPositionBefore", ctx, userAddress, model.HistoryCursor{BlockNumber: 30, LogIndex: 0, Key: "deposit:1"}).Return(earlier, nil)
+	mockPrices.On("GetPricesAtBlocks", ctx, []uint64{40, 30}).Return(map[uint64]map[string]string{
+		30: {"ETH": "2000"},
+		40: {"ETH": "1000"},
+	}, nil)
 
-	_, err := service.GetUserHistory(ctx, userAddress)
+	page, err := service.GetUserHistoryOperations(ctx, userAddress, model.HistoryQuery{Limit: 20})
 
-	assert.Error(t, err)
-	assert.EqualError(t, err, "burns error")
+	assert.NoError(t, err)
+	assert.Empty(t, page.NextCursor)
+	assert.Equal(t, int64(2), page.TotalCount)
+	assert.Len(t, page.Operations, 2)
+
+	redeem := page.Operations[0]
+	assert.Equal(t, "burn + redeem", redeem.Kind)
+	assert.Equal(t, "0xd", redeem.TxHash)
+	assert.Len(t, redeem.Legs, 2)
+	assert.Equal(t, []model.CollateralChange{{Asset: "0xethaddress", Amount: "-1000000000000000000"}}, redeem.CollateralChanges)
+	assert.Equal(t, "-500000000000000000000", redeem.DebtChange)
+	// 3 ETH at $1000 against 1000 AUSD, then 2 ETH against 500 AUSD.
+	assert.Equal(t, "1500000000000000000", redeem.HealthFactorBefore)
+	assert.Equal(t, "2000000000000000000", redeem.HealthFactorAfter)
+
+	deposit := page.Operations[1]
+	assert.Equal(t, "deposit + mint", deposit.Kind)
+	assert.Equal(t, uint64(30), deposit.BlockNumber)
+	assert.Equal(t, constants.MAX_UINT256.String(), deposit.HealthFactorBefore)
+	assert.Equal(t, "3000000000000000000", deposit.HealthFactorAfter)
+	mockTransactions.AssertExpectations(t)
+	mockPrices.AssertExpectations(t)
 }
 
-func TestGetUserHistory_LiquidationsError(t *testing.T) {
-	mockCollateral := new(MockCollateralHistoryStore)
-	mockCoin := new(MockCoinHistoryStore)
-	mockLiquidation := new(MockLiquidationHistoryStore)
-	mockEvents := new(MockEventHistoryStore)
-
-	service := NewHistoryService(mockCollateral, mockCoin, mockLiquidation, mockEvents)
+func TestGetUserHistoryOperations_MissingPrice(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	mockPrices := new(MockHistoryPriceStore)
+	service := NewHistoryService(mockTransactions, mockPrices)
 
 	ctx := context.Background()
 	userAddress := "0x123"
 
-	mockCollateral.On("GetLatestDeposits", ctx, userAddress, 10).Return([]model.Deposit{}, nil)
-	mockCollateral.On("GetLatestRedeems", ctx, userAddress, 10).Return([]model.Redeem{}, nil)
-	mockCoin.On("GetLatestMints", ctx, userAddress, 10).Return([]model.Mints{}, nil)
-	mockCoin.On("GetLatestBurns", ctx, userAddress, 10).Return([]model.Burns{}, nil)
-	mockLiquidation.On("GetLatestLiquidations", ctx, userAddress, 10).Return(nil, errors.New("liquidations error"))
+	keys := []model.HistoryOperationKey{{GroupKey: "0xc", BlockNumber: 30, TotalCount: 3}, {GroupKey: "0xb", BlockNumber: 20, TotalCount: 3}}
+	legs := []model.HistoryEntry{
+		{ID: "1", Type: model.TransactionTypeDeposit, Amount: model.BigInt{Int: big.NewInt(1000)}, Asset: "0xunknown", TxHash: "0xc", GroupKey: "0xc", BlockNumber: 30, RowKey: "deposit:1"},
+	}
+	mockTransactions.On("GetOperations", ctx, userAddress, model.HistoryFilter{}, (*model.HistoryCursor)(nil), 2).Return(keys, int64(3), nil)
+	mockTransactions.On("GetOperationLegs", ctx, userAddress, []string{"0xc"}).Return(legs, nil)
+	mockTransactions.On("GetPositionBefore", ctx, userAddress, model.HistoryCursor{BlockNumber: 30, Key: "deposit:1"}).Return([]model.HistoryEntry{}, nil)
+	mockPrices.On("GetPricesAtBlocks", ctx, []uint64{30}).Return(map[uint64]map[string]string{}, nil)
 
-	_, err := service.GetUserHistory(ctx, userAddress)
+	page, err := service.GetUserHistoryOperations(ctx, userAddress, model.HistoryQuery{Limit: 1})
 
-	assert.Error(t, err)
-	assert.EqualError(t, err, "liquidations error")
+	assert.NoError(t, err)
+	assert.Len(t, page.Operations, 1)
+	assert.Equal(t, "deposit", page.Operations[0].Kind)
+	assert.Equal(t, constants.MAX_UINT256.String(), page.Operations[0].HealthFactorBefore)
+	assert.Empty(t, page.Operations[0].HealthFactorAfter)
+
+	cursor, err := domain.DecodeHistoryCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, model.HistoryCursor{BlockNumber: 30, Key: "0xc"}, cursor)
 }
 
-func TestGetUserHistory_EmptyResults(t *testing.T) {
-	mockCollateral := new(MockCollateralHistoryStore)
-	mockCoin := new(MockCoinHistoryStore)
-	mockLiquidation := new(MockLiquidationHistoryStore)
-	mockEvents := new(MockEventHistoryStore)
+func TestGetUserHistoryOperations_CursorPastEnd(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
 
-	service := NewHistoryService(mockCollateral, mockCoin, mockLiquidation, mockEvents)
+	ctx := context.Background()
+	userAddress := "0x123"
+	before := model.HistoryCursor{BlockNumber: 1}
+
+	mockTransactions.On("GetOperations", ctx, userAddress, model.HistoryFilter{}, &before, 11).Return([]model.HistoryOperationKey{}, int64(0), nil)
+	mockTransactions.On("CountOperations", ctx, userAddress, model.HistoryFilter{}).Return(int64(4), nil)
+
+	page, err := service.GetUserHistoryOperations(ctx, userAddress, model.HistoryQuery{Cursor: domain.EncodeHistoryCursor(before), Limit: 10})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, page.Operations)
+	assert.Empty(t, page.Operations)
+	assert.Equal(t, int64(4), page.TotalCount)
+	mockTransactions.AssertNotCalled(t, "GetOperationLegs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetUserHistoryOperations_StoreError(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
 
 	ctx := context.Background()
-	userAddress := "0x999"
+	mockTransactions.On("GetOperations", ctx, "0x123", model.HistoryFilter{}, (*model.HistoryCursor)(nil), 11).Return(nil, int64(0), errors.New("database error"))
 
-	mockCollateral.On("GetLatestDeposits", ctx, userAddress, 10).Return([]model.Deposit{}, nil)
-	mockCollateral.On("GetLatestRedeems", ctx, userAddress, 10).Return([]model.Redeem{}, nil)
-	mockCoin.On("GetLatestMints", ctx, userAddress, 10).Return([]model.Mints{}, nil)
-	mockCoin.On("GetLatestBurns", ctx, userAddress, 10).Return([]model.Burns{}, nil)
-	mockLiquidation.On("GetLatestLiquidations", ctx, userAddress, 10).Return([]model.Liquidations{}, nil)
+	_, err := service.GetUserHistoryOperations(ctx, "0x123", model.HistoryQuery{Limit: 10})
 
-	history, err := service.GetUserHistory(ctx, userAddress)
+	assert.EqualError(t, err, "database error")
+}
+
+func TestExportUserHistory(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xEthAddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
+
+	ctx := context.Background()
+	userAddress := "0x123"
+	from := time.Unix(1609459000, 0)
+	filter := model.HistoryFilter{From: &from}
+	price := "2000.5"
+
+	entries := []model.StatementEntry{
+		{HistoryEntry: model.HistoryEntry{Type: model.TransactionTypeDeposit, Amount: model.BigInt{Int: big.NewInt(1.5e18)}, Asset: "0xethaddress", TxHash: "0xa", BlockNumber: 10, CreatedAt: 1609459200}, PriceInUSD: &price},
+		{HistoryEntry: model.HistoryEntry{Type: model.TransactionTypeMint, Amount: model.BigInt{Int: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))}, TxHash: "0xa", BlockNumber: 10, LogIndex: 1, CreatedAt: 1609459200}},
+		{HistoryEntry: model.HistoryEntry{Type: model.TransactionTypeLiquidation, Amount: model.BigInt{Int: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))}, CollateralAmount: model.BigInt{Int: big.NewInt(0.5e18)}, Asset: "0xethaddress", TxHash: "0xb", BlockNumber: 20, CreatedAt: 1609459300}, PriceInUSD: &price},
+		{HistoryEntry: model.HistoryEntry{Type: model.TransactionTypeRedeem, Amount: model.BigInt{Int: big.NewInt(1e17)}, Asset: "0xother"}},
+	}
+	mockTransactions.On("IterateStatement", ctx, userAddress, filter, constants.CollateralTokens, mock.Anything).Return(entries, nil)
+
+	var rows []model.StatementRow
+	err := service.ExportUserHistory(ctx, userAddress, filter, func(row model.StatementRow) error {
+		rows = append(rows, row)
+		return nil
+	})
 
 	assert.NoError(t, err)
-	assert.Equal(t, 0, len(history.Deposits))
-	assert.Equal(t, 0, len(history.MintBurn))
-	assert.Equal(t, 0, len(history.Liquidations))
+	assert.Len(t, rows, 4)
+	assert.Equal(t, model.StatementRow{Timestamp: "2021-01-01T00:00:00Z", BlockNumber: 10, TxHash: "0xa", Type: model.TransactionTypeDeposit, Token: "ETH", Amount: "1.5", ValueUSD: "3000.75"}, rows[0])
+	assert.Equal(t, "AUSD", rows[1].Token)
+	assert.Equal(t, "1000", rows[1].Amount)
+	assert.Equal(t, "1000", rows[1].ValueUSD)
+	assert.Equal(t, "ETH", rows[2].Token)
+	assert.Equal(t, "0.5", rows[2].Amount)
+	assert.Equal(t, "1000.25", rows[2].ValueUSD)
+	assert.Equal(t, "0xother", rows[3].Token)
+	assert.Empty(t, rows[3].ValueUSD)
+	assert.Empty(t, rows[3].Timestamp)
+}
+
+func TestExportUserHistory_CallbackError(t *testing.T) {
+	mockTransactions := new(MockTransactionHistoryStore)
+	service := NewHistoryService(mockTransactions, new(MockHistoryPriceStore))
+
+	ctx := context.Background()
+	entries := []model.StatementEntry{
+		{HistoryEntry: model.HistoryEntry{Type: model.TransactionTypeMint, Amount: model.BigInt{Int: big.NewInt(1)}}},
+		{HistoryEntry: model.HistoryEntry{Type: model.TransactionTypeBurn, Amount: model.BigInt{Int: big.NewInt(1)}}},
+	}
+	mockTransactions.On("IterateStatement", ctx, "0x123", model.HistoryFilter{}, mock.Anything, mock.Anything).Return(entries, nil)
+
+	calls := 0
+	err := service.ExportUserHistory(ctx, "0x123", model.HistoryFilter{}, func(model.StatementRow) error {
+		calls++
+		return errors.New("client disconnected")
+	})
+
+	assert.EqualError(t, err, "client disconnected")
+	assert.Equal(t, 1, calls)
 }
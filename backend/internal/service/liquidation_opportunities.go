@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/external"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+type liquidationOpportunitiesService struct {
+	Store     storage.ICacheStore
+	PriceFeed external.IPriceFeedAPI
+}
+
+func NewLiquidationOpportunitiesService(store storage.ICacheStore, priceFeed external.IPriceFeedAPI) *liquidationOpportunitiesService {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Initializing liquidation opportunities service")
+	return &liquidationOpportunitiesService{
+		Store:     store,
+		PriceFeed: priceFeed,
+	}
+}
+
+// GetLiquidationOpportunities evaluates every user in the liquidatable set with the contract's own
+// math and returns the ones a liquidator can profitably act on, ranked by expected USD profit.
+func (s *liquidationOpportunitiesService) GetLiquidationOpportunities(ctx context.Context) (model.LiquidationOpportunities, error) {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Getting liquidation opportunities")
+
+	prices, err := GetCollateralPrices(s.PriceFeed)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get collateral prices")
+		return model.LiquidationOpportunities{}, err
+	}
+
+	liquidatable, err := s.Store.HGetAll("liquidatable")
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get liquidatable users")
+		return model.LiquidationOpportunities{}, err
+	}
+
+	opportunities := make([]model.LiquidationOpportunity, 0, len(liquidatable))
+	profits := make(map[string]*big.Int, len(liquidatable))
+
+	for userAddress := range liquidatable {
+		position, err := getContractPosition(s.Store, userAddress, prices)
+		if err != nil {
+			logger.Warn().Err(err).Str("user", userAddress).Msg("Skipping user with incomplete position")
+			continue
+		}
+
+		plan, ok := domain.BestLiquidation(position)
+		if !ok {
+			logger.Debug().Str("user", userAddress).Msg("No profitable liquidation for user")
+			continue
+		}
+
+		profits[userAddress] = plan.ProfitUSD
+		opportunities = append(opportunities, model.LiquidationOpportunity{
+			Address:               userAddress,
+			HealthFactor:          plan.HealthFactorBefore.String(),
+			CollateralUsd:         position.CollateralValueUSD().String(),
+			Debt:                  position.Debt.String(),
+			Collateral:            plan.Token,
+			CollateralAddress:     constants.CollateralTokens[plan.Token],
+			DebtToCover:           plan.DebtToCover.String(),
+			CollateralReceived:    plan.CollateralSeized.String(),
+			BonusCollateral:       plan.BonusCollateral.String(),
+			CollateralReceivedUsd: plan.CollateralValueUSD.String(),
+			ProfitUsd:             plan.ProfitUSD.String(),
+			HealthFactorAfter:     plan.HealthFactorAfter.String(),
+		})
+	}
+
+	sort.SliceStable(opportunities, func(i, j int) bool {
+		return profits[opportunities[i].Address].Cmp(profits[opportunities[j].Address]) > 0
+	})
+
+	logger.Info().Int("liquidatable", len(liquidatable)).Int("opportunities", len(opportunities)).Msg("Liquidation opportunities retrieved successfully")
+
+	return model.LiquidationOpportunities{
+		Prices:        prices,
+		Opportunities: opportunities,
+	}, nil
+}
+
+// getContractPosition rebuilds the user's on-chain account state from the cached per-token balances
+// so it can be evaluated with the contract's 18 decimal math. A missing debt is returned as
+// storage.ErrCacheMiss and a missing collateral balance is skipped; any other read error is returned.
+func getContractPosition(store storage.ICacheStore, userAddress string, prices map[string]string) (domain.Position, error) {
+	debtStr, err := store.HGet("user:debt", userAddress)
+	if err != nil {
+		return domain.Position{}, err
+	}
+
+	debt, ok := new(big.Int).SetString(debtStr, 10)
+	if !ok {
+		return domain.Position{}, fmt.Errorf("invalid debt %q for user %s", debtStr, userAddress)
+	}
+
+	position := domain.Position{Debt: debt}
+	for name, tokenAddress := range constants.CollateralTokens {
+		amountStr, err := store.HGet("collateral:"+tokenAddress, userAddress)
+		if errors.Is(err, storage.ErrCacheMiss) {
+			continue
+		}
+		if err != nil {
+			return domain.Position{}, err
+		}
+
+		amount, ok := new(big.Int).SetString(amountStr, 10)
+		if !ok {
+			return domain.Position{}, fmt.Errorf("invalid %s collateral %q for user %s", name, amountStr, userAddress)
+		}
+		if amount.Sign() <= 0 {
+			continue
+		}
+
+		collateral, err := domain.NewCollateralPosition(name, amount, prices[name])
+		if err != nil {
+			return domain.Position{}, err
+		}
+		position.Collateral = append(position.Collateral, collateral)
+	}
+
+	return position, nil
+}
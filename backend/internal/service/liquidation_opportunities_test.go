@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLiquidationOpportunities(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	constants.CollateralTokens["BTC"] = "0xbtcaddress"
+	defer func() {
+		delete(constants.CollateralTokens, "ETH")
+		delete(constants.CollateralTokens, "BTC")
+	}()
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewLiquidationOpportunitiesService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockPriceFeed.On("GetBtcUsdPrice").Return("40000", nil)
+
+	mockCache.On("HGetAll", "liquidatable").Return(map[string]string{
+		"0xsmall":     "909090909090909090",
+		"0xlarge":     "666666666666666666",
+		"0xinsolvent": "526315789473684210",
+	}, nil)
+
+	mockCache.On("HGet", "user:debt", "0xsmall").Return("1100000000000000000000", nil)
+	mockCache.On("HGet", "user:debt", "0xlarge").Return("1500000000000000000000", nil)
+	mockCache.On("HGet", "user:debt", "0xinsolvent").Return("1900000000000000000000", nil)
+	for _, user := range []string{"0xsmall", "0xlarge", "0xinsolvent"} {
+		mockCache.On("HGet", "collateral:0xethaddress", user).Return("1000000000000000000", nil)
+		mockCache.On("HGet", "collateral:0xbtcaddress", user).Return("", storage.ErrCacheMiss)
+	}
+
+	result, err := service.GetLiquidationOpportunities(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"ETH": "2000", "BTC": "40000"}, result.Prices)
+	assert.Len(t, result.Opportunities, 2)
+
+	assert.Equal(t, "0xlarge", result.Opportunities[0].Address)
+	assert.Equal(t, "ETH", result.Opportunities[0].Collateral)
+	assert.Equal(t, "0xethaddress", result.Opportunities[0].CollateralAddress)
+	assert.Equal(t, "1500000000000000000000", result.Opportunities[0].DebtToCover)
+	assert.Equal(t, "825000000000000000", result.Opportunities[0].CollateralReceived)
+	assert.Equal(t, "75000000000000000", result.Opportunities[0].BonusCollateral)
+	assert.Equal(t, "150000000000000000000", result.Opportunities[0].ProfitUsd)
+
+	assert.Equal(t, "0xsmall", result.Opportunities[1].Address)
+	assert.Equal(t, "110000000000000000000", result.Opportunities[1].ProfitUsd)
+}
+
+func TestGetLiquidationOpportunities_PriceError(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewLiquidationOpportunitiesService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("", assert.AnError)
+
+	_, err := service.GetLiquidationOpportunities(context.Background())
+
+	assert.Error(t, err)
+	mockCache.AssertNotCalled(t, "HGetAll", "liquidatable")
+}
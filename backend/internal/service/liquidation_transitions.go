@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+const (
+	defaultLiquidationTransitionsMaxLen = 10000
+	liquidationTransitionsKey           = "liquidations:transitions"
+)
+
+// recordLiquidationTransition persists and publishes a move in or out of the liquidatable set.
+func recordLiquidationTransition(cacheStore storage.ICacheStore, snapshot model.HealthFactorSnapshot, change storage.HealthFactorChange) {
+	logger := utils.GetLogger()
+
+	transition := model.LiquidationTransition{
+		Address:              snapshot.UserAddress,
+		Direction:            model.LiquidationTransitionEnter,
+		HealthFactor:         snapshot.HealthFactor,
+		PreviousHealthFactor: change.PreviousHealthFactor,
+		CollateralUsd:        snapshot.CollateralUSD,
+		Debt:                 snapshot.Debt,
+		BlockNumber:          transitionBlockNumber(cacheStore, snapshot),
+		Prices:               transitionPrices(cacheStore, snapshot),
+		Source:               snapshot.Source,
+		Timestamp:            snapshot.Timestamp.UTC().Format(time.RFC3339),
+	}
+
+	if change.Transition == storage.LiquidatableExited {
+		transition.Direction = model.LiquidationTransitionExit
+		if sinceUnix, err := strconv.ParseInt(change.Since, 10, 64); err == nil {
+			transition.UnderwaterSeconds = snapshot.Timestamp.Unix() - sinceUnix
+		}
+	}
+
+	payload, err := json.Marshal(transition)
+	if err != nil {
+		logger.Error().Err(err).Str("user", snapshot.UserAddress).Msg("Failed to encode liquidation transition")
+		return
+	}
+
+	_, err = cacheStore.XAdd(liquidationTransitionsKey, utils.Int64FromEnv("LIQUIDATION_TRANSITIONS_MAX_LEN", defaultLiquidationTransitionsMaxLen), map[string]any{
+		"address":    snapshot.UserAddress,
+		"transition": string(payload),
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("user", snapshot.UserAddress).Msg("Failed to persist liquidation transition")
+	}
+
+	if err := cacheStore.Publish(constants.LIQUIDATIONS_CHANNEL, string(payload)); err != nil {
+		logger.Error().Err(err).Str("user", snapshot.UserAddress).Msg("Failed to publish liquidation transition")
+		return
+	}
+
+	logger.Info().Str("user", snapshot.UserAddress).Str("direction", string(transition.Direction)).Str("health_factor", snapshot.HealthFactor).Msg("Liquidation transition recorded")
+}
+
+// transitionBlockNumber falls back to the last block seen by the metrics workers for updates
+// that do not originate from an on-chain event, such as price driven re-evaluations.
+func transitionBlockNumber(cacheStore storage.ICacheStore, snapshot model.HealthFactorSnapshot) uint64 {
+	if snapshot.BlockNumber > 0 {
+		return snapshot.BlockNumber
+	}
+
+	lastBlock, err := cacheStore.HGet("sync", "last_block")
+	if err != nil {
+		return 0
+	}
+
+	blockNumber, err := strconv.ParseUint(lastBlock, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return blockNumber
+}
+
+func transitionPrices(cacheStore storage.ICacheStore, snapshot model.HealthFactorSnapshot) map[string]string {
+	if len(snapshot.Prices) > 0 {
+		return snapshot.Prices
+	}
+
+	prices, err := cacheStore.HGetAll("prices")
+	if err != nil {
+		return map[string]string{}
+	}
+	return prices
+}
+
+// CacheCollateralPrices stores the latest known collateral prices so that updates without
+// a price context can still be attributed to the prices they were computed with.
+func CacheCollateralPrices(cacheStore storage.ICacheStore, prices map[string]string) {
+	logger := utils.GetLogger()
+	for name, price := range prices {
+		if err := cacheStore.HSet("prices", name, price); err != nil {
+			logger.Error().Err(err).Str("token", name).Msg("Failed to cache collateral price")
+		}
+	}
+
+	if len(prices) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(model.PricesUpdate{
+		Prices:    prices,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to encode prices update")
+		return
+	}
+
+	if err := cacheStore.Publish(constants.PRICES_CHANNEL, string(payload)); err != nil {
+		logger.Error().Err(err).Msg("Failed to publish prices update")
+	}
+}
+
+type liquidationTransitionsService struct {
+	Store storage.ICacheStore
+}
+
+func NewLiquidationTransitionsService(store storage.ICacheStore) *liquidationTransitionsService {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Initializing liquidation transitions service")
+	return &liquidationTransitionsService{
+		Store: store,
+	}
+}
+
+func (s *liquidationTransitionsService) GetLiquidationTransitions(ctx context.Context, query model.LiquidationTransitionsQuery) (model.LiquidationTransitions, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("address", query.Address).Time("from", query.From).Time("to", query.To).Int("limit", query.Limit).Msg("Getting liquidation transitions")
+
+	entries, err := s.Store.XRange(
+		liquidationTransitionsKey,
+		strconv.FormatInt(query.From.UnixMilli(), 10),
+		strconv.FormatInt(query.To.UnixMilli(), 10),
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to read liquidation transitions")
+		return model.LiquidationTransitions{}, err
+	}
+
+	transitions := make([]model.LiquidationTransition, 0, len(entries))
+	for _, entry := range entries {
+		if query.Address != "" && !strings.EqualFold(entry.Values["address"], query.Address) {
+			continue
+		}
+
+		var transition model.LiquidationTransition
+		if err := json.Unmarshal([]byte(entry.Values["transition"]), &transition); err != nil {
+			logger.Warn().Err(err).Str("entry_id", entry.ID).Msg("Skipping invalid liquidation transition")
+			continue
+		}
+		transition.ID = entry.ID
+
+		transitions = append(transitions, transition)
+	}
+
+	if query.Limit > 0 && len(transitions) > query.Limit {
+		transitions = transitions[len(transitions)-query.Limit:]
+	}
+
+	logger.Info().Int("entries", len(entries)).Int("transitions", len(transitions)).Msg("Liquidation transitions retrieved successfully")
+
+	return model.LiquidationTransitions{
+		From:        query.From.UTC().Format(time.RFC3339),
+		To:          query.To.UTC().Format(time.RFC3339),
+		Transitions: transitions,
+	}, nil
+}
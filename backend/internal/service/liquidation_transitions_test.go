@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetLiquidationTransitions(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	service := NewLiquidationTransitionsService(mockCache)
+
+	from := time.Unix(1700000000, 0)
+	to := from.Add(time.Hour)
+
+	mockCache.On("XRange", "liquidations:transitions", "1700000000000", "1700003600000").Return([]storage.StreamEntry{
+		{ID: "1700000001000-0", Values: map[string]string{"address": "0xAAA", "transition": `{"address":"0xAAA","direction":"enter","healthFactor":"0"}`}},
+		{ID: "1700000002000-0", Values: map[string]string{"address": "0xBBB", "transition": `{"address":"0xBBB","direction":"enter","healthFactor":"0"}`}},
+		{ID: "1700000003000-0", Values: map[string]string{"address": "0xAAA", "transition": "not json"}},
+		{ID: "1700000004000-0", Values: map[string]string{"address": "0xAAA", "transition": `{"address":"0xAAA","direction":"exit","healthFactor":"2000000000000000000","underwaterSeconds":3}`}},
+	}, nil)
+
+	result, err := service.GetLiquidationTransitions(context.Background(), model.LiquidationTransitionsQuery{
+		Address: "0xaaa",
+		From:    from,
+		To:      to,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Transitions, 2)
+	assert.Equal(t, "1700000001000-0", result.Transitions[0].ID)
+	assert.Equal(t, model.LiquidationTransitionExit, result.Transitions[1].Direction)
+	assert.Equal(t, int64(3), result.Transitions[1].UnderwaterSeconds)
+}
+
+func TestGetLiquidationTransitions_Limit(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	service := NewLiquidationTransitionsService(mockCache)
+
+	mockCache.On("XRange", "liquidations:transitions", "0", "1000").Return([]storage.StreamEntry{
+		{ID: "1-0", Values: map[string]string{"address": "0xAAA", "transition": `{"address":"0xAAA","direction":"enter"}`}},
+		{ID: "2-0", Values: map[string]string{"address": "0xBBB", "transition": `{"address":"0xBBB","direction":"enter"}`}},
+	}, nil)
+
+	result, err := service.GetLiquidationTransitions(context.Background(), model.LiquidationTransitionsQuery{
+		From:  time.UnixMilli(0),
+		To:    time.UnixMilli(1000),
+		Limit: 1,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Transitions, 1)
+	assert.Equal(t, "2-0", result.Transitions[0].ID)
+}
+
+func TestGetLiquidationTransitions_Error(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	service := NewLiquidationTransitionsService(mockCache)
+
+	mockCache.On("XRange", "liquidations:transitions", "0", "1000").Return(nil, assert.AnError)
+
+	_, err := service.GetLiquidationTransitions(context.Background(), model.LiquidationTransitionsQuery{
+		From: time.UnixMilli(0),
+		To:   time.UnixMilli(1000),
+	})
+
+	assert.Error(t, err)
+}
+
+func TestCacheCollateralPrices(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	mockCache.On("HSet", "prices", "ETH", "2000").Return(nil)
+	mockCache.On("HSet", "prices", "BTC", "40000").Return(nil)
+	mockCache.On("Publish", constants.PRICES_CHANNEL, mock.MatchedBy(func(payload string) bool {
+		var update model.PricesUpdate
+		if err := json.Unmarshal([]byte(payload), &update); err != nil {
+			return false
+		}
+		return update.Prices["ETH"] == "2000" && update.Prices["BTC"] == "40000"
+	})).Return(nil)
+
+	CacheCollateralPrices(mockCache, map[string]string{"ETH": "2000", "BTC": "40000"})
+
+	mockCache.AssertExpectations(t)
+}
+
+func TestCacheCollateralPrices_Empty(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	CacheCollateralPrices(mockCache, map[string]string{})
+
+	mockCache.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+const recentLiquidatorLiquidations = 10
+
+type LiquidatorStore interface {
+	GetLiquidationsByLiquidator(ctx context.Context, liquidatorAddress string, limit int) ([]model.LiquidationRecord, error)
+	GetLiquidatorTotals(ctx context.Context, liquidatorAddress string) (model.LiquidatorTotals, error)
+	GetLiquidatorCollateral(ctx context.Context, liquidatorAddress string) ([]model.LiquidatorCollateralTotal, error)
+	GetLiquidatorLeaderboard(ctx context.Context, since *time.Time, limit int) ([]model.LiquidatorTotals, error)
+}
+
+type liquidatorsService struct {
+	Store LiquidatorStore
+}
+
+func NewLiquidatorsService(store LiquidatorStore) *liquidatorsService {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Initializing liquidators service")
+	return &liquidatorsService{
+		Store: store,
+	}
+}
+
+// GetLiquidatorSummary returns the liquidations performed by the address, as opposed to the ones
+// it suffered, with its totals and latest liquidations.
+func (s *liquidatorsService) GetLiquidatorSummary(ctx context.Context, address string) (model.LiquidatorSummary, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("liquidator", address).Msg("Getting liquidator summary")
+
+	totals, err := s.Store.GetLiquidatorTotals(ctx, address)
+	if err != nil {
+		logger.Error().Err(err).Str("liquidator", address).Msg("Failed to get liquidator totals")
+		return model.LiquidatorSummary{}, err
+	}
+
+	collateral, err := s.Store.GetLiquidatorCollateral(ctx, address)
+	if err != nil {
+		logger.Error().Err(err).Str("liquidator", address).Msg("Failed to get liquidator collateral")
+		return model.LiquidatorSummary{}, err
+	}
+
+	records, err := s.Store.GetLiquidationsByLiquidator(ctx, address, recentLiquidatorLiquidations)
+	if err != nil {
+		logger.Error().Err(err).Str("liquidator", address).Msg("Failed to get liquidator liquidations")
+		return model.LiquidatorSummary{}, err
+	}
+
+	summary := model.LiquidatorSummary{
+		Address:            address,
+		LiquidationCount:   totals.LiquidationCount,
+		DebtCovered:        totals.DebtCovered.Int.String(),
+		CollateralSeized:   make([]model.LiquidatorCollateral, 0, len(collateral)),
+		EstimatedBonusUSD:  domain.EstimateLiquidationBonusUSD(totals.DebtCovered.Int).String(),
+		RecentLiquidations: make([]model.LiquidatorLiquidation, 0, len(records)),
+	}
+
+	for _, total := range collateral {
+		summary.CollateralSeized = append(summary.CollateralSeized, model.LiquidatorCollateral{
+			Asset:  total.CollateralAddress,
+			Amount: total.CollateralAmount.Int.String(),
+		})
+	}
+
+	for _, record := range records {
+		liquidation := model.LiquidatorLiquidation{
+			ID:               record.ID,
+			LiquidatedUser:   record.LiquidatedUserAddress,
+			Collateral:       record.CollateralAddress,
+			CollateralAmount: record.CollateralAmount.Int.String(),
+			DebtCovered:      record.DebtCovered.Int.String(),
+			TxHash:           record.TxHash,
+			BlockNumber:      record.BlockNumber,
+		}
+		if record.TxHash != "" {
+			liquidation.Timestamp = time.Unix(record.CreatedAt, 0).UTC().Format(time.RFC3339)
+		}
+		summary.RecentLiquidations = append(summary.RecentLiquidations, liquidation)
+	}
+
+	logger.Info().Str("liquidator", address).Int64("liquidations", summary.LiquidationCount).Msg("Liquidator summary retrieved successfully")
+	return summary, nil
+}
+
+// GetLiquidatorLeaderboard ranks liquidators by debt covered within the query's window.
+func (s *liquidatorsService) GetLiquidatorLeaderboard(ctx context.Context, query model.LiquidatorLeaderboardQuery) (model.LiquidatorLeaderboard, error) {
+	logger := utils.GetLogger()
+	logger.Info().Str("window", query.Window).Int("limit", query.Limit).Msg("Getting liquidator leaderboard")
+
+	totals, err := s.Store.GetLiquidatorLeaderboard(ctx, query.Since, query.Limit)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get liquidator leaderboard")
+		return model.LiquidatorLeaderboard{}, err
+	}
+
+	leaderboard := model.LiquidatorLeaderboard{
+		Window:      query.Window,
+		Liquidators: make([]model.LiquidatorLeaderboardEntry, 0, len(totals)),
+	}
+	if query.Since != nil {
+		leaderboard.From = query.Since.UTC().Format(time.RFC3339)
+	}
+
+	for i, total := range totals {
+		leaderboard.Liquidators = append(leaderboard.Liquidators, model.LiquidatorLeaderboardEntry{
+			Rank:              i + 1,
+			Address:           total.LiquidatorAddress,
+			LiquidationCount:  total.LiquidationCount,
+			DebtCovered:       total.DebtCovered.Int.String(),
+			EstimatedBonusUSD: domain.EstimateLiquidationBonusUSD(total.DebtCovered.Int).String(),
+		})
+	}
+
+	logger.Info().Str("window", query.Window).Int("liquidators", len(leaderboard.Liquidators)).Msg("Liquidator leaderboard retrieved successfully")
+	return leaderboard, nil
+}
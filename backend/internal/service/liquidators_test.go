@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLiquidatorStore struct {
+	mock.Mock
+}
+
+func (m *MockLiquidatorStore) GetLiquidationsByLiquidator(ctx context.Context, liquidatorAddress string, limit int) ([]model.LiquidationRecord, error) {
+	args := m.Called(ctx, liquidatorAddress, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.LiquidationRecord), args.Error(1)
+}
+
+func (m *MockLiquidatorStore) GetLiquidatorTotals(ctx context.Context, liquidatorAddress string) (model.LiquidatorTotals, error) {
+	args := m.Called(ctx, liquidatorAddress)
+	return args.Get(0).(model.LiquidatorTotals), args.Error(1)
+}
+
+func (m *MockLiquidatorStore) GetLiquidatorCollateral(ctx context.Context, liquidatorAddress string) ([]model.LiquidatorCollateralTotal, error) {
+	args := m.Called(ctx, liquidatorAddress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.LiquidatorCollateralTotal), args.Error(1)
+}
+
+func (m *MockLiquidatorStore) GetLiquidatorLeaderboard(ctx context.Context, since *time.Time, limit int) ([]model.LiquidatorTotals, error) {
+	args := m.Called(ctx, since, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.LiquidatorTotals), args.Error(1)
+}
+
+func wei(amount int64) model.BigInt {
+	return model.BigInt{Int: new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e18))}
+}
+
+func TestGetLiquidatorSummary(t *testing.T) {
+	store := new(MockLiquidatorStore)
+	service := NewLiquidatorsService(store)
+
+	ctx := context.Background()
+	liquidator := "0xliquidator"
+
+	store.On("GetLiquidatorTotals", ctx, liquidator).Return(model.LiquidatorTotals{LiquidatorAddress: liquidator, LiquidationCount: 2, DebtCovered: wei(1500)}, nil)
+	store.On("GetLiquidatorCollateral", ctx, liquidator).Return([]model.LiquidatorCollateralTotal{
+		{CollateralAddress: "0xbtc", CollateralAmount: wei(1)},
+		{CollateralAddress: "0xeth", CollateralAmount: wei(3)},
+	}, nil)
+	store.On("GetLiquidationsByLiquidator", ctx, liquidator, recentLiquidatorLiquidations).Return([]model.LiquidationRecord{
+		{
+			Liquidations: model.Liquidations{ID: "l2", LiquidatedUserAddress: "0xuser", LiquidatorAddress: liquidator, CollateralAddress: "0xeth", CollateralAmount: wei(3), DebtCovered: wei(500)},
+			TxHash:       "0xb",
+			BlockNumber:  20,
+			CreatedAt:    1609459200,
+		},
+		{
+			Liquidations: model.Liquidations{ID: "l1", LiquidatedUserAddress: "0xother", LiquidatorAddress: liquidator, CollateralAddress: "0xbtc", CollateralAmount: wei(1), DebtCovered: wei(1000)},
+		},
+	}, nil)
+
+	summary, err := service.GetLiquidatorSummary(ctx, liquidator)
+
+	assert.NoError(t, err)
+	assert.Equal(t, liquidator, summary.Address)
+	assert.Equal(t, int64(2), summary.LiquidationCount)
+	assert.Equal(t, wei(1500).Int.String(), summary.DebtCovered)
+	assert.Equal(t, wei(150).Int.String(), summary.EstimatedBonusUSD)
+	assert.Equal(t, []model.LiquidatorCollateral{{Asset: "0xbtc", Amount: wei(1).Int.String()}, {Asset: "0xeth", Amount: wei(3).Int.String()}}, summary.CollateralSeized)
+	assert.Len(t, summary.RecentLiquidations, 2)
+	assert.Equal(t, "0xuser", summary.RecentLiquidations[0].LiquidatedUser)
+	assert.Equal(t, "2021-01-01T00:00:00Z", summary.RecentLiquidations[0].Timestamp)
+	assert.Empty(t, summary.RecentLiquidations[1].Timestamp)
+	store.AssertExpectations(t)
+}
+
+func TestGetLiquidatorSummary_NoLiquidations(t *testing.T) {
+	store := new(MockLiquidatorStore)
+	service := NewLiquidatorsService(store)
+
+	ctx := context.Background()
+	store.On("GetLiquidatorTotals", ctx, "0xnew").Return(model.LiquidatorTotals{LiquidatorAddress: "0xnew", DebtCovered: model.BigInt{Int: big.NewInt(0)}}, nil)
+	store.On("GetLiquidatorCollateral", ctx, "0xnew").Return([]model.LiquidatorCollateralTotal{}, nil)
+	store.On("GetLiquidationsByLiquidator", ctx, "0xnew", recentLiquidatorLiquidations).Return([]model.LiquidationRecord{}, nil)
+
+	summary, err := service.GetLiquidatorSummary(ctx, "0xnew")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "0", summary.DebtCovered)
+	assert.Equal(t, "0", summary.EstimatedBonusUSD)
+	assert.NotNil(t, summary.CollateralSeized)
+	assert.NotNil(t, summary.RecentLiquidations)
+}
+
+func TestGetLiquidatorSummary_StoreError(t *testing.T) {
+	store := new(MockLiquidatorStore)
+	service := NewLiquidatorsService(store)
+
+	ctx := context.Background()
+	store.On("GetLiquidatorTotals", ctx, "0xliquidator").Return(model.LiquidatorTotals{}, errors.New("database error"))
+
+	_, err := service.GetLiquidatorSummary(ctx, "0xliquidator")
+
+	assert.EqualError(t, err, "database error")
+	store.AssertNotCalled(t, "GetLiquidationsByLiquidator", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetLiquidatorLeaderboard(t *testing.T) {
+	store := new(MockLiquidatorStore)
+	service := NewLiquidatorsService(store)
+
+	ctx := context.Background()
+	since := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store.On("GetLiquidatorLeaderboard", ctx, &since, 2).Return([]model.LiquidatorTotals{
+		{LiquidatorAddress: "0xa", LiquidationCount: 1, DebtCovered: wei(1000)},
+		{LiquidatorAddress: "0xb", LiquidationCount: 4, DebtCovered: wei(200)},
+	}, nil)
+
+	leaderboard, err := service.GetLiquidatorLeaderboard(ctx, model.LiquidatorLeaderboardQuery{Window: "7d", Since: &since, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "7d", leaderboard.Window)
+	assert.Equal(t, "2021-01-01T00:00:00Z", leaderboard.From)
+	assert.Len(t, leaderboard.Liquidators, 2)
+	assert.Equal(t, model.LiquidatorLeaderboardEntry{Rank: 1, Address: "0xa", LiquidationCount: 1, DebtCovered: wei(1000).Int.String(), EstimatedBonusUSD: wei(100).Int.String()}, leaderboard.Liquidators[0])
+	assert.Equal(t, 2, leaderboard.Liquidators[1].Rank)
+}
+
+func TestGetLiquidatorLeaderboard_AllTime(t *testing.T) {
+	store := new(MockLiquidatorStore)
+	service := NewLiquidatorsService(store)
+
+	ctx := context.Background()
+	store.On("GetLiquidatorLeaderboard", ctx, (*time.Time)(nil), 10).Return([]model.LiquidatorTotals{}, nil)
+
+	leaderboard, err := service.GetLiquidatorLeaderboard(ctx, model.LiquidatorLeaderboardQuery{Window: "all", Limit: 10})
+
+	assert.NoError(t, err)
+	assert.Empty(t, leaderboard.From)
+	assert.NotNil(t, leaderboard.Liquidators)
+	assert.Empty(t, leaderboard.Liquidators)
+}
@@ -4,6 +4,7 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -74,6 +75,11 @@ func (m *MockCacheStore) HAdd(key, field string, amountInWei *big.Int) (*big.Int
 	return args.Get(0).(*big.Int), args.Error(1)
 }
 
+func (m *MockCacheStore) HDel(key string, fields ...string) error {
+	args := m.Called(key, fields)
+	return args.Error(0)
+}
+
 func (m *MockCacheStore) SSet(key string, members ...string) error {
 	args := m.Called(key, members)
 	return args.Error(0)
@@ -87,7 +93,48 @@ func (m *MockCacheStore) SGetAll(key string) ([]string, error) {
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (m *MockCacheStore) FlushAll() error {
+func (m *MockCacheStore) XAdd(key string, maxLen int64, values map[string]any) (string, error) {
+	args := m.Called(key, maxLen, values)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockCacheStore) XRange(key string, start string, stop string) ([]storage.StreamEntry, error) {
+	args := m.Called(key, start, stop)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]storage.StreamEntry), args.Error(1)
+}
+
+func (m *MockCacheStore) Publish(channel string, message any) error {
+	args := m.Called(channel, message)
+	return args.Error(0)
+}
+
+func (m *MockCacheStore) Subscribe(channels ...string) storage.Subscription {
+	args := m.Called(channels)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(storage.Subscription)
+}
+
+func (m *MockCacheStore) SetHealthFactor(user string, healthFactor string, liquidatable bool, at time.Time) (storage.HealthFactorChange, error) {
+	args := m.Called(user, healthFactor, liquidatable, at)
+	return args.Get(0).(storage.HealthFactorChange), args.Error(1)
+}
+
+func (m *MockCacheStore) PruneLiquidatableSince() (int64, error) {
 	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCacheStore) XTrimMinID(key string, minID string) error {
+	args := m.Called(key, minID)
+	return args.Error(0)
+}
+
+func (m *MockCacheStore) FlushAllExcept(patterns ...string) error {
+	args := m.Called(patterns)
 	return args.Error(0)
 }
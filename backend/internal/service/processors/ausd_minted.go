@@ -31,6 +31,7 @@ func ProcessAUSDMinted(eventName string, log types.Log, metricsChan chan<- model
 		TxHash:      log.TxHash.Hex(),
 		LogIndex:    log.Index,
 		Name:        eventName,
+		CreatedAt:   int64(log.BlockTimestamp),
 	}
 
 	err := storage.GetEventsStore().Create(context.Background(), eventModel)
@@ -67,6 +68,7 @@ func ProcessAUSDMinted(eventName string, log types.Log, metricsChan chan<- model
 		Asset:       model.StablecoinAsset,
 		Operation:   model.Addition,
 		BlockNumber: eventModel.BlockNumber,
+		Event:       eventModel,
 	}
 
 	metricsChan <- metric
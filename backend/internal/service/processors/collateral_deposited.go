@@ -33,6 +33,7 @@ func ProcessCollateralDeposited(eventName string, log types.Log, metricsChan cha
 		TxHash:      log.TxHash.Hex(),
 		LogIndex:    log.Index,
 		Name:        eventName,
+		CreatedAt:   int64(log.BlockTimestamp),
 	}
 
 	err := storage.GetEventsStore().Create(context.Background(), eventModel)
@@ -71,6 +72,7 @@ func ProcessCollateralDeposited(eventName string, log types.Log, metricsChan cha
 		Operation:   model.Addition,
 		BlockNumber: eventModel.BlockNumber,
 		CollateralTokenAddress: event.TokenAddr,
+		Event: eventModel,
 	}
 
 	metricsChan <- metric
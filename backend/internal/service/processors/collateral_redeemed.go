@@ -31,6 +31,7 @@ func ProcessCollateralRedeemed(eventName string, log types.Log, metricsChan chan
 		TxHash:      log.TxHash.Hex(),
 		LogIndex:    log.Index,
 		Name:        eventName,
+		CreatedAt:   int64(log.BlockTimestamp),
 	}
 
 	err := storage.GetEventsStore().Create(context.Background(), eventModel)
@@ -68,6 +69,7 @@ func ProcessCollateralRedeemed(eventName string, log types.Log, metricsChan chan
 		Asset:       model.CollateralAsset,
 		Operation:   model.Subtraction,
 		BlockNumber: eventModel.BlockNumber,
+		Event:       eventModel,
 	}
 
 	metricsChan <- metric
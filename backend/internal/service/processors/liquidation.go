@@ -38,6 +38,7 @@ func ProcessLiquidation(eventName string, log types.Log, metricsChan chan<- mode
 		TxHash:      log.TxHash.Hex(),
 		LogIndex:    log.Index,
 		Name:        eventName,
+		CreatedAt:   int64(log.BlockTimestamp),
 	}
 
 	err := storage.GetEventsStore().Create(context.Background(), eventModel)
@@ -81,6 +82,7 @@ func ProcessLiquidation(eventName string, log types.Log, metricsChan chan<- mode
 		Operation:              model.Subtraction,
 		BlockNumber:            eventModel.BlockNumber,
 		CollateralTokenAddress: event.TokenCollateral,
+		Event:                  eventModel,
 	}
 
 	metricsChan <- model.Metrics{
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/service"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
 )
@@ -44,6 +45,14 @@ func ProcessCoin(metric model.Metrics, cacheStore storage.ICacheStore) {
 
 	healthFactor := domain.CalculateHealthFactor(collateralUSDBigInt, debtBigInt)
 
-	cacheStore.HSet("user:health_factor", metric.UserAddress.Hex(), healthFactor.String())
+	service.RecordHealthFactor(cacheStore, model.HealthFactorSnapshot{
+		UserAddress:   metric.UserAddress.Hex(),
+		HealthFactor:  healthFactor.String(),
+		CollateralUSD: collateralUSDBigInt.String(),
+		Debt:          debtBigInt.String(),
+		Source:        model.HealthFactorSourceCoin,
+		BlockNumber:   metric.BlockNumber,
+	})
+	service.UpdateBadDebt(cacheStore)
 	logger.Info().Str("user", metric.UserAddress.Hex()).Str("health_factor", healthFactor.String()).Msg("Coin metric processed and health factor updated")
 }
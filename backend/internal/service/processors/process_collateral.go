@@ -8,6 +8,7 @@ import (
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/external"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/service"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
 )
@@ -58,7 +59,16 @@ func ProcessCollateral(metric model.Metrics, cacheStore storage.ICacheStore, pri
 
 	cacheStore.HAdd("collateral", "total_supply", usdAmountToChange)
 	cacheStore.HSet("user:collateral_usd", metric.UserAddress.Hex(), getCollateralUSDAmount.String())
-	cacheStore.HSet("user:health_factor", metric.UserAddress.Hex(), healthFactor.String())
+	service.RecordHealthFactor(cacheStore, model.HealthFactorSnapshot{
+		UserAddress:   metric.UserAddress.Hex(),
+		HealthFactor:  healthFactor.String(),
+		CollateralUSD: getCollateralUSDAmount.String(),
+		Debt:          debtBigInt.String(),
+		Source:        model.HealthFactorSourceCollateral,
+		BlockNumber:   metric.BlockNumber,
+		Prices:        getPricesInBlock(priceFeed, metric.BlockNumber, priceStore),
+	})
+	service.UpdateBadDebt(cacheStore)
 
 	logger.Info().Str("user", metric.UserAddress.Hex()).Str("health_factor", healthFactor.String()).Str("collateral_usd", getCollateralUSDAmount.String()).Msg("Collateral metric processed and health factor updated")
 }
@@ -127,6 +137,18 @@ func getCollateralUSDAmount(metric model.Metrics, priceFeed external.IPriceFeedA
 	return totalUSDValue, nil
 }
 
+func getPricesInBlock(priceFeed external.IPriceFeedAPI, blockNumber uint64, priceStore storage.IPriceStore) map[string]string {
+	prices := make(map[string]string, len(constants.CollateralTokens))
+	for name := range constants.CollateralTokens {
+		price, err := getPrice(priceFeed, name, blockNumber, priceStore)
+		if err != nil {
+			continue
+		}
+		prices[name] = price
+	}
+	return prices
+}
+
 func getPrice(priceFeed external.IPriceFeedAPI, name string, blockNumber uint64, priceStore storage.IPriceStore) (string, error) {
 	price, _ := priceStore.GetPriceInBlock(name, blockNumber)
 	if price != nil {
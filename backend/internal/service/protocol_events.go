@@ -0,0 +1,38 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+// PublishProtocolEvent notifies live subscribers that an indexed contract event was applied.
+// Metrics that do not carry their source event, such as the second metric of a liquidation, are skipped.
+func PublishProtocolEvent(cacheStore storage.ICacheStore, metric model.Metrics) {
+	logger := utils.GetLogger()
+
+	if metric.Event == nil {
+		return
+	}
+
+	payload, err := json.Marshal(model.ProtocolEventUpdate{
+		Name:        metric.Event.Name,
+		Address:     metric.UserAddress.Hex(),
+		TxHash:      metric.Event.TxHash,
+		BlockNumber: metric.Event.BlockNumber,
+		LogIndex:    metric.Event.LogIndex,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to encode protocol event update")
+		return
+	}
+
+	if err := cacheStore.Publish(constants.PROTOCOL_EVENTS_CHANNEL, string(payload)); err != nil {
+		logger.Error().Err(err).Str("event", metric.Event.Name).Msg("Failed to publish protocol event update")
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPublishProtocolEvent(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	user := common.HexToAddress("0x0000000000000000000000000000000000000123")
+
+	mockCache.On("Publish", constants.PROTOCOL_EVENTS_CHANNEL, mock.MatchedBy(func(payload string) bool {
+		var update model.ProtocolEventUpdate
+		if err := json.Unmarshal([]byte(payload), &update); err != nil {
+			return false
+		}
+		return update.Name == "AUSDMinted" &&
+			update.Address == user.Hex() &&
+			update.TxHash == "0xabc" &&
+			update.BlockNumber == 42 &&
+			update.LogIndex == 3 &&
+			update.Timestamp != ""
+	})).Return(nil)
+
+	PublishProtocolEvent(mockCache, model.Metrics{
+		UserAddress: user,
+		BlockNumber: 42,
+		Event: &model.Events{
+			Name:        "AUSDMinted",
+			TxHash:      "0xabc",
+			BlockNumber: 42,
+			LogIndex:    3,
+		},
+	})
+
+	mockCache.AssertExpectations(t)
+}
+
+func TestPublishProtocolEvent_WithoutEvent(t *testing.T) {
+	mockCache := new(MockCacheStore)
+
+	PublishProtocolEvent(mockCache, model.Metrics{BlockNumber: 42})
+
+	mockCache.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/external"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+type riskService struct {
+	Store     storage.ICacheStore
+	PriceFeed external.IPriceFeedAPI
+}
+
+func NewRiskService(store storage.ICacheStore, priceFeed external.IPriceFeedAPI) *riskService {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Initializing risk service")
+	return &riskService{
+		Store:     store,
+		PriceFeed: priceFeed,
+	}
+}
+
+// StressTest revalues every cached position at shocked collateral prices. It only reads from the
+// cache, so live positions, health factors and the liquidatable set are left untouched.
+func (s *riskService) StressTest(ctx context.Context, req model.StressTestRequest) (model.StressTestResult, error) {
+	logger := utils.GetLogger()
+	logger.Info().Interface("price_changes", req.PriceChanges).Interface("prices", req.Prices).Msg("Running stress test")
+
+	prices, err := GetCollateralPrices(s.PriceFeed)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get collateral prices")
+		return model.StressTestResult{}, err
+	}
+
+	currentPrices := make(map[string]*big.Int, len(prices))
+	shockedPrices := make(map[string]*big.Int, len(prices))
+	for name, price := range prices {
+		current, ok := domain.ParseDecimalToScaledInt(price, constants.PRICE_PRECISION)
+		if !ok {
+			return model.StressTestResult{}, fmt.Errorf("invalid %s price %q", name, price)
+		}
+		currentPrices[name] = current
+
+		shocked, err := shockedPrice(req, name, current)
+		if err != nil {
+			return model.StressTestResult{}, err
+		}
+		shockedPrices[name] = shocked
+	}
+
+	positions, err := s.getPositions(currentPrices)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to load cached positions")
+		return model.StressTestResult{}, err
+	}
+
+	outcome := domain.StressTest(positions, shockedPrices)
+
+	newlyLiquidatable := make([]model.StressedUser, 0, len(outcome.NewlyLiquidatable))
+	for _, position := range outcome.NewlyLiquidatable {
+		newlyLiquidatable = append(newlyLiquidatable, model.StressedUser{
+			Address:            position.Address,
+			HealthFactorBefore: position.HealthFactorBefore.String(),
+			HealthFactorAfter:  position.HealthFactorAfter.String(),
+			CollateralUsdAfter: position.CollateralUSDAfter.String(),
+			Debt:               position.Debt.String(),
+		})
+	}
+
+	stressPrices := make([]model.StressTestPrice, 0, len(currentPrices))
+	for name, current := range currentPrices {
+		stressPrices = append(stressPrices, model.StressTestPrice{
+			Asset:        name,
+			CurrentPrice: current.String(),
+			ShockedPrice: shockedPrices[name].String(),
+		})
+	}
+	sort.Slice(stressPrices, func(i, j int) bool { return stressPrices[i].Asset < stressPrices[j].Asset })
+
+	logger.Info().Int("users", outcome.UsersEvaluated).Int("newly_liquidatable", len(newlyLiquidatable)).Str("bad_debt", outcome.BadDebt.String()).Msg("Stress test completed successfully")
+
+	return model.StressTestResult{
+		Prices:                       stressPrices,
+		UsersEvaluated:               outcome.UsersEvaluated,
+		NewlyLiquidatable:            newlyLiquidatable,
+		LiquidatableUsers:            outcome.LiquidatableUsers,
+		DebtAtRisk:                   outcome.DebtAtRisk.String(),
+		BadDebt:                      outcome.BadDebt.String(),
+		TotalDebt:                    outcome.TotalDebt.String(),
+		TotalCollateralUsdBefore:     outcome.TotalCollateralUSDBefore.String(),
+		TotalCollateralUsdAfter:      outcome.TotalCollateralUSDAfter.String(),
+		CollateralizationRatioBefore: outcome.CollateralizationRatioBefore,
+		CollateralizationRatioAfter:  outcome.CollateralizationRatioAfter,
+	}, nil
+}
+
+func shockedPrice(req model.StressTestRequest, name string, current *big.Int) (*big.Int, error) {
+	if price, ok := req.Prices[name]; ok {
+		shocked, ok := domain.ParseDecimalToScaledInt(price, constants.PRICE_PRECISION)
+		if !ok || shocked.Sign() < 0 {
+			return nil, fmt.Errorf("invalid %s price %q", name, price)
+		}
+		return shocked, nil
+	}
+
+	if change, ok := req.PriceChanges[name]; ok {
+		return domain.ApplyPriceShock(current, int64(math.Round(change*100))), nil
+	}
+
+	return current, nil
+}
+
+// getPositions builds every cached position from the per-token balances and the debt hash.
+func (s *riskService) getPositions(prices map[string]*big.Int) (map[string]domain.Position, error) {
+	debts, err := s.Store.HGetAll("user:debt")
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]domain.Position, len(debts))
+	for userAddress, debtStr := range debts {
+		debt, ok := new(big.Int).SetString(debtStr, 10)
+		if !ok {
+			continue
+		}
+		positions[userAddress] = domain.Position{Debt: debt}
+	}
+
+	for name, tokenAddress := range constants.CollateralTokens {
+		balances, err := s.Store.HGetAll("collateral:" + tokenAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		for userAddress, amountStr := range balances {
+			amount, ok := new(big.Int).SetString(amountStr, 10)
+			if !ok || amount.Sign() <= 0 {
+				continue
+			}
+
+			position, exists := positions[userAddress]
+			if !exists {
+				position = domain.Position{Debt: big.NewInt(0)}
+			}
+			position.Collateral = append(position.Collateral, domain.CollateralPosition{
+				Token:  name,
+				Amount: amount,
+				Price:  prices[name],
+			})
+			positions[userAddress] = position
+		}
+	}
+
+	return positions, nil
+}
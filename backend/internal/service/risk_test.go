@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStressTest(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	constants.CollateralTokens["BTC"] = "0xbtcaddress"
+	defer func() {
+		delete(constants.CollateralTokens, "ETH")
+		delete(constants.CollateralTokens, "BTC")
+	}()
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewRiskService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("2000", nil)
+	mockPriceFeed.On("GetBtcUsdPrice").Return("40000", nil)
+
+	mockCache.On("HGetAll", "user:debt").Return(map[string]string{
+		"0xsafe":    "1000000000000000000000",
+		"0xnewly":   "1000000000000000000000",
+		"0xalready": "1500000000000000000000",
+	}, nil)
+	mockCache.On("HGetAll", "collateral:0xethaddress").Return(map[string]string{
+		"0xsafe":    "10000000000000000000",
+		"0xnewly":   "1000000000000000000",
+		"0xalready": "1000000000000000000",
+	}, nil)
+	mockCache.On("HGetAll", "collateral:0xbtcaddress").Return(map[string]string{
+		"0xnewly":  "10000000000000000",
+		"0xnodebt": "1000000000000000000",
+	}, nil)
+
+	result, err := service.StressTest(context.Background(), model.StressTestRequest{
+		PriceChanges: map[string]float64{"ETH": -30},
+		Prices:       map[string]string{"BTC": "32000"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.StressTestPrice{
+		{Asset: "BTC", CurrentPrice: "4000000000000", ShockedPrice: "3200000000000"},
+		{Asset: "ETH", CurrentPrice: "200000000000", ShockedPrice: "140000000000"},
+	}, result.Prices)
+	assert.Equal(t, 4, result.UsersEvaluated)
+	assert.Equal(t, 2, result.LiquidatableUsers)
+	if assert.Len(t, result.NewlyLiquidatable, 1) {
+		assert.Equal(t, "0xnewly", result.NewlyLiquidatable[0].Address)
+	}
+	assert.Equal(t, "2500000000000000000000", result.DebtAtRisk)
+	assert.Equal(t, "100000000000000000000", result.BadDebt)
+	assert.Equal(t, 1403.42, result.CollateralizationRatioAfter)
+
+	mockCache.AssertNotCalled(t, "HSet", mock.Anything, mock.Anything, mock.Anything)
+	mockCache.AssertNotCalled(t, "HDel", mock.Anything, mock.Anything)
+}
+
+func TestStressTest_PriceError(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewRiskService(mockCache, mockPriceFeed)
+
+	mockPriceFeed.On("GetEthUsdPrice").Return("", assert.AnError)
+
+	_, err := service.StressTest(context.Background(), model.StressTestRequest{
+		PriceChanges: map[string]float64{"ETH": -30},
+	})
+
+	assert.Error(t, err)
+}
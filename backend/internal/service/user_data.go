@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
@@ -30,21 +32,38 @@ func (s *userDataService) GetUserData(ctx context.Context, user string) (model.U
 	logger := utils.GetLogger()
 	logger.Info().Str("user", user).Msg("Getting user data from cache")
 
-	totalDebt, err := s.Store.HGet("user:debt", user)
+	totalDebt, debtFound, err := getCachedField(s.Store, "user:debt", user)
 	if err != nil {
-		logger.Debug().Err(err).Str("user", user).Msg("User debt not found in cache, defaulting to 0")
-		totalDebt = "0"
+		logger.Error().Err(err).Str("user", user).Msg("Failed to read user debt from cache")
+		return model.UserData{}, err
 	}
 
-	collateralValueUSD, err := s.Store.HGet("user:collateral_usd", user)
+	collateralValueUSD, collateralFound, err := getCachedField(s.Store, "user:collateral_usd", user)
 	if err != nil {
-		logger.Debug().Err(err).Str("user", user).Msg("User collateral USD not found in cache, defaulting to 0")
+		logger.Error().Err(err).Str("user", user).Msg("Failed to read user collateral USD from cache")
+		return model.UserData{}, err
+	}
+
+	if !debtFound && !collateralFound {
+		logger.Info().Str("user", user).Msg("User has no indexed position")
+		return model.UserData{}, fmt.Errorf("%w: %s", model.ErrUserNotFound, user)
+	}
+	if !debtFound {
+		logger.Debug().Str("user", user).Msg("User debt not found in cache, defaulting to 0")
+		totalDebt = "0"
+	}
+	if !collateralFound {
+		logger.Debug().Str("user", user).Msg("User collateral USD not found in cache, defaulting to 0")
 		collateralValueUSD = "0"
 	}
 
-	healthFactor, err := s.Store.HGet("user:health_factor", user)
+	healthFactor, found, err := getCachedField(s.Store, "user:health_factor", user)
 	if err != nil {
-		logger.Debug().Err(err).Str("user", user).Msg("User health factor not found in cache, defaulting to 0")
+		logger.Error().Err(err).Str("user", user).Msg("Failed to read user health factor from cache")
+		return model.UserData{}, err
+	}
+	if !found {
+		logger.Debug().Str("user", user).Msg("User health factor not found in cache, defaulting to 0")
 		healthFactor = "0"
 	}
 
@@ -52,16 +71,28 @@ func (s *userDataService) GetUserData(ctx context.Context, user string) (model.U
 
 	maxMintable := domain.CalculateMaxMintable(collateralValueUSD, totalDebt)
 
-	collateralAssets := s.fetchCollateralAssets(user)
+	collateralAssets, err := s.fetchCollateralAssets(user)
+	if err != nil {
+		logger.Error().Err(err).Str("user", user).Msg("Failed to fetch user collateral assets")
+		return model.UserData{}, err
+	}
 
 	collateralDeposited := domain.CalculateCollateralDeposited(collateralAssets)
 
+	liquidationPrices, liquidationDrop, err := calculateLiquidationPrices(collateralAssets, totalDebt)
+	if err != nil {
+		logger.Error().Err(err).Str("user", user).Msg("Failed to calculate liquidation prices")
+		return model.UserData{}, err
+	}
+
 	userData := model.UserData{
-		TotalDebt:           totalDebt,
-		CollateralValueUSD:  collateralValueUSD,
-		MaxMintable:         maxMintable,
-		CurrentHealthFactor: healthFactor,
-		CollateralDeposited: collateralDeposited,
+		TotalDebt:                 totalDebt,
+		CollateralValueUSD:        collateralValueUSD,
+		MaxMintable:               maxMintable,
+		CurrentHealthFactor:       healthFactor,
+		CollateralDeposited:       collateralDeposited,
+		LiquidationPrices:         liquidationPrices,
+		LiquidationDropPercentage: liquidationDrop,
 	}
 
 	logger.Info().Str("user", user).Str("max_mintable", maxMintable).Int("collateral_assets", len(collateralDeposited)).Msg("User data retrieved successfully")
@@ -69,37 +100,44 @@ func (s *userDataService) GetUserData(ctx context.Context, user string) (model.U
 	return userData, nil
 }
 
-func (s *userDataService) fetchCollateralAssets(user string) []domain.CollateralAssetData {
+// getCachedField reads a hash field, reporting a missing field through the second result. Any other
+// error is a cache failure and is returned instead of being read as an empty position.
+func getCachedField(store storage.ICacheStore, key string, field string) (string, bool, error) {
+	value, err := store.HGet(key, field)
+	if errors.Is(err, storage.ErrCacheMiss) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("read %s for %s: %w", key, field, err)
+	}
+	return value, true, nil
+}
+
+// fetchCollateralAssets returns the user's collateral with the live price of each token held. A token
+// the user holds none of is skipped; cache and price feed failures are returned, so the position is
+// never valued from part of its collateral.
+func (s *userDataService) fetchCollateralAssets(user string) ([]domain.CollateralAssetData, error) {
 	logger := utils.GetLogger()
 	assets := []domain.CollateralAssetData{}
 
-	ethPrice, _ := s.PriceFeed.GetEthUsdPrice()
-	btcPrice, _ := s.PriceFeed.GetBtcUsdPrice()
-
 	for name, tokenAddress := range constants.CollateralTokens {
-		collateralKey := "collateral:" + tokenAddress
-
-		amountStr, err := s.Store.HGet(collateralKey, user)
+		amountStr, found, err := getCachedField(s.Store, "collateral:"+tokenAddress, user)
 		if err != nil {
-			logger.Debug().Err(err).Str("user", user).Str("asset", name).Msg("No collateral found for this asset")
+			return nil, err
+		}
+		if !found {
+			logger.Debug().Str("user", user).Str("asset", name).Msg("No collateral found for this asset")
 			continue
 		}
 
 		amount := new(big.Int)
 		if _, ok := amount.SetString(amountStr, 10); !ok {
-			logger.Warn().Str("user", user).Str("asset", name).Str("amount", amountStr).Msg("Failed to parse collateral amount")
-			continue
+			return nil, fmt.Errorf("invalid %s collateral %q for user %s", name, amountStr, user)
 		}
 
-		var priceStr string
-		switch name {
-		case "ETH":
-			priceStr = ethPrice
-		case "BTC":
-			priceStr = btcPrice
-		default:
-			logger.Debug().Str("asset", name).Msg("Unknown asset type, skipping")
-			continue
+		priceStr, err := getTokenPrice(s.PriceFeed, name)
+		if err != nil {
+			return nil, err
 		}
 
 		assets = append(assets, domain.CollateralAssetData{
@@ -109,5 +147,50 @@ func (s *userDataService) fetchCollateralAssets(user string) []domain.Collateral
 		})
 	}
 
-	return assets
+	return assets, nil
+}
+
+// calculateLiquidationPrices returns the 8 decimal price at which each collateral makes the position
+// liquidatable, plus the uniform price drop that would do the same. Both are empty without debt. An
+// asset without a valid price is reported as model.ErrPriceUnavailable rather than left out.
+func calculateLiquidationPrices(assets []domain.CollateralAssetData, totalDebt string) ([]model.CollateralLiquidationPrice, *float64, error) {
+	liquidationPrices := []model.CollateralLiquidationPrice{}
+
+	debt, ok := new(big.Int).SetString(totalDebt, 10)
+	if !ok {
+		debt = big.NewInt(0)
+	}
+
+	position := domain.Position{Debt: debt}
+	for _, asset := range assets {
+		if asset.Amount == nil || asset.Amount.Sign() <= 0 {
+			continue
+		}
+
+		collateral, err := domain.NewCollateralPosition(asset.Name, asset.Amount, asset.PriceUSD)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w for %s: %v", model.ErrPriceUnavailable, asset.Name, err)
+		}
+		position.Collateral = append(position.Collateral, collateral)
+	}
+
+	for _, collateral := range position.Collateral {
+		price, ok := domain.LiquidationPrice(position, collateral.Token)
+		if !ok {
+			continue
+		}
+
+		liquidationPrices = append(liquidationPrices, model.CollateralLiquidationPrice{
+			Asset:            collateral.Token,
+			CurrentPrice:     collateral.Price.String(),
+			LiquidationPrice: price.String(),
+		})
+	}
+
+	drop, ok := domain.LiquidationPriceDropPercentage(position)
+	if !ok {
+		return liquidationPrices, nil, nil
+	}
+
+	return liquidationPrices, &drop, nil
 }
@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"math/big"
 	"testing"
 
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -34,7 +38,7 @@ func TestGetUserData_Success(t *testing.T) {
 
 	mockPriceFeed.On("GetEthUsdPrice").Return("3000000000000000000000", nil)
 	mockPriceFeed.On("GetBtcUsdPrice").Return("50000000000000000000000", nil)
-	mockCache.On("HGet", mock.Anything, userAddress).Return("", assert.AnError)
+	mockCache.On("HGet", mock.Anything, userAddress).Return("", storage.ErrCacheMiss)
 
 	userData, err := service.GetUserData(ctx, userAddress)
 
@@ -55,14 +59,14 @@ func TestGetUserData_NoDebt(t *testing.T) {
 	ctx := context.Background()
 	userAddress := "0x456"
 
-	mockCache.On("HGet", "user:debt", userAddress).Return("", assert.AnError)
+	mockCache.On("HGet", "user:debt", userAddress).Return("", storage.ErrCacheMiss)
 	mockCache.On("HGet", "user:collateral_usd", userAddress).Return("100000000000000000000", nil)
 	mockCache.On("HGet", "user:health_factor", userAddress).Return("0", nil)
 
 	mockPriceFeed.On("GetEthUsdPrice").Return("3000000000000000000000", nil)
 	mockPriceFeed.On("GetBtcUsdPrice").Return("50000000000000000000000", nil)
-	mockCache.On("HGet", "collateral:0xethaddress", userAddress).Return("", assert.AnError)
-	mockCache.On("HGet", "collateral:0xbtcaddress", userAddress).Return("", assert.AnError)
+	mockCache.On("HGet", "collateral:0xethaddress", userAddress).Return("", storage.ErrCacheMiss)
+	mockCache.On("HGet", "collateral:0xbtcaddress", userAddress).Return("", storage.ErrCacheMiss)
 
 	userData, err := service.GetUserData(ctx, userAddress)
 
@@ -80,13 +84,13 @@ func TestGetUserData_NoCollateral(t *testing.T) {
 	userAddress := "0x789"
 
 	mockCache.On("HGet", "user:debt", userAddress).Return("10000000000000000000", nil)
-	mockCache.On("HGet", "user:collateral_usd", userAddress).Return("", assert.AnError)
-	mockCache.On("HGet", "user:health_factor", userAddress).Return("", assert.AnError)
+	mockCache.On("HGet", "user:collateral_usd", userAddress).Return("", storage.ErrCacheMiss)
+	mockCache.On("HGet", "user:health_factor", userAddress).Return("", storage.ErrCacheMiss)
 
 	mockPriceFeed.On("GetEthUsdPrice").Return("3000000000000000000000", nil)
 	mockPriceFeed.On("GetBtcUsdPrice").Return("50000000000000000000000", nil)
-	mockCache.On("HGet", "collateral:0xethaddress", userAddress).Return("", assert.AnError)
-	mockCache.On("HGet", "collateral:0xbtcaddress", userAddress).Return("", assert.AnError)
+	mockCache.On("HGet", "collateral:0xethaddress", userAddress).Return("", storage.ErrCacheMiss)
+	mockCache.On("HGet", "collateral:0xbtcaddress", userAddress).Return("", storage.ErrCacheMiss)
 
 	userData, err := service.GetUserData(ctx, userAddress)
 
@@ -96,6 +100,37 @@ func TestGetUserData_NoCollateral(t *testing.T) {
 	assert.Equal(t, "0", userData.CurrentHealthFactor)
 }
 
+func TestGetUserData_UserNotFound(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewUserDataService(mockCache, mockPriceFeed)
+
+	userAddress := "0xabc"
+
+	mockCache.On("HGet", "user:debt", userAddress).Return("", storage.ErrCacheMiss)
+	mockCache.On("HGet", "user:collateral_usd", userAddress).Return("", storage.ErrCacheMiss)
+
+	_, err := service.GetUserData(context.Background(), userAddress)
+
+	assert.ErrorIs(t, err, model.ErrUserNotFound)
+	mockPriceFeed.AssertNotCalled(t, "GetEthUsdPrice")
+}
+
+func TestGetUserData_CacheFailure(t *testing.T) {
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewUserDataService(mockCache, mockPriceFeed)
+
+	userAddress := "0xabc"
+
+	mockCache.On("HGet", "user:debt", userAddress).Return("", assert.AnError)
+
+	_, err := service.GetUserData(context.Background(), userAddress)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NotErrorIs(t, err, model.ErrUserNotFound)
+}
+
 func TestFetchCollateralAssets_MultipleAssets(t *testing.T) {
 	constants.CollateralTokens["ETH"] = "0xethaddress"
 	constants.CollateralTokens["BTC"] = "0xbtcaddress"
@@ -115,26 +150,63 @@ func TestFetchCollateralAssets_MultipleAssets(t *testing.T) {
 	mockCache.On("HGet", "collateral:0xethaddress", userAddress).Return("2000000000000000000", nil)
 	mockCache.On("HGet", "collateral:0xbtcaddress", userAddress).Return("1000000000000000000", nil)
 
-	assets := service.fetchCollateralAssets(userAddress)
+	assets, err := service.fetchCollateralAssets(userAddress)
 
+	assert.NoError(t, err)
 	assert.Equal(t, 2, len(assets))
 }
 
 func TestFetchCollateralAssets_InvalidAmount(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
 	mockCache := new(MockCacheStore)
 	mockPriceFeed := new(MockPriceFeedAPI)
 	service := NewUserDataService(mockCache, mockPriceFeed)
 
 	userAddress := "0x456"
 
-	mockPriceFeed.On("GetEthUsdPrice").Return("3000000000000000000000", nil)
-	mockPriceFeed.On("GetBtcUsdPrice").Return("50000000000000000000000", nil)
 	mockCache.On("HGet", "collateral:0xethaddress", userAddress).Return("invalid_number", nil)
-	mockCache.On("HGet", "collateral:0xbtcaddress", userAddress).Return("", assert.AnError)
 
-	assets := service.fetchCollateralAssets(userAddress)
+	_, err := service.fetchCollateralAssets(userAddress)
 
-	assert.Equal(t, 0, len(assets))
+	assert.Error(t, err)
+}
+
+func TestFetchCollateralAssets_CacheFailure(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewUserDataService(mockCache, mockPriceFeed)
+
+	userAddress := "0x456"
+
+	mockCache.On("HGet", "collateral:0xethaddress", userAddress).Return("", assert.AnError)
+
+	_, err := service.fetchCollateralAssets(userAddress)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	mockPriceFeed.AssertNotCalled(t, "GetEthUsdPrice")
+}
+
+func TestFetchCollateralAssets_PriceUnavailable(t *testing.T) {
+	constants.CollateralTokens["ETH"] = "0xethaddress"
+	defer delete(constants.CollateralTokens, "ETH")
+
+	mockCache := new(MockCacheStore)
+	mockPriceFeed := new(MockPriceFeedAPI)
+	service := NewUserDataService(mockCache, mockPriceFeed)
+
+	userAddress := "0x456"
+
+	mockCache.On("HGet", "collateral:0xethaddress", userAddress).Return("1000000000000000000", nil)
+	mockPriceFeed.On("GetEthUsdPrice").Return("", model.ErrStalePrice)
+
+	_, err := service.fetchCollateralAssets(userAddress)
+
+	assert.ErrorIs(t, err, model.ErrStalePrice)
 }
 
 func TestFetchCollateralAssets_NoAssets(t *testing.T) {
@@ -146,10 +218,41 @@ func TestFetchCollateralAssets_NoAssets(t *testing.T) {
 
 	mockPriceFeed.On("GetEthUsdPrice").Return("3000000000000000000000", nil)
 	mockPriceFeed.On("GetBtcUsdPrice").Return("50000000000000000000000", nil)
-	mockCache.On("HGet", "collateral:0xethaddress", userAddress).Return("", assert.AnError)
-	mockCache.On("HGet", "collateral:0xbtcaddress", userAddress).Return("", assert.AnError)
+	mockCache.On("HGet", "collateral:0xethaddress", userAddress).Return("", storage.ErrCacheMiss)
+	mockCache.On("HGet", "collateral:0xbtcaddress", userAddress).Return("", storage.ErrCacheMiss)
 
-	assets := service.fetchCollateralAssets(userAddress)
+	assets, err := service.fetchCollateralAssets(userAddress)
 
+	assert.NoError(t, err)
 	assert.Equal(t, 0, len(assets))
 }
+
+func TestCalculateLiquidationPrices(t *testing.T) {
+	assets := []domain.CollateralAssetData{
+		{Name: "ETH", Amount: big.NewInt(1e18), PriceUSD: "2000"},
+		{Name: "BTC", Amount: big.NewInt(1e16), PriceUSD: "40000"},
+	}
+
+	prices, drop, err := calculateLiquidationPrices(assets, "800000000000000000000")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CollateralLiquidationPrice{
+		{Asset: "ETH", CurrentPrice: "200000000000", LiquidationPrice: "119999999999"},
+		{Asset: "BTC", CurrentPrice: "4000000000000", LiquidationPrice: "0"},
+	}, prices)
+	if assert.NotNil(t, drop) {
+		assert.Equal(t, 33.33, *drop)
+	}
+}
+
+func TestCalculateLiquidationPrices_NoDebt(t *testing.T) {
+	assets := []domain.CollateralAssetData{
+		{Name: "ETH", Amount: big.NewInt(1e18), PriceUSD: "2000"},
+	}
+
+	prices, drop, err := calculateLiquidationPrices(assets, "0")
+
+	assert.NoError(t, err)
+	assert.Empty(t, prices)
+	assert.Nil(t, drop)
+}
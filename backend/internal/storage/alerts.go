@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var alertStr alertStore
+
+type alertStore struct {
+	DB *gorm.DB
+}
+
+func NewAlertStore(db *gorm.DB) *alertStore {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Initializing alert store")
+	alertStr = alertStore{DB: db}
+	return &alertStr
+}
+
+func GetAlertStore() *alertStore {
+	return &alertStr
+}
+
+func (s *alertStore) CreateSubscription(ctx context.Context, subscription *model.AlertSubscription) error {
+	logger := utils.GetLogger()
+	logger.Debug().Str("address", subscription.Address).Msg("Creating alert subscription")
+
+	err := s.DB.WithContext(ctx).Create(subscription).Error
+	if err != nil {
+		logger.Error().Err(err).Str("address", subscription.Address).Msg("Failed to create alert subscription")
+		return err
+	}
+
+	logger.Info().Uint("subscription_id", subscription.ID).Str("address", subscription.Address).Msg("Alert subscription created successfully")
+	return nil
+}
+
+func (s *alertStore) GetSubscriptionsByAddress(ctx context.Context, address string) ([]model.AlertSubscription, error) {
+	var subscriptions []model.AlertSubscription
+	err := s.DB.WithContext(ctx).
+		Where("address = ?", address).
+		Order("id ASC").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (s *alertStore) GetSubscription(ctx context.Context, address string, id uint) (*model.AlertSubscription, error) {
+	var subscription model.AlertSubscription
+	err := s.DB.WithContext(ctx).Where("id = ? AND address = ?", id, address).First(&subscription).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (s *alertStore) DeleteSubscription(ctx context.Context, address string, id uint) (bool, error) {
+	logger := utils.GetLogger()
+
+	result := s.DB.WithContext(ctx).Where("id = ? AND address = ?", id, address).Delete(&model.AlertSubscription{})
+	if result.Error != nil {
+		logger.Error().Err(result.Error).Uint("subscription_id", id).Msg("Failed to delete alert subscription")
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// TriggerSubscription fires a subscription that has not fired yet and logs its pending delivery in the
+// same transaction, so an alert is never marked as sent without a delivery to send it. It reports
+// whether this call fired it, so only one of several replicas evaluating the same update delivers it.
+func (s *alertStore) TriggerSubscription(ctx context.Context, delivery *model.AlertDelivery, triggeredAt time.Time) (bool, error) {
+	triggered := false
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.AlertSubscription{}).
+			Where("id = ? AND triggered = ?", delivery.SubscriptionID, false).
+			Updates(map[string]any{"triggered": true, "last_triggered_at": triggeredAt})
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+
+		if err := tx.Create(delivery).Error; err != nil {
+			return err
+		}
+		triggered = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return triggered, nil
+}
+
+func (s *alertStore) RearmSubscription(ctx context.Context, id uint) error {
+	return s.DB.WithContext(ctx).Model(&model.AlertSubscription{}).
+		Where("id = ?", id).
+		Update("triggered", false).Error
+}
+
+func (s *alertStore) UpdateDelivery(ctx context.Context, delivery *model.AlertDelivery) error {
+	return s.DB.WithContext(ctx).Save(delivery).Error
+}
+
+// ClaimStalePendingDeliveries returns up to limit pending deliveries that were not updated since
+// staleBefore, which means the process delivering them stopped. Each one is claimed by bumping its
+// updated_at, so a delivery is only resumed by one replica.
+func (s *alertStore) ClaimStalePendingDeliveries(ctx context.Context, staleBefore time.Time, limit int) ([]model.AlertDelivery, error) {
+	logger := utils.GetLogger()
+
+	var stale []model.AlertDelivery
+	err := s.DB.WithContext(ctx).
+		Where("status = ? AND updated_at < ?", model.AlertDeliveryPending, staleBefore).
+		Order("id ASC").
+		Limit(limit).
+		Find(&stale).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]model.AlertDelivery, 0, len(stale))
+	for _, delivery := range stale {
+		now := time.Now()
+		result := s.DB.WithContext(ctx).Model(&model.AlertDelivery{}).
+			Where("id = ? AND status = ? AND updated_at = ?", delivery.ID, model.AlertDeliveryPending, delivery.UpdatedAt).
+			UpdateColumn("updated_at", now)
+		if result.Error != nil {
+			logger.Error().Err(result.Error).Uint("delivery_id", delivery.ID).Msg("Failed to claim alert delivery")
+			continue
+		}
+		if result.RowsAffected == 1 {
+			delivery.UpdatedAt = now
+			claimed = append(claimed, delivery)
+		}
+	}
+	return claimed, nil
+}
+
+func (s *alertStore) GetDeliveries(ctx context.Context, subscriptionID uint, limit int) ([]model.AlertDelivery, error) {
+	var deliveries []model.AlertDelivery
+	err := s.DB.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -2,7 +2,9 @@ package storage
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
+	"path"
 	"sync"
 	"time"
 
@@ -24,6 +26,10 @@ var incrByBigIntScript = redis.NewScript(`
 	return redis.call("INCRBY", KEYS[1], ARGV[1])
 `)
 
+// ErrCacheMiss is returned by Get and HGet when the key or hash field does not exist, so callers can
+// tell missing data apart from a failing Redis.
+var ErrCacheMiss = errors.New("cache miss")
+
 type CacheConfig interface {
 	GetAddress() string
 	GetPassword() string
@@ -34,6 +40,17 @@ type CacheStore struct {
 	mu     sync.RWMutex
 }
 
+type StreamEntry struct {
+	ID     string
+	Values map[string]string
+}
+
+// Subscription is a live pub/sub subscription; messages arrive on Channel until Close is called.
+type Subscription interface {
+	Channel() <-chan *redis.Message
+	Close() error
+}
+
 type ICacheStore interface {
 	Get(key string) (string, error)
 	Set(key string, value any, expiration time.Duration) (string, error)
@@ -43,9 +60,17 @@ type ICacheStore interface {
 	HGet(key string, field string) (string, error)
 	HAdd(key string, field string, amountInWei *big.Int) (*big.Int, error)
 	HGetAll(key string) (map[string]string, error)
+	HDel(key string, fields ...string) error
+	SetHealthFactor(user string, healthFactor string, liquidatable bool, at time.Time) (HealthFactorChange, error)
+	PruneLiquidatableSince() (int64, error)
 	SSet(key string, members ...string) error
 	SGetAll(key string) ([]string, error)
-	FlushAll() error
+	XAdd(key string, maxLen int64, values map[string]any) (string, error)
+	XRange(key string, start string, stop string) ([]StreamEntry, error)
+	XTrimMinID(key string, minID string) error
+	Publish(channel string, message any) error
+	Subscribe(channels ...string) Subscription
+	FlushAllExcept(patterns ...string) error
 }
 
 func NewCacheStore(config CacheConfig) *CacheStore {
@@ -60,7 +85,11 @@ func NewCacheStore(config CacheConfig) *CacheStore {
 }
 
 func (cs *CacheStore) Get(key string) (string, error) {
-	return cs.Client.Get(key).Result()
+	value, err := cs.Client.Get(key).Result()
+	if err == redis.Nil {
+		return "", ErrCacheMiss
+	}
+	return value, err
 }
 
 func (cs *CacheStore) Set(key string, value any, expiration time.Duration) (string, error) {
@@ -119,7 +148,11 @@ func (cs *CacheStore) HSet(key string, field string, value any) error {
 }
 
 func (cs *CacheStore) HGet(key string, field string) (string, error) {
-	return cs.Client.HGet(key, field).Result()
+	value, err := cs.Client.HGet(key, field).Result()
+	if err == redis.Nil {
+		return "", ErrCacheMiss
+	}
+	return value, err
 }
 
 func (cs *CacheStore) HAdd(
@@ -157,6 +190,10 @@ func (cs *CacheStore) HGetAll(key string) (map[string]string, error) {
 	return cs.Client.HGetAll(key).Result()
 }
 
+func (cs *CacheStore) HDel(key string, fields ...string) error {
+	return cs.Client.HDel(key, fields...).Err()
+}
+
 func (cs *CacheStore) SSet(key string, members ...string) error {
 	return cs.Client.SAdd(key, members).Err()
 }
@@ -165,6 +202,80 @@ func (cs *CacheStore) SGetAll(key string) ([]string, error) {
 	return cs.Client.SMembers(key).Result()
 }
 
-func (cs *CacheStore) FlushAll() error {
-	return cs.Client.FlushAll().Err()
+func (cs *CacheStore) XAdd(key string, maxLen int64, values map[string]any) (string, error) {
+	return cs.Client.XAdd(&redis.XAddArgs{
+		Stream:       key,
+		MaxLenApprox: maxLen,
+		Values:       values,
+	}).Result()
+}
+
+func (cs *CacheStore) XRange(key string, start string, stop string) ([]StreamEntry, error) {
+	messages, err := cs.Client.XRange(key, start, stop).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]StreamEntry, 0, len(messages))
+	for _, message := range messages {
+		values := make(map[string]string, len(message.Values))
+		for field, value := range message.Values {
+			values[field] = fmt.Sprint(value)
+		}
+		entries = append(entries, StreamEntry{ID: message.ID, Values: values})
+	}
+
+	return entries, nil
+}
+
+// XTrimMinID drops the entries of the stream at key with IDs lower than minID. Trimming is approximate,
+// so Redis only removes whole macro nodes and a few older entries may remain.
+func (cs *CacheStore) XTrimMinID(key string, minID string) error {
+	return cs.Client.Do("XTRIM", key, "MINID", "~", minID).Err()
+}
+
+func (cs *CacheStore) Publish(channel string, message any) error {
+	return cs.Client.Publish(channel, message).Err()
+}
+
+func (cs *CacheStore) Subscribe(channels ...string) Subscription {
+	return cs.Client.Subscribe(channels...)
+}
+
+// FlushAllExcept deletes every key that matches none of the glob patterns, so derived state can be
+// rebuilt on boot while history that cannot be rebuilt is kept.
+func (cs *CacheStore) FlushAllExcept(patterns ...string) error {
+	var cursor uint64
+	for {
+		keys, next, err := cs.Client.Scan(cursor, "*", 1000).Result()
+		if err != nil {
+			return err
+		}
+
+		flushed := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if !matchesAny(key, patterns) {
+				flushed = append(flushed, key)
+			}
+		}
+		if len(flushed) > 0 {
+			if err := cs.Client.Del(flushed...).Err(); err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func matchesAny(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
 }
@@ -27,16 +27,6 @@ func (cs *coinStore) CreateBurn(ctx context.Context, burn *model.Burns) error {
 	return cs.DB.WithContext(ctx).Create(burn).Error
 }
 
-func (cs *coinStore) GetLatestBurns(ctx context.Context, userAddress string, limit int) ([]model.Burns, error) {
-	var burns []model.Burns
-	err := cs.DB.WithContext(ctx).
-		Where("user_address = ?", userAddress).
-		Order("event_id DESC").
-		Limit(limit).
-		Find(&burns).Error
-	return burns, err
-}
-
 func (cs *coinStore) GetTotalBurnedGroupingByUser(ctx context.Context, users []string) (map[string]*big.Int, error) {
 	var results []struct {
 		UserAddress string
@@ -65,16 +55,6 @@ func (cs *coinStore) CreateMint(ctx context.Context, mint *model.Mints) error {
 	return cs.DB.WithContext(ctx).Create(mint).Error
 }
 
-func (cs *coinStore) GetLatestMints(ctx context.Context, userAddress string, limit int) ([]model.Mints, error) {
-	var mints []model.Mints
-	err := cs.DB.WithContext(ctx).
-		Where("user_address = ?", userAddress).
-		Order("event_id DESC").
-		Limit(limit).
-		Find(&mints).Error
-	return mints, err
-}
-
 func (cs *coinStore) IterateTotalMintedGroupingByUser(ctx context.Context, limit int, cb func(map[string]*big.Int) error) error {
     if limit <= 0 {
         limit = 500
@@ -33,26 +33,6 @@ func (s *collateralStore) CreateDeposit(ctx context.Context, deposit *model.Depo
 	return result.Error
 }
 
-func (s *collateralStore) GetLatestDeposits(ctx context.Context, userAddress string, limit int) ([]model.Deposit, error) {
-	var deposits []model.Deposit
-	err := s.DB.WithContext(ctx).
-		Where("user_address = ?", userAddress).
-		Order("event_id DESC").
-		Limit(limit).
-		Find(&deposits).Error
-	return deposits, err
-}
-
-func (s *collateralStore) GetLatestRedeems(ctx context.Context, userAddress string, limit int) ([]model.Redeem, error) {
-	var redeems []model.Redeem
-	err := s.DB.WithContext(ctx).
-		Where("user_address = ?", userAddress).
-		Order("event_id DESC").
-		Limit(limit).
-		Find(&redeems).Error
-	return redeems, err
-}
-
 func (s *collateralStore) IterateTotalDepositedGroupingByUser(ctx context.Context, limit int, cb func(map[string]map[string]*big.Int) error) error {
 	if limit <= 0 {
         limit = 500
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const dailyActivityQuery = `
+	SELECT
+		(SELECT COALESCE(SUM(m.amount), 0) FROM mints m JOIN events e ON e.id = m.event_id
+			WHERE e.created_at >= @from AND e.created_at < @to) AS mint_volume,
+		(SELECT COALESCE(SUM(b.amount), 0) FROM burns b JOIN events e ON e.id = b.event_id
+			WHERE e.created_at >= @from AND e.created_at < @to) AS burn_volume,
+		(SELECT COUNT(*) FROM liquidations l JOIN events e ON e.id = l.event_id
+			WHERE e.created_at >= @from AND e.created_at < @to) AS liquidation_count,
+		(SELECT COUNT(*) FROM (SELECT d.user_address FROM deposits d JOIN events e ON e.id = d.event_id
+			GROUP BY d.user_address HAVING MIN(e.created_at) >= @from AND MIN(e.created_at) < @to) n) AS new_users,
+		(SELECT COALESCE(MAX(e.block_number), 0) FROM events e WHERE e.created_at < @to) AS last_block_number`
+
+const dailyCollateralFlowQuery = `
+	SELECT c.collateral_address, SUM(c.deposit_volume) AS deposit_volume, SUM(c.redeem_volume) AS redeem_volume,
+		SUM(c.liquidated_amount) AS liquidated_amount
+	FROM (
+		SELECT d.collateral_address, d.amount AS deposit_volume, 0 AS redeem_volume, 0 AS liquidated_amount, d.event_id FROM deposits d
+		UNION ALL
+		SELECT r.collateral_address, 0, r.amount, 0, r.event_id FROM redeems r
+		UNION ALL
+		SELECT l.collateral_address, 0, 0, l.collateral_amount, l.event_id FROM liquidations l
+	) c JOIN events e ON e.id = c.event_id
+	WHERE e.created_at >= @from AND e.created_at < @to
+	GROUP BY c.collateral_address ORDER BY c.collateral_address`
+
+var dailyStatsStr dailyStatsStore
+
+type dailyStatsStore struct {
+	DB *gorm.DB
+}
+
+func NewDailyStatsStore(db *gorm.DB) *dailyStatsStore {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Initializing daily stats store")
+	dailyStatsStr = dailyStatsStore{DB: db}
+	return &dailyStatsStr
+}
+
+func GetDailyStatsStore() *dailyStatsStore {
+	return &dailyStatsStr
+}
+
+// GetDailyActivity aggregates the indexed tables over [from, to). A user is new on the day of their
+// first deposit.
+func (s *dailyStatsStore) GetDailyActivity(ctx context.Context, from, to time.Time) (model.DailyActivity, error) {
+	bounds := map[string]any{"from": from.Unix(), "to": to.Unix()}
+
+	var activity model.DailyActivity
+	if err := s.DB.WithContext(ctx).Raw(dailyActivityQuery, bounds).Scan(&activity).Error; err != nil {
+		return model.DailyActivity{}, err
+	}
+	if err := s.DB.WithContext(ctx).Raw(dailyCollateralFlowQuery, bounds).Scan(&activity.Collateral).Error; err != nil {
+		return model.DailyActivity{}, err
+	}
+	return activity, nil
+}
+
+// GetPendingRollupStart returns the time of the earliest indexed event that no rollup has seen yet,
+// or nil when every event is covered.
+func (s *dailyStatsStore) GetPendingRollupStart(ctx context.Context) (*time.Time, error) {
+	var createdAt *int64
+	err := s.DB.WithContext(ctx).
+		Raw("SELECT MIN(e.created_at) FROM events e WHERE e.block_number > (SELECT COALESCE(MAX(last_block_number), -1) FROM daily_protocol_stats)").
+		Scan(&createdAt).Error
+	if err != nil || createdAt == nil {
+		return nil, err
+	}
+	start := time.Unix(*createdAt, 0).UTC()
+	return &start, nil
+}
+
+// GetLastRolledUpDay returns the latest day with a rollup, or nil before the first one.
+func (s *dailyStatsStore) GetLastRolledUpDay(ctx context.Context) (*time.Time, error) {
+	var stats model.DailyProtocolStats
+	err := s.DB.WithContext(ctx).Order("day DESC").First(&stats).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	day := stats.Day.UTC()
+	return &day, nil
+}
+
+func (s *dailyStatsStore) GetDailyCollateralStats(ctx context.Context, day time.Time) ([]model.DailyCollateralStats, error) {
+	var stats []model.DailyCollateralStats
+	err := s.DB.WithContext(ctx).Where("day = ?", day).Order("collateral_address").Find(&stats).Error
+	return stats, err
+}
+
+// SaveDailyStats writes a day's rollup, replacing the previous one for that day.
+func (s *dailyStatsStore) SaveDailyStats(ctx context.Context, stats model.DailyProtocolStats, collateral []model.DailyCollateralStats) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&stats).Error; err != nil {
+			return err
+		}
+		if len(collateral) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&collateral).Error
+	})
+}
+
+// GetDailyStats returns the rollups of every day in [from, to], oldest first.
+func (s *dailyStatsStore) GetDailyStats(ctx context.Context, from, to time.Time) ([]model.DailyProtocolStats, []model.DailyCollateralStats, error) {
+	var stats []model.DailyProtocolStats
+	if err := s.DB.WithContext(ctx).Where("day BETWEEN ? AND ?", from, to).Order("day").Find(&stats).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var collateral []model.DailyCollateralStats
+	if err := s.DB.WithContext(ctx).Where("day BETWEEN ? AND ?", from, to).Order("day, collateral_address").Find(&collateral).Error; err != nil {
+		return nil, nil, err
+	}
+	return stats, collateral, nil
+}
@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// historyFeedQuery puts every user-facing row next to the event it was indexed from, so the whole feed
+// is read in one round trip. Rows whose event is missing are kept with a zero position rather than
+// dropped, and get a group key of their own. The row key is unique across the union and breaks ties
+// between rows at the same position. Postgres pushes the outer filters down into each branch of the
+// union.
+const historyFeedQuery = `
+	SELECT d.id, 'deposit' AS type, d.amount, d.amount AS collateral_amount, d.collateral_address AS asset,
+		d.user_address, d.event_id, COALESCE(e.tx_hash, '') AS tx_hash, COALESCE(e.tx_hash, 'deposit:' || d.id) AS group_key,
+		COALESCE(e.block_number, 0) AS block_number, COALESCE(e.log_index, 0) AS log_index, COALESCE(e.created_at, 0) AS created_at, 'deposit:' || d.id AS row_key
+	FROM deposits d LEFT JOIN events e ON e.id = d.event_id
+	UNION ALL
+	SELECT r.id, 'redeem', r.amount, r.amount, r.collateral_address,
+		r.user_address, r.event_id, COALESCE(e.tx_hash, ''), COALESCE(e.tx_hash, 'redeem:' || r.id),
+		COALESCE(e.block_number, 0), COALESCE(e.log_index, 0), COALESCE(e.created_at, 0), 'redeem:' || r.id
+	FROM redeems r LEFT JOIN events e ON e.id = r.event_id
+	UNION ALL
+	SELECT m.id, 'mint', m.amount, 0, '',
+		m.user_address, m.event_id, COALESCE(e.tx_hash, ''), COALESCE(e.tx_hash, 'mint:' || m.id),
+		COALESCE(e.block_number, 0), COALESCE(e.log_index, 0), COALESCE(e.created_at, 0), 'mint:' || m.id
+	FROM mints m LEFT JOIN events e ON e.id = m.event_id
+	UNION ALL
+	SELECT b.id, 'burn', b.amount, 0, '',
+		b.user_address, b.event_id, COALESCE(e.tx_hash, ''), COALESCE(e.tx_hash, 'burn:' || b.id),
+		COALESCE(e.block_number, 0), COALESCE(e.log_index, 0), COALESCE(e.created_at, 0), 'burn:' || b.id
+	FROM burns b LEFT JOIN events e ON e.id = b.event_id
+	UNION ALL
+	SELECT l.id, 'liquidation', l.debt_covered, l.collateral_amount, l.collateral_address,
+		l.liquidated_user_address, l.event_id, COALESCE(e.tx_hash, ''), COALESCE(e.tx_hash, 'liquidation:' || l.id),
+		COALESCE(e.block_number, 0), COALESCE(e.log_index, 0), COALESCE(e.created_at, 0), 'liquidation:' || l.id
+	FROM liquidations l LEFT JOIN events e ON e.id = l.event_id`
+
+const historyEntryColumns = "h.id, h.type, h.amount, h.collateral_amount, h.asset, h.tx_hash, h.group_key, h.block_number, h.log_index, h.created_at, h.row_key"
+
+var historyStr historyStore
+
+type historyStore struct {
+	DB *gorm.DB
+}
+
+func NewHistoryStore(db *gorm.DB) *historyStore {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Initializing history store")
+	historyStr = historyStore{DB: db}
+	return &historyStr
+}
+
+func GetHistoryStore() *historyStore {
+	return &historyStr
+}
+
+// GetTransactions returns up to limit transactions matching the filter, newest first, starting
+// strictly before the given cursor when one is set. The total number of matching transactions across
+// all pages is computed in the same query; it is zero when the page is empty.
+func (s *historyStore) GetTransactions(ctx context.Context, userAddress string, filter model.HistoryFilter, before *model.HistoryCursor, limit int) ([]model.HistoryEntry, int64, error) {
+	logger := utils.GetLogger()
+	logger.Debug().Str("user", userAddress).Int("limit", limit).Msg("Fetching transaction history page")
+
+	conditions, args := historyConditions(userAddress, filter)
+
+	page := "TRUE"
+	if before != nil {
+		page = "(h.block_number, h.log_index, h.row_key) < (?, ?, ?)"
+		args = append(args, before.BlockNumber, before.LogIndex, before.Key)
+	}
+	args = append(args, limit)
+
+	var rows []struct {
+		model.HistoryEntry
+		TotalCount int64
+	}
+	err := s.DB.WithContext(ctx).
+		Raw("SELECT "+historyEntryColumns+", h.total_count FROM ("+
+			"SELECT f.*, COUNT(*) OVER () AS total_count FROM ("+historyFeedQuery+") f WHERE "+strings.Join(conditions, " AND ")+
+			") h WHERE "+page+" ORDER BY h.block_number DESC, h.log_index DESC, h.row_key DESC LIMIT ?", args...).
+		Scan(&rows).Error
+	if err != nil {
+		logger.Error().Err(err).Str("user", userAddress).Msg("Failed to fetch transaction history page")
+		return nil, 0, err
+	}
+
+	entries := make([]model.HistoryEntry, 0, len(rows))
+	var total int64
+	for _, row := range rows {
+		entries = append(entries, row.HistoryEntry)
+		total = row.TotalCount
+	}
+	return entries, total, nil
+}
+
+// GetLatestTransactions returns the latest perType transactions of each type, newest first.
+func (s *historyStore) GetLatestTransactions(ctx context.Context, userAddress string, perType int) ([]model.HistoryEntry, error) {
+	logger := utils.GetLogger()
+	logger.Debug().Str("user", userAddress).Int("per_type", perType).Msg("Fetching latest transactions")
+
+	var entries []model.HistoryEntry
+	err := s.DB.WithContext(ctx).
+		Raw("SELECT "+historyEntryColumns+" FROM ("+
+			"SELECT f.*, ROW_NUMBER() OVER (PARTITION BY f.type ORDER BY f.event_id DESC) AS type_rank FROM ("+historyFeedQuery+") f WHERE f.user_address = ?"+
+			") h WHERE h.type_rank <= ? ORDER BY h.block_number DESC, h.log_index DESC, h.row_key DESC", userAddress, perType).
+		Scan(&entries).Error
+	if err != nil {
+		logger.Error().Err(err).Str("user", userAddress).Msg("Failed to fetch latest transactions")
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetOperations returns up to limit operations, the user's legs grouped by transaction, newest first.
+// Operations are positioned by their last leg, and the total across all pages comes with the page.
+func (s *historyStore) GetOperations(ctx context.Context, userAddress string, filter model.HistoryFilter, before *model.HistoryCursor, limit int) ([]model.HistoryOperationKey, int64, error) {
+	logger := utils.GetLogger()
+	logger.Debug().Str("user", userAddress).Int("limit", limit).Msg("Fetching history operations page")
+
+	conditions, args := historyConditions(userAddress, filter)
+
+	page := "TRUE"
+	if before != nil {
+		page = "(o.block_number, o.log_index, o.group_key) < (?, ?, ?)"
+		args = append(args, before.BlockNumber, before.LogIndex, before.Key)
+	}
+	args = append(args, limit)
+
+	var keys []model.HistoryOperationKey
+	err := s.DB.WithContext(ctx).
+		Raw("SELECT o.group_key, o.block_number, o.log_index, o.total_count FROM ("+
+			"SELECT g.*, COUNT(*) OVER () AS total_count FROM ("+
+			"SELECT f.group_key, MAX(f.block_number) AS block_number, MAX(f.log_index) AS log_index FROM ("+historyFeedQuery+") f WHERE "+strings.Join(conditions, " AND ")+" GROUP BY f.group_key"+
+			") g) o WHERE "+page+" ORDER BY o.block_number DESC, o.log_index DESC, o.group_key DESC LIMIT ?", args...).
+		Scan(&keys).Error
+	if err != nil {
+		logger.Error().Err(err).Str("user", userAddress).Msg("Failed to fetch history operations page")
+		return nil, 0, err
+	}
+
+	var total int64
+	if len(keys) > 0 {
+		total = keys[0].TotalCount
+	}
+	return keys, total, nil
+}
+
+// GetOperationLegs returns every leg of the given operations in the order they were emitted.
+func (s *historyStore) GetOperationLegs(ctx context.Context, userAddress string, groupKeys []string) ([]model.HistoryEntry, error) {
+	var entries []model.HistoryEntry
+	err := s.DB.WithContext(ctx).
+		Raw("SELECT "+historyEntryColumns+" FROM ("+historyFeedQuery+") h WHERE h.user_address = ? AND h.group_key IN ? ORDER BY h.block_number ASC, h.log_index ASC, h.row_key ASC", userAddress, groupKeys).
+		Scan(&entries).Error
+	return entries, err
+}
+
+// GetPositionBefore returns the user's legs strictly before the cursor, summed by type and asset,
+// so replaying them rebuilds the position at that point.
+func (s *historyStore) GetPositionBefore(ctx context.Context, userAddress string, cursor model.HistoryCursor) ([]model.HistoryEntry, error) {
+	var entries []model.HistoryEntry
+	err := s.DB.WithContext(ctx).
+		Raw("SELECT h.type, h.asset, SUM(h.amount) AS amount, SUM(h.collateral_amount) AS collateral_amount FROM ("+historyFeedQuery+") h "+
+			"WHERE h.user_address = ? AND (h.block_number, h.log_index, h.row_key) < (?, ?, ?) GROUP BY h.type, h.asset", userAddress, cursor.BlockNumber, cursor.LogIndex, cursor.Key).
+		Scan(&entries).Error
+	return entries, err
+}
+
+// CountTransactions returns how many transactions match the filter across all pages.
+func (s *historyStore) CountTransactions(ctx context.Context, userAddress string, filter model.HistoryFilter) (int64, error) {
+	conditions, args := historyConditions(userAddress, filter)
+
+	var count int64
+	err := s.DB.WithContext(ctx).
+		Raw("SELECT COUNT(*) FROM ("+historyFeedQuery+") f WHERE "+strings.Join(conditions, " AND "), args...).
+		Scan(&count).Error
+	return count, err
+}
+
+// CountOperations returns how many transactions the user's matching legs span across all pages.
+func (s *historyStore) CountOperations(ctx context.Context, userAddress string, filter model.HistoryFilter) (int64, error) {
+	conditions, args := historyConditions(userAddress, filter)
+
+	var count int64
+	err := s.DB.WithContext(ctx).
+		Raw("SELECT COUNT(DISTINCT f.group_key) FROM ("+historyFeedQuery+") f WHERE "+strings.Join(conditions, " AND "), args...).
+		Scan(&count).Error
+	return count, err
+}
+
+// IterateStatement streams the user's transactions matching the filter, oldest first, each with the
+// latest price indexed for its collateral at or before its block. tokens maps token names to the
+// collateral addresses they are priced under.
+func (s *historyStore) IterateStatement(ctx context.Context, userAddress string, filter model.HistoryFilter, tokens map[string]string, cb func(model.StatementEntry) error) error {
+	conditions, args := historyConditions(userAddress, filter)
+
+	price := "NULL AS price_in_usd"
+	join := ""
+	if len(tokens) > 0 {
+		values := make([]string, 0, len(tokens))
+		for name, address := range tokens {
+			values = append(values, "(?, ?)")
+			args = append(args, strings.ToLower(address), name)
+		}
+		price = "p.price_in_usd"
+		join = " LEFT JOIN (VALUES " + strings.Join(values, ", ") + ") AS t(address, name) ON t.address = LOWER(h.asset)" +
+			" LEFT JOIN LATERAL (SELECT pr.price_in_usd::text AS price_in_usd FROM prices pr WHERE pr.token_name = t.name AND pr.block_number <= h.block_number" +
+			" ORDER BY pr.block_number DESC LIMIT 1) p ON TRUE"
+	}
+
+	rows, err := s.DB.WithContext(ctx).
+		Raw("SELECT "+historyEntryColumns+", "+price+" FROM ("+
+			"SELECT f.* FROM ("+historyFeedQuery+") f WHERE "+strings.Join(conditions, " AND ")+
+			") h"+join+" ORDER BY h.block_number ASC, h.log_index ASC, h.row_key ASC", args...).
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry model.StatementEntry
+		if err := s.DB.ScanRows(rows, &entry); err != nil {
+			return err
+		}
+		if err := cb(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func historyConditions(userAddress string, filter model.HistoryFilter) ([]string, []any) {
+	conditions := []string{"f.user_address = ?"}
+	args := []any{userAddress}
+
+	if len(filter.Types) > 0 {
+		types := make([]string, len(filter.Types))
+		for i, t := range filter.Types {
+			types[i] = string(t)
+		}
+		conditions = append(conditions, "f.type IN ?")
+		args = append(args, types)
+	}
+	if filter.Token != "" {
+		conditions = append(conditions, "LOWER(f.asset) = LOWER(?)")
+		args = append(args, filter.Token)
+	}
+	if filter.FromBlock != nil {
+		conditions = append(conditions, "f.block_number >= ?")
+		args = append(args, *filter.FromBlock)
+	}
+	if filter.ToBlock != nil {
+		conditions = append(conditions, "f.block_number <= ?")
+		args = append(args, *filter.ToBlock)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "f.created_at >= ?")
+		args = append(args, filter.From.Unix())
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "f.created_at <= ?")
+		args = append(args, filter.To.Unix())
+	}
+
+	return conditions, args
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	healthFactorKey      = "user:health_factor"
+	liquidatableKey      = "liquidatable"
+	liquidatableSinceKey = "liquidatable:since"
+)
+
+// setHealthFactorScript stores the health factor at ARGV[2] for the user at ARGV[1] and moves the user
+// in or out of the liquidatable set according to ARGV[3]. Membership is compared and changed in one
+// step, so concurrent updates of the same user report a transition exactly once.
+var setHealthFactorScript = redis.NewScript(`
+	local previous = redis.call("HGET", KEYS[1], ARGV[1]) or ""
+	redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+
+	local member = redis.call("HEXISTS", KEYS[2], ARGV[1]) == 1
+	if ARGV[3] == "1" then
+		redis.call("HSET", KEYS[2], ARGV[1], ARGV[2])
+		if member then
+			return {previous, "", ""}
+		end
+		redis.call("HSET", KEYS[3], ARGV[1], ARGV[4])
+		return {previous, "enter", ""}
+	end
+
+	if not member then
+		return {previous, "", ""}
+	end
+	local since = redis.call("HGET", KEYS[3], ARGV[1]) or ""
+	redis.call("HDEL", KEYS[2], ARGV[1])
+	redis.call("HDEL", KEYS[3], ARGV[1])
+	return {previous, "exit", since}
+`)
+
+// pruneLiquidatableSinceScript drops the since timestamps of users that are no longer liquidatable.
+var pruneLiquidatableSinceScript = redis.NewScript(`
+	local pruned = 0
+	for _, user in ipairs(redis.call("HKEYS", KEYS[2])) do
+		if redis.call("HEXISTS", KEYS[1], user) == 0 then
+			redis.call("HDEL", KEYS[2], user)
+			pruned = pruned + 1
+		end
+	end
+	return pruned
+`)
+
+type LiquidatableTransition string
+
+const (
+	LiquidatableEntered LiquidatableTransition = "enter"
+	LiquidatableExited  LiquidatableTransition = "exit"
+)
+
+// HealthFactorChange is the outcome of SetHealthFactor. Transition is empty when the user stayed on
+// the same side of the liquidatable set, and Since is only set on exits.
+type HealthFactorChange struct {
+	PreviousHealthFactor string
+	Transition           LiquidatableTransition
+	Since                string
+}
+
+// SetHealthFactor stores the user's health factor and adds the user to, or removes them from, the
+// liquidatable set, recording when they entered it.
+func (cs *CacheStore) SetHealthFactor(user string, healthFactor string, liquidatable bool, at time.Time) (HealthFactorChange, error) {
+	flag := "0"
+	if liquidatable {
+		flag = "1"
+	}
+
+	res, err := setHealthFactorScript.Run(cs.Client, []string{healthFactorKey, liquidatableKey, liquidatableSinceKey}, user, healthFactor, flag, at.Unix()).Result()
+	if err != nil {
+		return HealthFactorChange{}, err
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 3 {
+		return HealthFactorChange{}, errors.New("unexpected redis return type")
+	}
+	previous, previousOk := values[0].(string)
+	transition, transitionOk := values[1].(string)
+	since, sinceOk := values[2].(string)
+	if !previousOk || !transitionOk || !sinceOk {
+		return HealthFactorChange{}, errors.New("unexpected redis return type")
+	}
+
+	return HealthFactorChange{
+		PreviousHealthFactor: previous,
+		Transition:           LiquidatableTransition(transition),
+		Since:                since,
+	}, nil
+}
+
+// PruneLiquidatableSince removes since timestamps left behind by users no longer in the liquidatable
+// set and returns how many were removed.
+func (cs *CacheStore) PruneLiquidatableSince() (int64, error) {
+	return pruneLiquidatableSinceScript.Run(cs.Client, []string{liquidatableKey, liquidatableSinceKey}).Int64()
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
@@ -37,22 +38,59 @@ func (ls *liquidationStore) CreateLiquidation(ctx context.Context, liquidation *
 	return nil
 }
 
-func (ls *liquidationStore) GetLatestLiquidations(ctx context.Context, userAddress string, limit int) ([]model.Liquidations, error) {
-	logger := utils.GetLogger()
-	logger.Debug().Str("user", userAddress).Int("limit", limit).Msg("Fetching latest liquidations")
+// GetLiquidationsByLiquidator returns the latest liquidations performed by the liquidator, newest first.
+func (ls *liquidationStore) GetLiquidationsByLiquidator(ctx context.Context, liquidatorAddress string, limit int) ([]model.LiquidationRecord, error) {
+	var records []model.LiquidationRecord
+	err := ls.DB.WithContext(ctx).
+		Raw("SELECT l.*, COALESCE(e.tx_hash, '') AS tx_hash, COALESCE(e.block_number, 0) AS block_number, COALESCE(e.created_at, 0) AS created_at "+
+			"FROM liquidations l LEFT JOIN events e ON e.id = l.event_id WHERE l.liquidator_address = ? "+
+			"ORDER BY e.block_number DESC NULLS LAST, e.log_index DESC LIMIT ?", liquidatorAddress, limit).
+		Scan(&records).Error
+	return records, err
+}
 
-	var liquidations []model.Liquidations
+// GetLiquidatorTotals returns the number of liquidations and the debt covered by the liquidator.
+func (ls *liquidationStore) GetLiquidatorTotals(ctx context.Context, liquidatorAddress string) (model.LiquidatorTotals, error) {
+	totals := model.LiquidatorTotals{LiquidatorAddress: liquidatorAddress}
 	err := ls.DB.WithContext(ctx).
-		Where("liquidated_user_address = ?", userAddress).
-		Order("event_id DESC").
-		Limit(limit).
-		Find(&liquidations).Error
+		Raw("SELECT COUNT(*) AS liquidation_count, COALESCE(SUM(debt_covered), 0) AS debt_covered FROM liquidations WHERE liquidator_address = ?", liquidatorAddress).
+		Scan(&totals).Error
+	return totals, err
+}
+
+// GetLiquidatorCollateral returns the collateral seized by the liquidator per asset, bonus included.
+func (ls *liquidationStore) GetLiquidatorCollateral(ctx context.Context, liquidatorAddress string) ([]model.LiquidatorCollateralTotal, error) {
+	var totals []model.LiquidatorCollateralTotal
+	err := ls.DB.WithContext(ctx).
+		Raw("SELECT collateral_address, SUM(collateral_amount) AS collateral_amount FROM liquidations WHERE liquidator_address = ? "+
+			"GROUP BY collateral_address ORDER BY collateral_address", liquidatorAddress).
+		Scan(&totals).Error
+	return totals, err
+}
 
+// GetLiquidatorLeaderboard ranks liquidators by debt covered since the given time, or over all
+// time when since is nil.
+func (ls *liquidationStore) GetLiquidatorLeaderboard(ctx context.Context, since *time.Time, limit int) ([]model.LiquidatorTotals, error) {
+	logger := utils.GetLogger()
+	logger.Debug().Int("limit", limit).Msg("Fetching liquidator leaderboard")
+
+	window := "TRUE"
+	args := []any{}
+	if since != nil {
+		window = "e.created_at >= ?"
+		args = append(args, since.Unix())
+	}
+	args = append(args, limit)
+
+	var totals []model.LiquidatorTotals
+	err := ls.DB.WithContext(ctx).
+		Raw("SELECT l.liquidator_address, COUNT(*) AS liquidation_count, SUM(l.debt_covered) AS debt_covered "+
+			"FROM liquidations l LEFT JOIN events e ON e.id = l.event_id WHERE "+window+" "+
+			"GROUP BY l.liquidator_address ORDER BY SUM(l.debt_covered) DESC, COUNT(*) DESC, l.liquidator_address LIMIT ?", args...).
+		Scan(&totals).Error
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to fetch liquidations")
+		logger.Error().Err(err).Msg("Failed to fetch liquidator leaderboard")
 		return nil, err
 	}
-
-	logger.Info().Int("count", len(liquidations)).Msg("Liquidations fetched successfully")
-	return liquidations, nil
+	return totals, nil
 }
@@ -1,6 +1,9 @@
 package storage
 
 import (
+	"context"
+	"strings"
+
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -48,3 +51,41 @@ func (s *priceStore) SavePriceInBlock(tokenName string, blockNumber uint64, pric
 	result := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&price)
 	return result.Error
 }
+
+// GetPricesAtBlocks returns the latest price of every token as of each block, keyed by block and then
+// by token name, in a single query.
+func (s *priceStore) GetPricesAtBlocks(ctx context.Context, blockNumbers []uint64) (map[uint64]map[string]string, error) {
+	prices := make(map[uint64]map[string]string, len(blockNumbers))
+	if len(blockNumbers) == 0 {
+		return prices, nil
+	}
+
+	values := make([]string, len(blockNumbers))
+	args := make([]any, len(blockNumbers))
+	for i, blockNumber := range blockNumbers {
+		values[i] = "(?::bigint)"
+		args[i] = blockNumber
+	}
+
+	var rows []struct {
+		AtBlock    uint64
+		TokenName  string
+		PriceInUSD string
+	}
+	err := s.DB.WithContext(ctx).
+		Raw("SELECT b.block_number AS at_block, p.token_name, p.price_in_usd FROM (VALUES "+strings.Join(values, ", ")+") AS b(block_number) "+
+			"CROSS JOIN LATERAL (SELECT DISTINCT ON (token_name) token_name, price_in_usd FROM prices "+
+			"WHERE block_number <= b.block_number ORDER BY token_name, block_number DESC) p", args...).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if prices[row.AtBlock] == nil {
+			prices[row.AtBlock] = map[string]string{}
+		}
+		prices[row.AtBlock][row.TokenName] = row.PriceInUSD
+	}
+	return prices, nil
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// fixedWindowScript counts one request in the window at KEYS[1], starting a window of ARGV[1]
+// milliseconds on its first request. A key left without an expiry is given one rather than counting
+// forever.
+var fixedWindowScript = redis.NewScript(`
+	local count = redis.call("INCR", KEYS[1])
+	if count == 1 then
+		redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	end
+	local ttl = redis.call("PTTL", KEYS[1])
+	if ttl < 0 then
+		redis.call("PEXPIRE", KEYS[1], ARGV[1])
+		ttl = tonumber(ARGV[1])
+	end
+	return {count, ttl}
+`)
+
+// IncrementWindow counts a request in the fixed window stored at key and returns the requests counted
+// so far in it and the time left until it resets. Every replica shares the count.
+func (cs *CacheStore) IncrementWindow(key string, window time.Duration) (int64, time.Duration, error) {
+	res, err := fixedWindowScript.Run(cs.Client, []string{key}, window.Milliseconds()).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 2 {
+		return 0, 0, errors.New("unexpected redis return type")
+	}
+	count, countOk := values[0].(int64)
+	ttl, ttlOk := values[1].(int64)
+	if !countOk || !ttlOk {
+		return 0, 0, errors.New("unexpected redis return type")
+	}
+
+	return count, time.Duration(ttl) * time.Millisecond, nil
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// DurationFromEnv reads key as a time.Duration, falling back when it is unset, malformed or not positive.
+func DurationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		logger.Warn().Str("key", key).Str("value", value).Str("default", fallback.String()).Msg("Invalid duration, using default")
+		return fallback
+	}
+	return duration
+}
+
+// IntFromEnv reads key as an int, falling back when it is unset, malformed or not positive.
+func IntFromEnv(key string, fallback int) int {
+	return int(Int64FromEnv(key, int64(fallback)))
+}
+
+// Int64FromEnv reads key as an int64, falling back when it is unset, malformed or not positive.
+func Int64FromEnv(key string, fallback int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, strconv.IntSize)
+	if err != nil || parsed <= 0 {
+		logger.Warn().Str("key", key).Str("value", value).Int64("default", fallback).Msg("Invalid number, using default")
+		return fallback
+	}
+	return parsed
+}
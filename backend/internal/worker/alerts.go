@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/service"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+const alertsResumeInterval = time.Minute
+
+type AlertProcessor interface {
+	EvaluateHealthFactorUpdate(ctx context.Context, update model.HealthFactorUpdate) ([]service.AlertDispatch, error)
+	ResumeStaleDeliveries(ctx context.Context) ([]service.AlertDispatch, error)
+	DeliverAlert(ctx context.Context, dispatch service.AlertDispatch) error
+}
+
+// RunAlertsWorker listens for recomputed health factors and delivers the webhook alerts they trigger.
+// Deliveries run in their own goroutines so a slow subscriber never delays the evaluation of other updates.
+// Every pending delivery is stored first, and deliveries abandoned by a stopped process are resumed
+// on boot and then every alertsResumeInterval, so alerts are delivered at least once.
+func RunAlertsWorker(ctx context.Context, cacheStore storage.ICacheStore, alerts AlertProcessor) {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Starting alerts worker")
+
+	subscription := cacheStore.Subscribe(constants.HEALTH_FACTORS_CHANNEL)
+
+	go func() {
+		<-ctx.Done()
+		subscription.Close()
+	}()
+
+	go func() {
+		ticker := time.NewTicker(alertsResumeInterval)
+		defer ticker.Stop()
+
+		for {
+			dispatches, err := alerts.ResumeStaleDeliveries(ctx)
+			if err != nil {
+				logger.Error().Err(err).Msg("Failed to resume stale alert deliveries")
+			}
+			for _, dispatch := range dispatches {
+				go alerts.DeliverAlert(ctx, dispatch)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	go func() {
+		logger.Debug().Msg("Alerts worker goroutine started")
+
+		for message := range subscription.Channel() {
+			var update model.HealthFactorUpdate
+			if err := json.Unmarshal([]byte(message.Payload), &update); err != nil {
+				logger.Warn().Err(err).Msg("Failed to decode health factor update")
+				continue
+			}
+
+			dispatches, err := alerts.EvaluateHealthFactorUpdate(ctx, update)
+			if err != nil {
+				logger.Error().Err(err).Str("user", update.Address).Msg("Failed to evaluate health factor alerts")
+				continue
+			}
+
+			for _, dispatch := range dispatches {
+				go alerts.DeliverAlert(ctx, dispatch)
+			}
+		}
+
+		logger.Warn().Msg("Alerts worker subscription closed")
+	}()
+	logger.Info().Msg("Alerts worker started successfully")
+}
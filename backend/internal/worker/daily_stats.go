@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model/constants"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
+)
+
+const defaultDailyStatsRollupInterval = time.Minute
+
+type DailyStatsRollup interface {
+	RollupDailyStats(ctx context.Context, until time.Time) error
+}
+
+// RunDailyStatsWorker backfills the daily stats on startup, then rolls them up again at most once per
+// DAILY_STATS_ROLLUP_INTERVAL after newly indexed events are published.
+func RunDailyStatsWorker(cacheStore storage.ICacheStore, rollup DailyStatsRollup) {
+	logger := utils.GetLogger()
+	logger.Info().Msg("Starting daily stats worker")
+
+	interval := utils.DurationFromEnv("DAILY_STATS_ROLLUP_INTERVAL", defaultDailyStatsRollupInterval)
+	logger.Info().Str("interval", interval.String()).Msg("Daily stats worker configured")
+
+	subscription := cacheStore.Subscribe(constants.PROTOCOL_EVENTS_CHANNEL)
+
+	go func() {
+		logger.Debug().Msg("Daily stats worker goroutine started")
+		ctx := context.Background()
+
+		run := func() {
+			if err := rollup.RollupDailyStats(ctx, time.Now()); err != nil {
+				logger.Error().Err(err).Msg("Failed to roll up daily stats")
+			}
+		}
+		run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		pending := false
+		messages := subscription.Channel()
+		for {
+			select {
+			case _, ok := <-messages:
+				if !ok {
+					logger.Warn().Msg("Daily stats worker subscription closed")
+					return
+				}
+				pending = true
+
+			case <-ticker.C:
+				if pending {
+					pending = false
+					run()
+				}
+			}
+		}
+	}()
+	logger.Info().Msg("Daily stats worker started successfully")
+}
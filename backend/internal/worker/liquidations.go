@@ -1,47 +1,131 @@
 package worker
 
 import (
+	"context"
+	"math"
+	"math/big"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/domain"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/external"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/service"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
 )
 
-func RunLiquidationsWorker(cacheStore storage.ICacheStore, priceFeed external.IPriceFeedAPI) {
+const (
+	defaultLiquidationsScanInterval      = 5 * time.Minute
+	defaultLiquidationsPricePollInterval = 15 * time.Second
+	defaultLiquidationsPriceThreshold    = "1"
+)
+
+// RunLiquidationsWorker runs the fallback scan and the price poll until ctx is cancelled.
+func RunLiquidationsWorker(ctx context.Context, cacheStore storage.ICacheStore, priceFeed external.IPriceFeedAPI) {
 	logger := utils.GetLogger()
 	logger.Info().Msg("Starting liquidations worker")
 
-	tickerScanInterval := os.Getenv("LIQUIDATIONS_SCAN_INTERVAL")
-	if tickerScanInterval == "" {
-		tickerScanInterval = "1h"
-	}
+	scanInterval := utils.DurationFromEnv("LIQUIDATIONS_SCAN_INTERVAL", defaultLiquidationsScanInterval)
+	pollInterval := utils.DurationFromEnv("LIQUIDATIONS_PRICE_POLL_INTERVAL", defaultLiquidationsPricePollInterval)
+	threshold := priceThresholdFromEnv()
 
-	duration, err := time.ParseDuration(tickerScanInterval)
-	if err != nil {
-		logger.Warn().Err(err).Str("interval", tickerScanInterval).Msg("Invalid LIQUIDATIONS_SCAN_INTERVAL, defaulting to 1h")
-		duration = time.Hour
-	}
+	logger.Info().
+		Str("scan_interval", scanInterval.String()).
+		Str("price_poll_interval", pollInterval.String()).
+		Str("price_threshold_bps", threshold.String()).
+		Msg("Liquidations worker configured")
 
-	logger.Info().Str("interval", duration.String()).Msg("Liquidations worker configured")
-
-	ticker := time.NewTicker(duration)
+	scanTicker := time.NewTicker(scanInterval)
+	pollTicker := time.NewTicker(pollInterval)
 	go func() {
+		defer scanTicker.Stop()
+		defer pollTicker.Stop()
 		logger.Debug().Msg("Liquidations worker goroutine started")
+
+		referencePrices, err := service.GetCollateralPrices(priceFeed)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to fetch initial reference prices")
+			referencePrices = map[string]string{}
+		}
+		service.CacheCollateralPrices(cacheStore, referencePrices)
+
 		for {
 			select {
-			case <-ticker.C:
-				logger.Info().Msg("Liquidations scan triggered")
+			case <-ctx.Done():
+				logger.Info().Msg("Liquidations worker stopped")
+				return
+
+			case <-scanTicker.C:
+				logger.Info().Msg("Liquidations fallback scan triggered")
 				err := service.CalculateLiquidations(priceFeed, cacheStore)
 				if err != nil {
 					logger.Error().Err(err).Msg("Failed to calculate liquidations")
-				} else {
-					logger.Info().Msg("Liquidations calculation completed successfully")
+					continue
+				}
+				logger.Info().Msg("Liquidations calculation completed successfully")
+				service.PublishDashboardUpdate(cacheStore, 0)
+
+				if prices, err := service.GetCollateralPrices(priceFeed); err == nil {
+					referencePrices = prices
+					service.CacheCollateralPrices(cacheStore, prices)
+				}
+
+			case <-pollTicker.C:
+				prices, err := service.GetCollateralPrices(priceFeed)
+				if err != nil {
+					logger.Warn().Err(err).Msg("Failed to poll collateral prices")
+					continue
+				}
+				service.CacheCollateralPrices(cacheStore, prices)
+
+				for name, price := range prices {
+					reference, ok := referencePrices[name]
+					if !ok {
+						referencePrices[name] = price
+						continue
+					}
+
+					moved, err := domain.PriceMovedBeyondThreshold(reference, price, threshold)
+					if err != nil {
+						logger.Warn().Err(err).Str("token", name).Msg("Failed to compare collateral prices")
+						referencePrices[name] = price
+						continue
+					}
+					if !moved {
+						continue
+					}
+
+					logger.Info().Str("token", name).Str("reference_price", reference).Str("price", price).Msg("Price moved beyond threshold, re-evaluating holders")
+					if err := service.ReevaluateTokenHolders(cacheStore, name, prices); err != nil {
+						logger.Error().Err(err).Str("token", name).Msg("Failed to re-evaluate token holders")
+						continue
+					}
+					referencePrices[name] = price
+					service.PublishDashboardUpdate(cacheStore, 0)
 				}
 			}
 		}
 	}()
 	logger.Info().Msg("Liquidations worker started successfully")
 }
+
+// priceThresholdFromEnv reads LIQUIDATIONS_PRICE_CHANGE_THRESHOLD as a percentage and returns it in basis
+// points, rounded to the nearest one. Thresholds below one basis point would treat every poll as a move.
+func priceThresholdFromEnv() *big.Int {
+	logger := utils.GetLogger()
+
+	value := os.Getenv("LIQUIDATIONS_PRICE_CHANGE_THRESHOLD")
+	if value == "" {
+		value = defaultLiquidationsPriceThreshold
+	}
+
+	percent, err := strconv.ParseFloat(value, 64)
+	basisPoints := math.Round(percent * 100)
+	if err != nil || math.IsNaN(basisPoints) || math.IsInf(basisPoints, 0) || basisPoints < 1 {
+		logger.Warn().Err(err).Str("threshold", value).Msg("Invalid LIQUIDATIONS_PRICE_CHANGE_THRESHOLD, defaulting to 1%")
+		basisPoints = 100
+	}
+
+	return big.NewInt(int64(basisPoints))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/http/external"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/model"
+	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/service"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/service/processors"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/storage"
 	"github.com/Gabriel-Schiestl/AnchorUSD/backend/internal/utils"
@@ -52,6 +53,10 @@ func (mp *metricsProcessor) process(cacheStore storage.ICacheStore, priceFeed ex
 	for metric := range metricsChan {
 		logger.Debug().Str("asset", string(metric.Asset)).Str("user", metric.UserAddress.Hex()).Msg("Processing metric from channel")
 
+		if err := cacheStore.HSet("sync", "last_block", metric.BlockNumber); err != nil {
+			logger.Warn().Err(err).Uint64("block", metric.BlockNumber).Msg("Failed to store last processed block")
+		}
+
 		switch metric.Asset {
 		case model.CollateralAsset:
 			logger.Debug().Str("user", metric.UserAddress.Hex()).Msg("Processing collateral metric")
@@ -65,6 +70,10 @@ func (mp *metricsProcessor) process(cacheStore storage.ICacheStore, priceFeed ex
 
 		default:
 			logger.Warn().Str("asset", string(metric.Asset)).Str("user", metric.UserAddress.Hex()).Msg("Unknown asset type in metric")
+			continue
 		}
+
+		service.PublishDashboardUpdate(cacheStore, metric.BlockNumber)
+		service.PublishProtocolEvent(cacheStore, metric)
 	}
 }
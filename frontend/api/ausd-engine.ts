@@ -45,7 +45,7 @@ interface CalculateRedeemRequest {
 
 export const ausdEngineApi = {
   getUserData: async (address: string): Promise<AUSDEngineData> => {
-    return get<AUSDEngineData>(`/api/user/${address}`);
+    return get<AUSDEngineData>(`/api/v1/user/${address}`);
   },
 
   calculateHealthFactorAfterMint: async (
@@ -53,7 +53,7 @@ export const ausdEngineApi = {
     mintAmount: string,
   ): Promise<HealthFactorProjection> => {
     return post<HealthFactorProjection, CalculateMintRequest>(
-      `/api/ausd-engine/calculate-mint`,
+      `/api/v1/ausd-engine/calculate-mint`,
       { address, mintAmount },
     );
   },
@@ -63,7 +63,7 @@ export const ausdEngineApi = {
     burnAmount: string,
   ): Promise<HealthFactorProjection> => {
     return post<HealthFactorProjection, CalculateBurnRequest>(
-      `/api/ausd-engine/calculate-burn`,
+      `/api/v1/ausd-engine/calculate-burn`,
       { address, burnAmount },
     );
   },
@@ -74,7 +74,7 @@ export const ausdEngineApi = {
     depositAmount: string,
   ): Promise<HealthFactorProjection> => {
     return post<HealthFactorProjection, CalculateDepositRequest>(
-      `/api/ausd-engine/calculate-deposit`,
+      `/api/v1/ausd-engine/calculate-deposit`,
       { address, tokenAddress, depositAmount },
     );
   },
@@ -85,7 +85,7 @@ export const ausdEngineApi = {
     redeemAmount: string,
   ): Promise<HealthFactorProjection> => {
     return post<HealthFactorProjection, CalculateRedeemRequest>(
-      `/api/ausd-engine/calculate-redeem`,
+      `/api/v1/ausd-engine/calculate-redeem`,
       { address, tokenAddress, redeemAmount },
     );
   },
@@ -25,8 +25,8 @@ import { ConnectWalletPrompt } from "@/components/connect-wallet-prompt";
 export function UserDashboard() {
   const { isConnected, address } = useAccount();
   const { data, isLoading } = useSWR(
-    address && isConnected ? `/api/user/${address}` : null,
-    () => get<DashboardData>(`/api/user/${address}`),
+    address && isConnected ? `/api/v1/user/${address}` : null,
+    () => get<DashboardData>(`/api/v1/user/${address}`),
     {
       fallbackData: mockDashboardData,
     },
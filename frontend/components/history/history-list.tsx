@@ -73,8 +73,8 @@ function TransactionItem({ tx }: { tx: Transaction }) {
 export function HistoryList() {
   const { isConnected, address } = useAccount();
   const { data, isLoading } = useSWR(
-    address && isConnected ? `/api/history/${address}` : null,
-    () => get<HistoryData>(`/api/history/${address}`),
+    address && isConnected ? `/api/v1/history/${address}` : null,
+    () => get<HistoryData>(`/api/v1/history/${address}`),
     {
       fallbackData: mockHistoryData,
     },
@@ -35,8 +35,8 @@ import { useAUSDEngineOperations } from "@/hooks/useAUSDEngineOperations";
 export function RiskDashboard() {
   const { isConnected } = useAccount();
   const { data, isLoading } = useSWR(
-    isConnected ? `/api/metrics/dashboard` : null,
-    () => get<MetricsData>(`/api/metrics/dashboard`),
+    isConnected ? `/api/v1/metrics/dashboard` : null,
+    () => get<MetricsData>(`/api/v1/metrics/dashboard`),
     {
       fallbackData: mockRiskData,
     },
@@ -28,7 +28,7 @@ export function useAUSDEngine() {
     error,
     mutate,
   } = useSWR<AUSDEngineData>(
-    address && isConnected ? `/api/ausd-engine/user/${address}` : null,
+    address && isConnected ? `/api/v1/ausd-engine/user/${address}` : null,
     () => ausdEngineApi.getUserData(address!),
     {
       refreshInterval: 10000,